@@ -0,0 +1,70 @@
+package sorting
+
+import (
+	"practice/concurrent/workstealing"
+	"practice/utilities"
+	"sync"
+)
+
+// sequentialThreshold is the length below which spawning a subtask costs more than it saves.
+const sequentialThreshold = 2048
+
+// ParallelMergeSort is a merge sort whose halves are sorted in parallel on a work-stealing pool.
+type ParallelMergeSort struct {
+	// Pool runs the sorts. The caller owns it, and must not close it while a sort is running.
+	// If it is nil, the sorts run on a pool of GOMAXPROCS workers that is shared by every ParallelMergeSort
+	// without a pool of its own. That pool is started by the first sort that needs it and is never closed;
+	// its workers sleep while there is nothing to sort.
+	Pool *workstealing.Pool
+}
+
+// sharedPool runs the sorts of every ParallelMergeSort that has no pool of its own.
+var sharedPool = sync.OnceValue(func() *workstealing.Pool {
+	return workstealing.NewPool(0)
+})
+
+func (p ParallelMergeSort) Kind() string {
+	return "ParallelMergeSort"
+}
+
+func (p ParallelMergeSort) Sorted(arr []int) []int {
+	clone := utilities.Clone(arr)
+	if len(clone) <= sequentialThreshold {
+		mergeSort(clone)
+		return clone
+	}
+
+	pool := p.Pool
+	if pool == nil {
+		pool = sharedPool()
+	}
+
+	buf := make([]int, len(clone))
+	task, err := pool.Submit(func(w *workstealing.Worker) {
+		parallelMergeSort(w, clone, buf)
+	})
+	if err != nil {
+		// Only a pool passed in by the caller can be closed
+		panic(err)
+	}
+	task.Wait()
+	return clone
+}
+
+// parallelMergeSort sorts arr, using buf (of the same length) as scratch space for merging.
+func parallelMergeSort(w *workstealing.Worker, arr, buf []int) {
+	if len(arr) <= sequentialThreshold {
+		mergeSort(arr)
+		return
+	}
+
+	mid := len(arr) / 2
+	left := w.Spawn(func(w *workstealing.Worker) {
+		parallelMergeSort(w, arr[:mid], buf[:mid])
+	})
+	parallelMergeSort(w, arr[mid:], buf[mid:])
+	w.Wait(left)
+
+	copy(buf, arr)
+	merge(arr, buf[:mid], buf[mid:])
+}
@@ -2,6 +2,7 @@ package sorting
 
 import (
 	"math/rand"
+	"practice/concurrent/workstealing"
 	"reflect"
 	"testing"
 	"testing/quick"
@@ -12,6 +13,7 @@ func TestSortingAlgorithms(t *testing.T) {
 		DefaultSort{},
 		QuickSort{},
 		MergeSort{},
+		ParallelMergeSort{},
 	}
 
 	// Property: all sorting algorithms should result in the same sorted-order
@@ -33,6 +35,31 @@ func TestSortingAlgorithms(t *testing.T) {
 
 }
 
+func TestParallelMergeSortLargeArrays(t *testing.T) {
+	pool := workstealing.NewPool(4)
+	defer pool.Close()
+
+	for _, sorter := range []ParallelMergeSort{{}, {Pool: pool}} {
+		for _, n := range []int{sequentialThreshold + 1, 100000} {
+			given := rand.Perm(n)
+			want := DefaultSort{}.Sorted(given)
+			got := sorter.Sorted(given)
+			assertEquals(sorter.Kind(), got, want, t)
+		}
+	}
+}
+
+func BenchmarkMergeSort(b *testing.B) {
+	given := rand.Perm(1 << 20)
+	for _, sorter := range []Sorter{MergeSort{}, ParallelMergeSort{}} {
+		b.Run(sorter.Kind(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sorter.Sorted(given)
+			}
+		})
+	}
+}
+
 func assertEquals(kind string, got, want []int, t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("Sorting failed for %s", kind)
@@ -0,0 +1,199 @@
+package cache
+
+import "practice/collections/list"
+
+// ARC is a Cache using the Adaptive Replacement Cache policy.
+// It splits its capacity between entries seen once recently and entries used repeatedly,
+// and remembers the keys it recently evicted from each part to learn which one deserves more room.
+// This keeps it resistant to scans that would flush an LRU while still adapting to changing workloads.
+type ARC[K comparable, V any] struct {
+	core[K, V]
+	items map[K]*list.Node[*arcEntry[K, V]]
+	// target is the share of the capacity the policy currently aims to give to recent.
+	target int64
+	// recent and frequent hold the cached entries that were used once and more than once.
+	recent, frequent *arcList[K, V]
+	// recentGhosts and frequentGhosts remember the keys and costs of entries evicted from recent and frequent.
+	recentGhosts, frequentGhosts *arcList[K, V]
+}
+
+// arcList is one of the four ARC lists, with the most recently used entry at the front.
+type arcList[K comparable, V any] struct {
+	entries *list.List[*arcEntry[K, V]]
+	cost    int64
+	ghost   bool
+}
+
+type arcEntry[K comparable, V any] struct {
+	entry[K, V]
+	list *arcList[K, V]
+}
+
+// NewARC returns an empty ARC cache.
+func NewARC[K comparable, V any](config Config[K, V]) *ARC[K, V] {
+	newList := func(ghost bool) *arcList[K, V] {
+		return &arcList[K, V]{entries: list.New[*arcEntry[K, V]](), ghost: ghost}
+	}
+
+	return &ARC[K, V]{
+		core:           newCore(config),
+		items:          map[K]*list.Node[*arcEntry[K, V]]{},
+		recent:         newList(false),
+		frequent:       newList(false),
+		recentGhosts:   newList(true),
+		frequentGhosts: newList(true),
+	}
+}
+
+func (c *ARC[K, V]) Len() int {
+	return c.recent.entries.Len() + c.frequent.entries.Len()
+}
+
+func (c *ARC[K, V]) Get(key K) (v V, ok bool) {
+	n := c.items[key]
+	if n == nil || n.Value.list.ghost {
+		c.hit(nil)
+		return v, false
+	}
+	if !c.hit(&n.Value.entry) {
+		c.remove(n)
+		c.evict(&n.Value.entry)
+		return v, false
+	}
+
+	c.move(n, c.frequent)
+	return n.Value.value, true
+}
+
+func (c *ARC[K, V]) Set(key K, value V) bool {
+	cost := c.costOf(key, value)
+	if cost > c.config.Capacity {
+		c.Delete(key)
+		return false
+	}
+
+	n := c.items[key]
+	wasFrequentGhost := false
+	if n != nil && n.Value.list.ghost {
+		// A ghost's cost is remembered but no longer counted as used.
+		c.used += n.Value.cost
+	}
+	switch {
+	case n == nil:
+		n = &list.Node[*arcEntry[K, V]]{Value: &arcEntry[K, V]{entry: entry[K, V]{key: key}}}
+		c.items[key] = n
+		c.push(n, c.recent)
+	case n.Value.list == c.recentGhosts:
+		// The key was evicted from recent too early, so give recent more room.
+		c.target = min(c.config.Capacity, c.target+adapt(cost, c.frequentGhosts.cost, c.recentGhosts.cost))
+		c.move(n, c.frequent)
+	case n.Value.list == c.frequentGhosts:
+		// The key was evicted from frequent too early, so give frequent more room.
+		c.target = max(0, c.target-adapt(cost, c.recentGhosts.cost, c.frequentGhosts.cost))
+		c.move(n, c.frequent)
+		wasFrequentGhost = true
+	default:
+		c.move(n, c.frequent)
+	}
+
+	n.Value.list.cost += cost - n.Value.cost
+	c.fill(&n.Value.entry, value, cost)
+
+	for c.used > c.config.Capacity {
+		c.replace(n, wasFrequentGhost)
+	}
+	c.trimGhosts()
+	return true
+}
+
+func (c *ARC[K, V]) Delete(key K) bool {
+	n := c.items[key]
+	if n == nil {
+		return false
+	}
+
+	ghost := n.Value.list.ghost
+	c.remove(n)
+	if ghost {
+		return false
+	}
+	c.used -= n.Value.cost
+	return true
+}
+
+// replace evicts the least recently used entry of recent or frequent, never evicting keep.
+func (c *ARC[K, V]) replace(keep *list.Node[*arcEntry[K, V]], wasFrequentGhost bool) {
+	from, to := c.frequent, c.frequentGhosts
+	if c.recent.cost > 0 && (c.recent.cost > c.target || (wasFrequentGhost && c.recent.cost == c.target)) {
+		from, to = c.recent, c.recentGhosts
+	}
+	if from.entries.Back == nil || from.entries.Back == keep {
+		// keep fits on its own, so the other list must hold something to evict.
+		if from == c.recent {
+			from, to = c.frequent, c.frequentGhosts
+		} else {
+			from, to = c.recent, c.recentGhosts
+		}
+	}
+
+	victim := from.entries.Back
+	c.evict(&victim.Value.entry)
+	if c.expired(&victim.Value.entry) {
+		c.remove(victim)
+		return
+	}
+
+	var zero V
+	victim.Value.value = zero
+	c.move(victim, to)
+}
+
+// trimGhosts forgets the oldest evicted keys so that recent and its ghosts together cover at most the capacity,
+// and all four lists together at most twice the capacity.
+func (c *ARC[K, V]) trimGhosts() {
+	for c.recent.cost+c.recentGhosts.cost > c.config.Capacity && c.recentGhosts.entries.Back != nil {
+		c.remove(c.recentGhosts.entries.Back)
+	}
+
+	for c.recent.cost+c.frequent.cost+c.recentGhosts.cost+c.frequentGhosts.cost > 2*c.config.Capacity {
+		ghosts := c.frequentGhosts
+		if ghosts.entries.Back == nil {
+			ghosts = c.recentGhosts
+		}
+		if ghosts.entries.Back == nil {
+			return
+		}
+		c.remove(ghosts.entries.Back)
+	}
+}
+
+// adapt returns how far to move the target after a ghost hit on a list holding hitCost,
+// scaled by how much larger the other ghost list is.
+func adapt(cost, otherCost, hitCost int64) int64 {
+	delta := max(cost, 1)
+	if hitCost > 0 && otherCost > hitCost {
+		delta = delta * otherCost / hitCost
+	}
+	return delta
+}
+
+// move moves n to the front of to.
+func (c *ARC[K, V]) move(n *list.Node[*arcEntry[K, V]], to *arcList[K, V]) {
+	from := n.Value.list
+	from.entries.Remove(n)
+	from.cost -= n.Value.cost
+	c.push(n, to)
+}
+
+func (c *ARC[K, V]) push(n *list.Node[*arcEntry[K, V]], to *arcList[K, V]) {
+	to.entries.PushFrontNode(n)
+	to.cost += n.Value.cost
+	n.Value.list = to
+}
+
+func (c *ARC[K, V]) remove(n *list.Node[*arcEntry[K, V]]) {
+	from := n.Value.list
+	from.entries.Remove(n)
+	from.cost -= n.Value.cost
+	delete(c.items, n.Value.key)
+}
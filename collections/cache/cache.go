@@ -0,0 +1,154 @@
+// Package cache provides bounded key-value caches with LRU, LFU and ARC eviction policies.
+package cache
+
+import (
+	"practice/collections/delay"
+	"time"
+)
+
+// Cache maps keys to values and evicts entries once their total cost exceeds its capacity.
+// Implementations are not safe for concurrent use unless stated otherwise; see Sharded.
+type Cache[K comparable, V any] interface {
+	// Get returns the value stored for key and records the access.
+	// False is returned if the key is missing or its entry has expired.
+	Get(key K) (V, bool)
+	// Set stores value for key, evicting other entries if the cache is over capacity.
+	// False is returned, and any previous value for key removed, if the entry alone costs more than the capacity.
+	Set(key K, value V) bool
+	// Delete removes key without calling the eviction callback.
+	// False is returned if the key was not in the cache.
+	Delete(key K) bool
+	// Len returns the number of entries in the cache, including expired entries not yet removed.
+	Len() int
+	// Cost returns the total cost of the entries in the cache.
+	Cost() int64
+	// Stats returns the cache's hit, miss and eviction counters.
+	Stats() Stats
+}
+
+// Config describes the capacity and behaviour of a cache.
+type Config[K comparable, V any] struct {
+	// Capacity is the maximum total cost of the entries in the cache. It must be positive.
+	Capacity int64
+	// Cost returns the cost of an entry, for example len(value) to bound the cache by bytes.
+	// If nil, every entry costs 1 and Capacity bounds the number of entries.
+	Cost func(key K, value V) int64
+	// TTL, if positive, is how long an entry stays valid after it was last set.
+	TTL time.Duration
+	// OnEvict, if set, is called with every entry removed because the cache was full or the entry expired.
+	// It is not called for Delete or when Set replaces a value.
+	OnEvict func(key K, value V)
+	// Clock is used to expire entries. Defaults to delay.RealClock.
+	Clock delay.Clock
+}
+
+// Stats counts the outcome of cache operations.
+type Stats struct {
+	Hits, Misses uint64
+	// Evictions counts entries removed to make room for others.
+	Evictions uint64
+	// Expirations counts entries removed because their TTL had passed.
+	Expirations uint64
+}
+
+// HitRatio returns the fraction of lookups that were hits, or 0 if there were none.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func (s Stats) add(other Stats) Stats {
+	return Stats{
+		Hits:        s.Hits + other.Hits,
+		Misses:      s.Misses + other.Misses,
+		Evictions:   s.Evictions + other.Evictions,
+		Expirations: s.Expirations + other.Expirations,
+	}
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	cost    int64
+	expires time.Time
+}
+
+// core holds the bookkeeping shared by every eviction policy.
+type core[K comparable, V any] struct {
+	config Config[K, V]
+	used   int64
+	stats  Stats
+}
+
+func newCore[K comparable, V any](config Config[K, V]) core[K, V] {
+	if config.Capacity < 1 {
+		panic("cache: capacity must be positive")
+	}
+	if config.Clock == nil {
+		config.Clock = delay.RealClock{}
+	}
+
+	return core[K, V]{config: config}
+}
+
+func (c *core[K, V]) Cost() int64 {
+	return c.used
+}
+
+func (c *core[K, V]) Stats() Stats {
+	return c.stats
+}
+
+// costOf returns the cost of storing value for key.
+func (c *core[K, V]) costOf(key K, value V) int64 {
+	if c.config.Cost == nil {
+		return 1
+	}
+
+	cost := c.config.Cost(key, value)
+	if cost < 0 {
+		panic("cache: negative cost")
+	}
+	return cost
+}
+
+// fill stores value in e, adjusting the cost of the cache and restarting the entry's TTL.
+func (c *core[K, V]) fill(e *entry[K, V], value V, cost int64) {
+	c.used += cost - e.cost
+	e.value = value
+	e.cost = cost
+	if c.config.TTL > 0 {
+		e.expires = c.config.Clock.Now().Add(c.config.TTL)
+	}
+}
+
+func (c *core[K, V]) expired(e *entry[K, V]) bool {
+	return !e.expires.IsZero() && !c.config.Clock.Now().Before(e.expires)
+}
+
+// evict accounts for e leaving the cache, either because it expired or to make room.
+func (c *core[K, V]) evict(e *entry[K, V]) {
+	c.used -= e.cost
+	if c.expired(e) {
+		c.stats.Expirations++
+	} else {
+		c.stats.Evictions++
+	}
+	if c.config.OnEvict != nil {
+		c.config.OnEvict(e.key, e.value)
+	}
+}
+
+// hit records a lookup of e, returning false and counting a miss if e is missing or expired.
+// The caller must remove an expired entry with evict.
+func (c *core[K, V]) hit(e *entry[K, V]) bool {
+	if e == nil || c.expired(e) {
+		c.stats.Misses++
+		return false
+	}
+
+	c.stats.Hits++
+	return true
+}
@@ -0,0 +1,285 @@
+package cache
+
+import (
+	"math/rand"
+	"practice/collections/delay"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+type cacheTest struct {
+	name string
+	new  func(config Config[int, string]) Cache[int, string]
+}
+
+func caches() []cacheTest {
+	return []cacheTest{
+		{"LRU", func(config Config[int, string]) Cache[int, string] { return NewLRU(config) }},
+		{"LFU", func(config Config[int, string]) Cache[int, string] { return NewLFU(config) }},
+		{"ARC", func(config Config[int, string]) Cache[int, string] { return NewARC(config) }},
+		{"Sharded LRU", func(config Config[int, string]) Cache[int, string] {
+			return NewSharded(1, config, NewLRU[int, string])
+		}},
+	}
+}
+
+func TestCaches_GetSetDelete(t *testing.T) {
+	t.Parallel()
+	for _, test := range caches() {
+		t.Run(test.name, func(t *testing.T) {
+			c := test.new(Config[int, string]{Capacity: 10})
+
+			if _, ok := c.Get(1); ok {
+				t.Errorf("Get() on an empty cache returned ok")
+			}
+			c.Set(1, "one")
+			c.Set(2, "two")
+			c.Set(1, "uno")
+
+			if v, ok := c.Get(1); !ok || v != "uno" {
+				t.Errorf("Get(1) = %v, %v, want uno, true", v, ok)
+			}
+			if c.Len() != 2 || c.Cost() != 2 {
+				t.Errorf("Len(), Cost() = %v, %v, want 2, 2", c.Len(), c.Cost())
+			}
+
+			if !c.Delete(1) || c.Delete(1) {
+				t.Errorf("Delete(1) twice did not return true then false")
+			}
+			if _, ok := c.Get(1); ok {
+				t.Errorf("Get(1) after Delete returned ok")
+			}
+
+			stats := c.Stats()
+			if stats.Hits != 1 || stats.Misses != 2 || stats.HitRatio() != 1.0/3 {
+				t.Errorf("Stats() = %+v, want 1 hit and 2 misses", stats)
+			}
+		})
+	}
+}
+
+func TestCaches_ByteCost(t *testing.T) {
+	t.Parallel()
+	for _, test := range caches() {
+		t.Run(test.name, func(t *testing.T) {
+			var evicted []int
+			c := test.new(Config[int, string]{
+				Capacity: 10,
+				Cost:     func(_ int, v string) int64 { return int64(len(v)) },
+				OnEvict:  func(k int, _ string) { evicted = append(evicted, k) },
+			})
+
+			c.Set(1, "aaaa")
+			c.Set(2, "bbbb")
+			c.Set(3, "cccc")
+
+			if c.Cost() > 10 || c.Len() != 2 {
+				t.Errorf("Cost(), Len() = %v, %v, want at most 10 bytes in 2 entries", c.Cost(), c.Len())
+			}
+			if len(evicted) != 1 || evicted[0] == 3 {
+				t.Errorf("evicted %v, want one of the older entries", evicted)
+			}
+			if c.Stats().Evictions != 1 {
+				t.Errorf("Stats().Evictions = %v, want 1", c.Stats().Evictions)
+			}
+
+			if c.Set(3, "this value is too big") {
+				t.Errorf("Set() of an entry larger than the capacity returned true")
+			}
+			if _, ok := c.Get(3); ok {
+				t.Errorf("Get() returned the value replaced by an oversized entry")
+			}
+		})
+	}
+}
+
+func TestCaches_TTL(t *testing.T) {
+	t.Parallel()
+	for _, test := range caches() {
+		t.Run(test.name, func(t *testing.T) {
+			clock := delay.NewFakeClock(time.Unix(0, 0))
+			var expired []int
+			c := test.new(Config[int, string]{
+				Capacity: 10,
+				TTL:      time.Minute,
+				Clock:    clock,
+				OnEvict:  func(k int, _ string) { expired = append(expired, k) },
+			})
+
+			c.Set(1, "one")
+			clock.Advance(30 * time.Second)
+			c.Set(2, "two")
+			clock.Advance(30 * time.Second)
+
+			if _, ok := c.Get(1); ok {
+				t.Errorf("Get(1) returned an expired entry")
+			}
+			if v, ok := c.Get(2); !ok || v != "two" {
+				t.Errorf("Get(2) = %v, %v, want two, true", v, ok)
+			}
+
+			// Setting a key again restarts its TTL.
+			c.Set(2, "dos")
+			clock.Advance(45 * time.Second)
+			if v, ok := c.Get(2); !ok || v != "dos" {
+				t.Errorf("Get(2) = %v, %v, want dos, true", v, ok)
+			}
+
+			if len(expired) != 1 || expired[0] != 1 || c.Stats().Expirations != 1 || c.Len() != 1 {
+				t.Errorf("expired %v with stats %+v, want only key 1 to expire", expired, c.Stats())
+			}
+		})
+	}
+}
+
+// Property: a cache never exceeds its capacity, and every hit returns the value last set for the key
+func TestCaches_MatchModel(t *testing.T) {
+	t.Parallel()
+	for _, test := range caches() {
+		t.Run(test.name, func(t *testing.T) {
+			f := func(ops []uint16, seed int64) bool {
+				r := rand.New(rand.NewSource(seed))
+				model := map[int]string{}
+				evictions, consistent := 0, true
+				c := test.new(Config[int, string]{
+					Capacity: 64,
+					Cost:     func(_ int, v string) int64 { return int64(len(v)) },
+					OnEvict: func(k int, v string) {
+						// Only entries the model still holds may be evicted, with their latest value.
+						consistent = consistent && model[k] == v
+						delete(model, k)
+						evictions++
+					},
+				})
+
+				for _, op := range ops {
+					key := int(op % 32)
+					switch r.Intn(3) {
+					case 0:
+						value := strings.Repeat(string(rune('a'+op%26)), r.Intn(16)+1)
+						if c.Set(key, value) {
+							model[key] = value
+						}
+					case 1:
+						if v, ok := c.Get(key); ok && v != model[key] {
+							return false
+						}
+					case 2:
+						if c.Delete(key) != (model[key] != "") {
+							return false
+						}
+						delete(model, key)
+					}
+
+					var cost int64
+					for _, v := range model {
+						cost += int64(len(v))
+					}
+					if c.Cost() > 64 || c.Cost() != cost || c.Len() != len(model) {
+						return false
+					}
+				}
+				return consistent && c.Stats().Evictions == uint64(evictions)
+			}
+
+			if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	c := NewLRU(Config[int, int]{Capacity: 3})
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Set(3, 3)
+	c.Get(1)
+	c.Set(4, 4)
+
+	if _, ok := c.Get(2); ok {
+		t.Errorf("Get(2) returned ok, want the least recently used key evicted")
+	}
+	for _, k := range []int{1, 3, 4} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("Get(%v) missed", k)
+		}
+	}
+}
+
+func TestLFU_EvictsLeastFrequentlyUsed(t *testing.T) {
+	t.Parallel()
+	c := NewLFU(Config[int, int]{Capacity: 3})
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Set(3, 3)
+	c.Get(1)
+	c.Get(1)
+	c.Get(3)
+	c.Set(4, 4)
+
+	if _, ok := c.Get(2); ok {
+		t.Errorf("Get(2) returned ok, want the least frequently used key evicted")
+	}
+
+	// 4 and 2 were each used once, so 4, the older of the two remaining, goes next.
+	c.Set(5, 5)
+	if _, ok := c.Get(4); ok {
+		t.Errorf("Get(4) returned ok, want it evicted before more frequently used keys")
+	}
+}
+
+func TestARC_ResistsScans(t *testing.T) {
+	t.Parallel()
+	lru := NewLRU(Config[int, int]{Capacity: 100})
+	arc := NewARC(Config[int, int]{Capacity: 100})
+
+	for _, c := range []Cache[int, int]{lru, arc} {
+		// A hot set that is used repeatedly, interleaved with a long scan of keys used only once.
+		for round := 0; round < 20; round++ {
+			for k := 0; k < 50; k++ {
+				if _, ok := c.Get(k); !ok {
+					c.Set(k, k)
+				}
+				c.Get(k)
+			}
+			for k := 0; k < 100; k++ {
+				key := 1000 + round*100 + k
+				if _, ok := c.Get(key); !ok {
+					c.Set(key, key)
+				}
+			}
+		}
+	}
+
+	if arc.Stats().Hits <= lru.Stats().Hits {
+		t.Errorf("ARC hits = %v, LRU hits = %v, want ARC to keep the hot set through scans", arc.Stats().Hits, lru.Stats().Hits)
+	}
+}
+
+func TestARC_GhostHitsAdaptTarget(t *testing.T) {
+	t.Parallel()
+	c := NewARC(Config[int, int]{Capacity: 4})
+	for k := 0; k < 2; k++ {
+		c.Set(k, k)
+		c.Get(k)
+	}
+	for k := 2; k < 5; k++ {
+		c.Set(k, k)
+	}
+
+	// Key 2 was evicted from the recent list while 0 and 1 were kept, so setting it again should favour recent entries.
+	if _, ok := c.Get(2); ok {
+		t.Fatalf("Get(2) returned ok, want it evicted")
+	}
+	c.Set(2, 2)
+	if c.target == 0 {
+		t.Errorf("target = 0 after a hit on a recently evicted key, want it to grow")
+	}
+	if v, ok := c.Get(2); !ok || v != 2 || c.Len() != 4 {
+		t.Errorf("Get(2) = %v, %v with Len() %v, want 2, true with Len() 4", v, ok, c.Len())
+	}
+}
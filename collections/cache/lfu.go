@@ -0,0 +1,141 @@
+package cache
+
+import "practice/collections/list"
+
+// LFU is a Cache that evicts the least frequently used entry first,
+// breaking ties by evicting the least recently used of them.
+// Every operation runs in O(1).
+type LFU[K comparable, V any] struct {
+	core[K, V]
+	items map[K]*list.Node[*lfuEntry[K, V]]
+	// buckets holds one bucket per distinct access count, in increasing order of count.
+	buckets *list.List[*lfuBucket[K, V]]
+}
+
+type lfuBucket[K comparable, V any] struct {
+	count uint64
+	// entries holds the most recently used entry at the front.
+	entries *list.List[*lfuEntry[K, V]]
+}
+
+type lfuEntry[K comparable, V any] struct {
+	entry[K, V]
+	bucket *list.Node[*lfuBucket[K, V]]
+}
+
+// NewLFU returns an empty LFU cache.
+func NewLFU[K comparable, V any](config Config[K, V]) *LFU[K, V] {
+	return &LFU[K, V]{
+		core:    newCore(config),
+		items:   map[K]*list.Node[*lfuEntry[K, V]]{},
+		buckets: list.New[*lfuBucket[K, V]](),
+	}
+}
+
+func (c *LFU[K, V]) Len() int {
+	return len(c.items)
+}
+
+func (c *LFU[K, V]) Get(key K) (v V, ok bool) {
+	n := c.items[key]
+	if n == nil {
+		c.hit(nil)
+		return v, false
+	}
+	if !c.hit(&n.Value.entry) {
+		c.remove(n)
+		c.evict(&n.Value.entry)
+		return v, false
+	}
+
+	c.touch(n)
+	return n.Value.value, true
+}
+
+func (c *LFU[K, V]) Set(key K, value V) bool {
+	cost := c.costOf(key, value)
+	if cost > c.config.Capacity {
+		c.Delete(key)
+		return false
+	}
+
+	n := c.items[key]
+	if n != nil {
+		c.touch(n)
+	} else {
+		n = c.insert(key)
+	}
+	c.fill(&n.Value.entry, value, cost)
+
+	for c.used > c.config.Capacity {
+		victim := c.victim(n)
+		c.remove(victim)
+		c.evict(&victim.Value.entry)
+	}
+	return true
+}
+
+func (c *LFU[K, V]) Delete(key K) bool {
+	n := c.items[key]
+	if n == nil {
+		return false
+	}
+
+	c.remove(n)
+	c.used -= n.Value.cost
+	return true
+}
+
+// insert adds a new entry for key with an access count of one.
+func (c *LFU[K, V]) insert(key K) *list.Node[*lfuEntry[K, V]] {
+	first := c.buckets.Front
+	if first == nil || first.Value.count != 1 {
+		first = c.buckets.PushFront(&lfuBucket[K, V]{count: 1, entries: list.New[*lfuEntry[K, V]]()})
+	}
+
+	n := first.Value.entries.PushFront(&lfuEntry[K, V]{entry: entry[K, V]{key: key}, bucket: first})
+	c.items[key] = n
+	return n
+}
+
+// touch moves n to the bucket for its next access count.
+func (c *LFU[K, V]) touch(n *list.Node[*lfuEntry[K, V]]) {
+	current := n.Value.bucket
+	next := current.Next
+	if next == nil || next.Value.count != current.Value.count+1 {
+		next = c.buckets.InsertAfter(&lfuBucket[K, V]{
+			count:   current.Value.count + 1,
+			entries: list.New[*lfuEntry[K, V]](),
+		}, current)
+	}
+
+	current.Value.entries.Remove(n)
+	if current.Value.entries.Len() == 0 {
+		c.buckets.Remove(current)
+	}
+	next.Value.entries.PushFrontNode(n)
+	n.Value.bucket = next
+}
+
+// victim returns the entry to evict next, skipping keep.
+func (c *LFU[K, V]) victim(keep *list.Node[*lfuEntry[K, V]]) *list.Node[*lfuEntry[K, V]] {
+	for b := range c.buckets.All() {
+		candidate := b.entries.Back
+		if candidate == keep {
+			candidate = candidate.Prev
+		}
+		if candidate != nil {
+			return candidate
+		}
+	}
+	panic("cache: no entry to evict")
+}
+
+func (c *LFU[K, V]) remove(n *list.Node[*lfuEntry[K, V]]) {
+	bucket := n.Value.bucket
+	bucket.Value.entries.Remove(n)
+	if bucket.Value.entries.Len() == 0 {
+		c.buckets.Remove(bucket)
+	}
+	delete(c.items, n.Value.key)
+}
@@ -0,0 +1,81 @@
+package cache
+
+import "practice/collections/list"
+
+// LRU is a Cache that evicts the least recently used entry first.
+type LRU[K comparable, V any] struct {
+	core[K, V]
+	items map[K]*list.Node[*entry[K, V]]
+	// order holds the most recently used entry at the front.
+	order *list.List[*entry[K, V]]
+}
+
+// NewLRU returns an empty LRU cache.
+func NewLRU[K comparable, V any](config Config[K, V]) *LRU[K, V] {
+	return &LRU[K, V]{
+		core:  newCore(config),
+		items: map[K]*list.Node[*entry[K, V]]{},
+		order: list.New[*entry[K, V]](),
+	}
+}
+
+func (c *LRU[K, V]) Len() int {
+	return c.order.Len()
+}
+
+func (c *LRU[K, V]) Get(key K) (v V, ok bool) {
+	n := c.items[key]
+	if n == nil {
+		c.hit(nil)
+		return v, false
+	}
+	if !c.hit(n.Value) {
+		c.remove(n)
+		c.evict(n.Value)
+		return v, false
+	}
+
+	c.order.MoveToFront(n)
+	return n.Value.value, true
+}
+
+func (c *LRU[K, V]) Set(key K, value V) bool {
+	cost := c.costOf(key, value)
+	if cost > c.config.Capacity {
+		c.Delete(key)
+		return false
+	}
+
+	n := c.items[key]
+	if n != nil {
+		c.order.MoveToFront(n)
+	} else {
+		n = c.order.PushFront(&entry[K, V]{key: key})
+		c.items[key] = n
+	}
+	c.fill(n.Value, value, cost)
+
+	// The new entry is at the front and fits on its own, so it is never evicted here.
+	for c.used > c.config.Capacity {
+		victim := c.order.Back
+		c.remove(victim)
+		c.evict(victim.Value)
+	}
+	return true
+}
+
+func (c *LRU[K, V]) Delete(key K) bool {
+	n := c.items[key]
+	if n == nil {
+		return false
+	}
+
+	c.remove(n)
+	c.used -= n.Value.cost
+	return true
+}
+
+func (c *LRU[K, V]) remove(n *list.Node[*entry[K, V]]) {
+	c.order.Remove(n)
+	delete(c.items, n.Value.key)
+}
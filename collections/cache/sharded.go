@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// Sharded is a Cache that is safe for concurrent use.
+// Keys are spread by hash over independently locked shards, so operations on different shards do not contend.
+// The eviction callback runs while its shard is locked and must not call back into the cache.
+type Sharded[K comparable, V any] struct {
+	seed   maphash.Seed
+	shards []shard[K, V]
+}
+
+type shard[K comparable, V any] struct {
+	lock  sync.Mutex
+	cache Cache[K, V]
+}
+
+// NewSharded returns an empty cache split into the given number of shards, each built by newCache,
+// for example NewSharded(16, config, NewLRU[string, []byte]).
+// Every shard receives an equal share of the capacity, rounded up.
+func NewSharded[K comparable, V any, C Cache[K, V]](shards int, config Config[K, V], newCache func(Config[K, V]) C) *Sharded[K, V] {
+	if shards < 1 {
+		panic("cache: shards must be positive")
+	}
+
+	per := config
+	per.Capacity = (config.Capacity + int64(shards) - 1) / int64(shards)
+
+	c := &Sharded[K, V]{
+		seed:   maphash.MakeSeed(),
+		shards: make([]shard[K, V], shards),
+	}
+	for i := range c.shards {
+		c.shards[i].cache = newCache(per)
+	}
+	return c
+}
+
+func (c *Sharded[K, V]) Get(key K) (V, bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cache.Get(key)
+}
+
+func (c *Sharded[K, V]) Set(key K, value V) bool {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cache.Set(key, value)
+}
+
+func (c *Sharded[K, V]) Delete(key K) bool {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cache.Delete(key)
+}
+
+func (c *Sharded[K, V]) Len() int {
+	total := 0
+	c.each(func(cache Cache[K, V]) {
+		total += cache.Len()
+	})
+	return total
+}
+
+func (c *Sharded[K, V]) Cost() int64 {
+	var total int64
+	c.each(func(cache Cache[K, V]) {
+		total += cache.Cost()
+	})
+	return total
+}
+
+func (c *Sharded[K, V]) Stats() Stats {
+	var total Stats
+	c.each(func(cache Cache[K, V]) {
+		total = total.add(cache.Stats())
+	})
+	return total
+}
+
+func (c *Sharded[K, V]) shard(key K) *shard[K, V] {
+	return &c.shards[maphash.Comparable(c.seed, key)%uint64(len(c.shards))]
+}
+
+// each calls fn with every shard's cache while holding that shard's lock.
+func (c *Sharded[K, V]) each(fn func(cache Cache[K, V])) {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lock.Lock()
+		fn(s.cache)
+		s.lock.Unlock()
+	}
+}
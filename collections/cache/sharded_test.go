@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func ExampleSharded() {
+	c := NewSharded(16, Config[string, []byte]{
+		Capacity: 1 << 20,
+		Cost:     func(_ string, v []byte) int64 { return int64(len(v)) },
+	}, NewARC[string, []byte])
+
+	c.Set("greeting", []byte("hello"))
+	v, ok := c.Get("greeting")
+	fmt.Println(string(v), ok, c.Cost())
+	// Output:
+	// hello true 5
+}
+
+func TestSharded_Concurrent(t *testing.T) {
+	t.Parallel()
+	c := NewSharded(8, Config[int, int]{Capacity: 800}, NewLFU[int, int])
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := (g*7919 + i) % 1000
+				if v, ok := c.Get(key); ok && v != key {
+					t.Errorf("Get(%v) = %v", key, v)
+				}
+				c.Set(key, key)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	if stats.Hits+stats.Misses != 8*2000 {
+		t.Errorf("Stats() = %+v, want %v lookups", stats, 8*2000)
+	}
+	if c.Len() > 800 || int64(c.Len()) != c.Cost() {
+		t.Errorf("Len(), Cost() = %v, %v, want at most 800 entries", c.Len(), c.Cost())
+	}
+}
@@ -0,0 +1,167 @@
+package delay
+
+import (
+	"context"
+	"practice/collections/heap"
+	"practice/collections/queue"
+	"sync"
+	"time"
+)
+
+// DelayQueue holds items until their deadline has passed.
+// Items are handed out in deadline order, and items with the same deadline in insertion order.
+// After Close, Push fails with queue.ErrClosed and Pop fails with queue.ErrClosed
+// as soon as no item is due.
+type DelayQueue[T any] struct {
+	lock   sync.Mutex
+	clock  Clock
+	heap   *heap.IndexedDHeap[T, deadline]
+	seq    uint64
+	closed bool
+	// changed is closed and replaced whenever an item is pushed or the queue is closed.
+	changed chan struct{}
+}
+
+// deadline orders the items, breaking ties by the order they were pushed in.
+type deadline struct {
+	at  time.Time
+	seq uint64
+}
+
+// NewDelayQueue returns an empty DelayQueue that reads the time from clock.
+func NewDelayQueue[T any](clock Clock) *DelayQueue[T] {
+	return &DelayQueue[T]{
+		clock: clock,
+		heap: heap.NewIndexed[T](4, func(a, b deadline) bool {
+			if a.at.Equal(b.at) {
+				return a.seq < b.seq
+			}
+			return a.at.Before(b.at)
+		}),
+		changed: make(chan struct{}),
+	}
+}
+
+// Len returns the number of items in the queue, whether they are due or not.
+func (q *DelayQueue[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.heap.Len()
+}
+
+// Push adds v to the queue, to become available at the given time.
+func (q *DelayQueue[T]) Push(v T, at time.Time) error {
+	_, err := q.push(v, at)
+	return err
+}
+
+// push adds v to the queue and returns its handle, which remove takes to withdraw it before it is due.
+func (q *DelayQueue[T]) push(v T, at time.Time) (*heap.Handle[T, deadline], error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return nil, queue.ErrClosed
+	}
+
+	q.seq++
+	handle := q.heap.Insert(v, deadline{at: at, seq: q.seq})
+	q.broadcast()
+	return handle, nil
+}
+
+// remove withdraws the item that push returned the handle of.
+// False is returned if it has already been popped or removed.
+func (q *DelayQueue[T]) remove(handle *heap.Handle[T, deadline]) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.heap.Remove(handle)
+}
+
+// PushAfter adds v to the queue, to become available once d has elapsed.
+func (q *DelayQueue[T]) PushAfter(v T, d time.Duration) error {
+	return q.Push(v, q.clock.Now().Add(d))
+}
+
+// TryPop removes and returns the earliest item if its deadline has passed.
+// False is returned if no item is due.
+func (q *DelayQueue[T]) TryPop() (t T, ok bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	item, ok := q.heap.Peek()
+	if !ok || item.Priority().at.After(q.clock.Now()) {
+		return t, false
+	}
+
+	q.heap.Extract()
+	return item.Value(), true
+}
+
+// Pop removes and returns the earliest item, waiting until its deadline has passed.
+// An error is returned if ctx is done first, or if the queue is closed and no item is due.
+func (q *DelayQueue[T]) Pop(ctx context.Context) (t T, err error) {
+	q.lock.Lock()
+	for {
+		item, ok := q.heap.Peek()
+		now := q.clock.Now()
+
+		if ok && !item.Priority().at.After(now) {
+			q.heap.Extract()
+			q.lock.Unlock()
+			return item.Value(), nil
+		}
+
+		if q.closed {
+			q.lock.Unlock()
+			return t, queue.ErrClosed
+		}
+
+		// Wait for the earliest deadline, or for a push that may have an earlier one.
+		var expired <-chan time.Time
+		var timer Timer
+		if ok {
+			timer = q.clock.NewTimer(item.Priority().at.Sub(now))
+			expired = timer.C()
+		}
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-expired:
+		case <-changed:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return t, err
+		}
+
+		q.lock.Lock()
+	}
+}
+
+// Close stops the queue from accepting new items and wakes up every blocked Pop.
+// Closing a closed queue has no effect.
+func (q *DelayQueue[T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.broadcast()
+	}
+}
+
+// broadcast wakes up every goroutine waiting on the current changed channel.
+// The caller must hold the lock.
+func (q *DelayQueue[T]) broadcast() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
@@ -0,0 +1,147 @@
+package delay
+
+import (
+	"context"
+	"errors"
+	"practice/collections/queue"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestDelayQueue_TryPopOnlyReturnsDueItems(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	q := NewDelayQueue[string](clock)
+	q.PushAfter("b", 2*time.Second)
+	q.PushAfter("a", time.Second)
+
+	if _, ok := q.TryPop(); ok {
+		t.Fatalf("TryPop() returned an item before its deadline")
+	}
+
+	clock.Advance(time.Second)
+	if v, ok := q.TryPop(); !ok || v != "a" {
+		t.Errorf("TryPop() = %v, %v, want %v, %v", v, ok, "a", true)
+	}
+	if _, ok := q.TryPop(); ok {
+		t.Errorf("TryPop() returned b before its deadline")
+	}
+
+	clock.Advance(time.Second)
+	if v, ok := q.TryPop(); !ok || v != "b" {
+		t.Errorf("TryPop() = %v, %v, want %v, %v", v, ok, "b", true)
+	}
+}
+
+func TestDelayQueue_SameDeadlineIsFIFO(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	q := NewDelayQueue[int](clock)
+	for i := 0; i < 10; i++ {
+		q.Push(i, epoch)
+	}
+
+	for want := 0; want < 10; want++ {
+		if got, _ := q.TryPop(); got != want {
+			t.Errorf("TryPop() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDelayQueue_PopWaitsForDeadline(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	q := NewDelayQueue[string](clock)
+	q.PushAfter("a", time.Minute)
+
+	got := make(chan string)
+	go func() {
+		v, _ := q.Pop(context.Background())
+		got <- v
+	}()
+
+	clock.BlockUntil(1)
+	select {
+	case v := <-got:
+		t.Fatalf("Pop() = %v before the deadline", v)
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	if v := <-got; v != "a" {
+		t.Errorf("Pop() = %v, want %v", v, "a")
+	}
+}
+
+func TestDelayQueue_PopWakesForEarlierItem(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	q := NewDelayQueue[string](clock)
+	q.PushAfter("late", time.Hour)
+
+	got := make(chan string)
+	go func() {
+		v, _ := q.Pop(context.Background())
+		got <- v
+	}()
+
+	clock.BlockUntil(1)
+	q.PushAfter("early", time.Second)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	if v := <-got; v != "early" {
+		t.Errorf("Pop() = %v, want %v", v, "early")
+	}
+}
+
+func TestDelayQueue_PopWithRealClock(t *testing.T) {
+	t.Parallel()
+	q := NewDelayQueue[int](RealClock{})
+	start := time.Now()
+	q.PushAfter(1, 20*time.Millisecond)
+
+	if v, err := q.Pop(context.Background()); err != nil || v != 1 {
+		t.Errorf("Pop() = %v, %v, want %v, nil", v, err, 1)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Pop() returned after %v, want at least %v", elapsed, 20*time.Millisecond)
+	}
+}
+
+func TestDelayQueue_PopHonorsContext(t *testing.T) {
+	t.Parallel()
+	q := NewDelayQueue[int](NewFakeClock(epoch))
+	q.PushAfter(1, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pop() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %v, want %v", q.Len(), 1)
+	}
+}
+
+func TestDelayQueue_Close(t *testing.T) {
+	t.Parallel()
+	q := NewDelayQueue[int](NewFakeClock(epoch))
+	q.PushAfter(1, time.Hour)
+
+	errs := make(chan error)
+	go func() {
+		_, err := q.Pop(context.Background())
+		errs <- err
+	}()
+
+	q.Close()
+	if err := <-errs; !errors.Is(err, queue.ErrClosed) {
+		t.Errorf("Pop() error = %v, want %v", err, queue.ErrClosed)
+	}
+	if err := q.PushAfter(2, 0); !errors.Is(err, queue.ErrClosed) {
+		t.Errorf("Push() error = %v, want %v", err, queue.ErrClosed)
+	}
+}
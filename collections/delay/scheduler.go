@@ -0,0 +1,145 @@
+package delay
+
+import (
+	"context"
+	"practice/collections/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scheduler runs callbacks at given times, or repeatedly at a fixed rate.
+// Every run happens on its own goroutine, so a slow callback does not delay the others.
+type Scheduler struct {
+	clock   Clock
+	queue   *DelayQueue[*Task]
+	stopped atomic.Bool
+	// dispatching is done once the dispatch loop has returned.
+	dispatching sync.WaitGroup
+	// running tracks callbacks that have not returned yet.
+	running sync.WaitGroup
+}
+
+// Task is a handle to a scheduled callback.
+type Task struct {
+	fn        func()
+	next      time.Time
+	interval  time.Duration
+	cancelled atomic.Bool
+	queue     *DelayQueue[*Task]
+	// lock guards handle, which locates the pending run in the queue.
+	lock   sync.Mutex
+	handle *heap.Handle[*Task, deadline]
+}
+
+// Cancel prevents any future run of the task and removes its pending run from the scheduler,
+// so a cancelled task is not held until its deadline.
+// A run that has already started is not interrupted.
+// False is returned if the task was already cancelled.
+func (t *Task) Cancel() bool {
+	if !t.cancelled.CompareAndSwap(false, true) {
+		return false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.handle != nil {
+		t.queue.remove(t.handle)
+		t.handle = nil
+	}
+	return true
+}
+
+// NewScheduler returns a running Scheduler that reads the time from clock.
+func NewScheduler(clock Clock) *Scheduler {
+	s := &Scheduler{
+		clock: clock,
+		queue: NewDelayQueue[*Task](clock),
+	}
+
+	s.dispatching.Add(1)
+	go s.dispatch()
+
+	return s
+}
+
+// At runs fn once at the given time.
+func (s *Scheduler) At(at time.Time, fn func()) *Task {
+	return s.schedule(&Task{fn: fn, next: at, queue: s.queue})
+}
+
+// After runs fn once after d has elapsed.
+func (s *Scheduler) After(d time.Duration, fn func()) *Task {
+	return s.At(s.clock.Now().Add(d), fn)
+}
+
+// Every runs fn every interval, starting one interval from now.
+// Runs are scheduled at a fixed rate, so they may overlap if fn takes longer than interval.
+func (s *Scheduler) Every(interval time.Duration, fn func()) *Task {
+	if interval <= 0 {
+		panic("delay: interval must be positive")
+	}
+
+	return s.schedule(&Task{
+		fn:       fn,
+		next:     s.clock.Now().Add(interval),
+		interval: interval,
+		queue:    s.queue,
+	})
+}
+
+// Stop cancels every pending run and waits for running callbacks to return.
+// Tasks scheduled after Stop never run.
+func (s *Scheduler) Stop() {
+	s.stopped.Store(true)
+	s.queue.Close()
+	s.dispatching.Wait()
+	s.running.Wait()
+}
+
+// schedule queues the next run of task, unless it was cancelled.
+// It holds the task's lock so a concurrent Cancel either sees the new handle or stops the run from being queued.
+func (s *Scheduler) schedule(task *Task) *Task {
+	task.lock.Lock()
+	defer task.lock.Unlock()
+
+	if task.cancelled.Load() {
+		return task
+	}
+
+	handle, err := s.queue.push(task, task.next)
+	if err != nil {
+		// The scheduler is stopped, so the task can never run.
+		task.cancelled.Store(true)
+		return task
+	}
+	task.handle = handle
+	return task
+}
+
+func (s *Scheduler) dispatch() {
+	defer s.dispatching.Done()
+
+	for {
+		task, err := s.queue.Pop(context.Background())
+		if err != nil || s.stopped.Load() {
+			return
+		}
+
+		if task.cancelled.Load() {
+			continue
+		}
+
+		if task.interval > 0 {
+			task.next = task.next.Add(task.interval)
+			s.schedule(task)
+		}
+
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			task.fn()
+		}()
+	}
+}
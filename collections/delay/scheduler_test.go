@@ -0,0 +1,121 @@
+package delay
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_After(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	s := NewScheduler(clock)
+	defer s.Stop()
+
+	ran := make(chan time.Time, 1)
+	s.After(time.Second, func() { ran <- clock.Now() })
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	if at := <-ran; !at.Equal(epoch.Add(time.Second)) {
+		t.Errorf("task ran at %v, want %v", at, epoch.Add(time.Second))
+	}
+}
+
+func TestScheduler_Every(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	s := NewScheduler(clock)
+	defer s.Stop()
+
+	ran := make(chan struct{}, 10)
+	task := s.Every(time.Minute, func() { ran <- struct{}{} })
+
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		<-ran
+	}
+
+	if !task.Cancel() {
+		t.Errorf("Cancel() = false, want true")
+	}
+	if task.Cancel() {
+		t.Errorf("second Cancel() = true, want false")
+	}
+	if n := s.queue.Len(); n != 0 {
+		t.Errorf("%v runs are still queued after Cancel, want 0", n)
+	}
+
+	clock.Advance(time.Minute)
+	s.Stop()
+
+	if len(ran) != 0 {
+		t.Errorf("cancelled task ran %v more times", len(ran))
+	}
+}
+
+func TestScheduler_CancelBeforeRun(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	s := NewScheduler(clock)
+
+	var runs atomic.Int32
+	task := s.After(time.Second, func() { runs.Add(1) })
+	task.Cancel()
+	if n := s.queue.Len(); n != 0 {
+		t.Errorf("%v runs are still queued after Cancel, want 0", n)
+	}
+
+	clock.Advance(time.Second)
+	s.Stop()
+
+	if n := runs.Load(); n != 0 {
+		t.Errorf("cancelled task ran %v times", n)
+	}
+}
+
+func TestScheduler_OrderOfTasks(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(epoch)
+	s := NewScheduler(clock)
+	defer s.Stop()
+
+	ran := make(chan int, 3)
+	s.At(epoch.Add(3*time.Second), func() { ran <- 3 })
+	s.At(epoch.Add(1*time.Second), func() { ran <- 1 })
+	s.At(epoch.Add(2*time.Second), func() { ran <- 2 })
+
+	for want := 1; want <= 3; want++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		if got := <-ran; got != want {
+			t.Errorf("task %v ran, want %v", got, want)
+		}
+	}
+}
+
+func TestScheduler_StopWaitsForRunningTasks(t *testing.T) {
+	t.Parallel()
+	s := NewScheduler(RealClock{})
+
+	var finished atomic.Bool
+	started := make(chan struct{})
+	s.After(0, func() {
+		close(started)
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(true)
+	})
+
+	<-started
+	s.Stop()
+	if !finished.Load() {
+		t.Errorf("Stop() returned before the running task finished")
+	}
+
+	task := s.After(0, func() {})
+	if task.Cancel() {
+		t.Errorf("a task scheduled after Stop should already be cancelled")
+	}
+}
@@ -0,0 +1,179 @@
+package deque
+
+import "iter"
+
+// minCapacity is the smallest ring buffer a non-empty Deque uses. It must be a power of two.
+const minCapacity = 8
+
+// Deque is a double-ended queue stored in a ring buffer.
+// Pushing and popping at either end and indexing are O(1). The buffer doubles when it is full
+// and halves when it is a quarter full, so memory stays proportional to the number of elements.
+type Deque[T any] struct {
+	// buf has a power of two length, so indices wrap around with a mask.
+	buf  []T
+	head int
+	size int
+}
+
+// New returns an empty deque.
+func New[T any]() *Deque[T] {
+	return &Deque[T]{}
+}
+
+// Len returns the number of elements in the deque.
+func (d *Deque[T]) Len() int {
+	return d.size
+}
+
+// PushFront adds 'v' to the front of the deque.
+func (d *Deque[T]) PushFront(v T) {
+	d.grow()
+	d.head = d.index(-1)
+	d.buf[d.head] = v
+	d.size++
+}
+
+// PushBack adds 'v' to the back of the deque.
+func (d *Deque[T]) PushBack(v T) {
+	d.grow()
+	d.buf[d.index(d.size)] = v
+	d.size++
+}
+
+// PopFront removes and returns the element at the front of the deque.
+// False is returned if the deque is empty.
+func (d *Deque[T]) PopFront() (t T, ok bool) {
+	if d.size == 0 {
+		return t, false
+	}
+
+	v := d.buf[d.head]
+	d.buf[d.head] = t
+	d.head = d.index(1)
+	d.size--
+	d.shrink()
+	return v, true
+}
+
+// PopBack removes and returns the element at the back of the deque.
+// False is returned if the deque is empty.
+func (d *Deque[T]) PopBack() (t T, ok bool) {
+	if d.size == 0 {
+		return t, false
+	}
+
+	i := d.index(d.size - 1)
+	v := d.buf[i]
+	d.buf[i] = t
+	d.size--
+	d.shrink()
+	return v, true
+}
+
+// Front returns the element at the front of the deque without removing it.
+// False is returned if the deque is empty.
+func (d *Deque[T]) Front() (t T, ok bool) {
+	if d.size == 0 {
+		return t, false
+	}
+
+	return d.buf[d.head], true
+}
+
+// Back returns the element at the back of the deque without removing it.
+// False is returned if the deque is empty.
+func (d *Deque[T]) Back() (t T, ok bool) {
+	if d.size == 0 {
+		return t, false
+	}
+
+	return d.buf[d.index(d.size-1)], true
+}
+
+// At returns the i-th element counting from the front. It panics if i is out of range.
+func (d *Deque[T]) At(i int) T {
+	d.check(i)
+	return d.buf[d.index(i)]
+}
+
+// Set replaces the i-th element counting from the front. It panics if i is out of range.
+func (d *Deque[T]) Set(i int, v T) {
+	d.check(i)
+	d.buf[d.index(i)] = v
+}
+
+// Clear removes every element from the deque.
+func (d *Deque[T]) Clear() {
+	d.buf = nil
+	d.head = 0
+	d.size = 0
+}
+
+// All returns an iterator over the indices and elements from front to back.
+func (d *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < d.size; i++ {
+			if !yield(i, d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the indices and elements from back to front.
+func (d *Deque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := d.size - 1; i >= 0; i-- {
+			if !yield(i, d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// index maps a position relative to the front onto the ring buffer.
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) & (len(d.buf) - 1)
+}
+
+func (d *Deque[T]) check(i int) {
+	if i < 0 || i >= d.size {
+		panic("deque: index out of range")
+	}
+}
+
+// grow makes room for one more element.
+func (d *Deque[T]) grow() {
+	if d.size < len(d.buf) {
+		return
+	}
+
+	if len(d.buf) == 0 {
+		d.buf = make([]T, minCapacity)
+		d.head = 0
+		return
+	}
+
+	d.resize(len(d.buf) * 2)
+}
+
+// shrink halves the buffer once it is only a quarter full.
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > minCapacity && d.size <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+}
+
+// resize moves the elements to the start of a new buffer of the given capacity.
+func (d *Deque[T]) resize(capacity int) {
+	buf := make([]T, capacity)
+	if d.head+d.size <= len(d.buf) {
+		copy(buf, d.buf[d.head:d.head+d.size])
+	} else {
+		n := copy(buf, d.buf[d.head:])
+		copy(buf[n:], d.buf[:d.size-n])
+	}
+
+	d.buf = buf
+	d.head = 0
+}
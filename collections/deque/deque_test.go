@@ -0,0 +1,258 @@
+package deque
+
+import (
+	"container/list"
+	"fmt"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	genericlist "practice/collections/list"
+)
+
+func Example() {
+	d := New[int]()
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+
+	for i, v := range d.All() {
+		fmt.Println(i, v)
+	}
+	// Output:
+	// 0 1
+	// 1 2
+	// 2 3
+}
+
+func TestPushAndPop(t *testing.T) {
+	t.Parallel()
+	d := New[int]()
+	d.PushBack(2)
+	d.PushFront(1)
+	d.PushBack(3)
+
+	if d.Len() != 3 {
+		t.Errorf("Len() = %v, want %v", d.Len(), 3)
+	}
+	if v, _ := d.Front(); v != 1 {
+		t.Errorf("Front() = %v, want %v", v, 1)
+	}
+	if v, _ := d.Back(); v != 3 {
+		t.Errorf("Back() = %v, want %v", v, 3)
+	}
+	if v, _ := d.PopFront(); v != 1 {
+		t.Errorf("PopFront() = %v, want %v", v, 1)
+	}
+	if v, _ := d.PopBack(); v != 3 {
+		t.Errorf("PopBack() = %v, want %v", v, 3)
+	}
+	if v, _ := d.PopBack(); v != 2 {
+		t.Errorf("PopBack() = %v, want %v", v, 2)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	t.Parallel()
+	d := New[int]()
+	if _, ok := d.PopFront(); ok {
+		t.Errorf("PopFront() on an empty deque returned ok")
+	}
+	if _, ok := d.PopBack(); ok {
+		t.Errorf("PopBack() on an empty deque returned ok")
+	}
+	if _, ok := d.Front(); ok {
+		t.Errorf("Front() on an empty deque returned ok")
+	}
+	if _, ok := d.Back(); ok {
+		t.Errorf("Back() on an empty deque returned ok")
+	}
+}
+
+func TestAtOutOfRangePanics(t *testing.T) {
+	t.Parallel()
+	d := New[int]()
+	d.PushBack(1)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("At(1) on a deque of length 1 did not panic")
+		}
+	}()
+	d.At(1)
+}
+
+func TestGrowAndShrink(t *testing.T) {
+	t.Parallel()
+	d := New[int]()
+	for i := 0; i < 1000; i++ {
+		d.PushFront(i)
+	}
+	if len(d.buf) != 1024 {
+		t.Errorf("capacity = %v, want %v", len(d.buf), 1024)
+	}
+
+	for i := 0; i < 990; i++ {
+		d.PopBack()
+	}
+	if len(d.buf) > 4*d.Len() && len(d.buf) > minCapacity {
+		t.Errorf("capacity %v did not shrink for %v elements", len(d.buf), d.Len())
+	}
+	for i := 0; i < d.Len(); i++ {
+		if d.At(i) != 999-i {
+			t.Errorf("At(%v) = %v, want %v", i, d.At(i), 999-i)
+		}
+	}
+}
+
+func TestBackward(t *testing.T) {
+	t.Parallel()
+	d := New[string]()
+	for _, s := range []string{"a", "b", "c"} {
+		d.PushBack(s)
+	}
+
+	var got []string
+	for i, v := range d.Backward() {
+		if d.At(i) != v {
+			t.Errorf("Backward() yielded %v at %v, want %v", v, i, d.At(i))
+		}
+		got = append(got, v)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != "c" || got[1] != "b" {
+		t.Errorf("got %v, want %v", got, []string{"c", "b"})
+	}
+}
+
+// Property: a deque behaves like a slice under any sequence of operations at both ends
+func TestDequeMatchesSlice(t *testing.T) {
+	t.Parallel()
+	f := func(ops []uint8) bool {
+		d := New[int]()
+		var model []int
+
+		for i, op := range ops {
+			switch op % 5 {
+			case 0:
+				d.PushFront(i)
+				model = append([]int{i}, model...)
+			case 1, 2:
+				d.PushBack(i)
+				model = append(model, i)
+			case 3:
+				v, ok := d.PopFront()
+				if ok != (len(model) > 0) || (ok && v != model[0]) {
+					return false
+				}
+				if ok {
+					model = model[1:]
+				}
+			case 4:
+				v, ok := d.PopBack()
+				if ok != (len(model) > 0) || (ok && v != model[len(model)-1]) {
+					return false
+				}
+				if ok {
+					model = model[:len(model)-1]
+				}
+			}
+
+			if d.Len() != len(model) {
+				return false
+			}
+		}
+
+		for i, v := range d.All() {
+			if model[i] != v {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+// The benchmarks compare the deque against linked lists used as deques with a sliding window:
+// every iteration pushes one element at the back and, once the window is full, pops one at the front.
+const window = 1024
+
+func BenchmarkSlidingWindow(b *testing.B) {
+	b.Run("Deque", func(b *testing.B) {
+		d := New[int]()
+		for i := 0; i < b.N; i++ {
+			d.PushBack(i)
+			if d.Len() > window {
+				d.PopFront()
+			}
+		}
+	})
+
+	b.Run("list.List", func(b *testing.B) {
+		l := genericlist.New[int]()
+		for i := 0; i < b.N; i++ {
+			l.PushBack(i)
+			if l.Len() > window {
+				l.Remove(l.Front)
+			}
+		}
+	})
+
+	b.Run("container/list", func(b *testing.B) {
+		l := list.New()
+		for i := 0; i < b.N; i++ {
+			l.PushBack(i)
+			if l.Len() > window {
+				l.Remove(l.Front())
+			}
+		}
+	})
+}
+
+func BenchmarkRandomEnds(b *testing.B) {
+	ops := make([]int, 4096)
+	r := rand.New(rand.NewSource(1))
+	for i := range ops {
+		ops[i] = r.Intn(4)
+	}
+
+	b.Run("Deque", func(b *testing.B) {
+		d := New[int]()
+		for i := 0; i < b.N; i++ {
+			switch ops[i%len(ops)] {
+			case 0:
+				d.PushFront(i)
+			case 1:
+				d.PushBack(i)
+			case 2:
+				d.PopFront()
+			case 3:
+				d.PopBack()
+			}
+		}
+	})
+
+	b.Run("list.List", func(b *testing.B) {
+		l := genericlist.New[int]()
+		for i := 0; i < b.N; i++ {
+			switch ops[i%len(ops)] {
+			case 0:
+				l.PushFront(i)
+			case 1:
+				l.PushBack(i)
+			case 2:
+				if l.Front != nil {
+					l.Remove(l.Front)
+				}
+			case 3:
+				if l.Back != nil {
+					l.Remove(l.Back)
+				}
+			}
+		}
+	})
+}
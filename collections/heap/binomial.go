@@ -0,0 +1,257 @@
+package heap
+
+// BinomialHeap is a forest of binomial trees with O(log n) Insert, Extract, Meld,
+// DecreaseKey and Remove.
+type BinomialHeap[T any] struct {
+	// head is the first root, and roots are linked in increasing order of degree.
+	head  *binomialTree[T]
+	size  int
+	less  func(a, b T) bool
+	owner *owner
+}
+
+// BinomialNode is a handle to a value stored in a BinomialHeap.
+type BinomialNode[T any] struct {
+	value T
+	tree  *binomialTree[T]
+	owner *owner
+}
+
+// Value returns the value stored in the node.
+func (n *BinomialNode[T]) Value() T {
+	return n.value
+}
+
+// binomialTree is a position in the heap. Values move between positions when a key
+// decreases, so handles point to the position that currently holds them.
+type binomialTree[T any] struct {
+	node                   *BinomialNode[T]
+	parent, child, sibling *binomialTree[T]
+	degree                 int
+}
+
+// NewBinomial returns an empty binomial heap ordered by less.
+func NewBinomial[T any](less func(a, b T) bool) *BinomialHeap[T] {
+	return &BinomialHeap[T]{
+		less:  less,
+		owner: &owner{},
+	}
+}
+
+// Len returns the number of elements in the heap.
+func (h *BinomialHeap[T]) Len() int {
+	return h.size
+}
+
+// Insert adds value to the heap.
+func (h *BinomialHeap[T]) Insert(value T) {
+	h.InsertNode(value)
+}
+
+// InsertNode adds value to the heap and returns a handle for DecreaseKey and Remove.
+func (h *BinomialHeap[T]) InsertNode(value T) *BinomialNode[T] {
+	n := &BinomialNode[T]{value: value, owner: h.owner}
+	n.tree = &binomialTree[T]{node: n}
+	h.union(n.tree)
+	h.size++
+	return n
+}
+
+// Peek returns the next element to be extracted without removing it.
+// False is returned if the heap is empty.
+func (h *BinomialHeap[T]) Peek() (t T, ok bool) {
+	top, _ := h.top()
+	if top == nil {
+		return t, false
+	}
+
+	return top.node.value, true
+}
+
+// Extract removes and returns the element at the top of the heap.
+// False is returned if the heap is empty.
+func (h *BinomialHeap[T]) Extract() (t T, ok bool) {
+	top, prev := h.top()
+	if top == nil {
+		return t, false
+	}
+
+	n := top.node
+	h.removeRoot(top, prev)
+	return n.value, true
+}
+
+// Drain removes every element from the heap and returns them in extraction order.
+func (h *BinomialHeap[T]) Drain() []T {
+	result := make([]T, 0, h.size)
+	for h.head != nil {
+		v, _ := h.Extract()
+		result = append(result, v)
+	}
+	return result
+}
+
+// Contains reports whether the node is still stored in this heap.
+func (h *BinomialHeap[T]) Contains(n *BinomialNode[T]) bool {
+	return n != nil && n.owner != nil && n.owner.resolve() == h.owner
+}
+
+// Meld moves every element of other into h in O(log n), leaving other empty.
+// Handles from other remain valid and now refer to elements of h.
+func (h *BinomialHeap[T]) Meld(other *BinomialHeap[T]) {
+	if other == h || other.head == nil {
+		return
+	}
+
+	h.union(other.head)
+	h.size += other.size
+
+	other.head = nil
+	other.size = 0
+	other.owner = h.owner.absorb(other.owner)
+}
+
+// DecreaseKey replaces the node's value with one that is extracted no later than the current one.
+// False is returned if the node is not in the heap, or if value would be extracted after the current value.
+func (h *BinomialHeap[T]) DecreaseKey(n *BinomialNode[T], value T) bool {
+	if !h.Contains(n) || h.less(n.value, value) {
+		return false
+	}
+
+	n.value = value
+	h.bubbleUp(n.tree, false)
+	return true
+}
+
+// Remove deletes the node from the heap.
+// False is returned if the node is not in the heap.
+func (h *BinomialHeap[T]) Remove(n *BinomialNode[T]) bool {
+	if !h.Contains(n) {
+		return false
+	}
+
+	// Move the node to the root of its tree as if its key were minus infinity.
+	root := h.bubbleUp(n.tree, true)
+
+	var prev *binomialTree[T]
+	for x := h.head; x != root; x = x.sibling {
+		prev = x
+	}
+
+	h.removeRoot(root, prev)
+	return true
+}
+
+// top returns the root holding the next element to extract, and the root before it.
+func (h *BinomialHeap[T]) top() (top, prev *binomialTree[T]) {
+	var before *binomialTree[T]
+	for x := h.head; x != nil; before, x = x, x.sibling {
+		if top == nil || h.less(x.node.value, top.node.value) {
+			top, prev = x, before
+		}
+	}
+	return top, prev
+}
+
+// removeRoot removes the root x, whose predecessor in the root list is prev, and
+// merges its children back into the heap.
+func (h *BinomialHeap[T]) removeRoot(x, prev *binomialTree[T]) {
+	if prev == nil {
+		h.head = x.sibling
+	} else {
+		prev.sibling = x.sibling
+	}
+
+	// The children are linked in decreasing order of degree, so reverse them.
+	var children *binomialTree[T]
+	for child := x.child; child != nil; {
+		next := child.sibling
+		child.parent = nil
+		child.sibling = children
+		children = child
+		child = next
+	}
+	h.union(children)
+
+	x.node.tree = nil
+	x.node.owner = nil
+	h.size--
+}
+
+// bubbleUp moves the value at x towards the root while it belongs above its parent,
+// or all the way up if force is set, and returns its final position.
+func (h *BinomialHeap[T]) bubbleUp(x *binomialTree[T], force bool) *binomialTree[T] {
+	for x.parent != nil && (force || h.less(x.node.value, x.parent.node.value)) {
+		p := x.parent
+		x.node, p.node = p.node, x.node
+		x.node.tree = x
+		p.node.tree = p
+		x = p
+	}
+	return x
+}
+
+// union merges the root list starting at other into the heap, linking trees of equal degree.
+func (h *BinomialHeap[T]) union(other *binomialTree[T]) {
+	head := h.mergeRoots(h.head, other)
+	if head == nil {
+		h.head = nil
+		return
+	}
+
+	var prev *binomialTree[T]
+	x := head
+	next := x.sibling
+	for next != nil {
+		if x.degree != next.degree || (next.sibling != nil && next.sibling.degree == x.degree) {
+			prev = x
+			x = next
+		} else if !h.less(next.node.value, x.node.value) {
+			x.sibling = next.sibling
+			h.link(next, x)
+		} else {
+			if prev == nil {
+				head = next
+			} else {
+				prev.sibling = next
+			}
+			h.link(x, next)
+			x = next
+		}
+		next = x.sibling
+	}
+
+	h.head = head
+}
+
+// mergeRoots merges two root lists into one ordered by degree.
+func (h *BinomialHeap[T]) mergeRoots(a, b *binomialTree[T]) *binomialTree[T] {
+	var head, tail *binomialTree[T]
+	for a != nil || b != nil {
+		var next *binomialTree[T]
+		if b == nil || (a != nil && a.degree <= b.degree) {
+			next, a = a, a.sibling
+		} else {
+			next, b = b, b.sibling
+		}
+
+		if tail == nil {
+			head = next
+		} else {
+			tail.sibling = next
+		}
+		tail = next
+	}
+	if tail != nil {
+		tail.sibling = nil
+	}
+	return head
+}
+
+// link makes the tree y the leftmost child of the tree z of the same degree.
+func (h *BinomialHeap[T]) link(y, z *binomialTree[T]) {
+	y.parent = z
+	y.sibling = z.child
+	z.child = y
+	z.degree++
+}
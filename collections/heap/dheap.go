@@ -1,69 +1,175 @@
 package heap
 
-type DHeap struct {
+// DHeap is a d-ary heap ordered by a user supplied comparison function.
+// A larger degree makes the heap shallower, which speeds up Insert at the cost of
+// comparing more children on every Extract.
+type DHeap[T any] struct {
 	degree int
-	heap   []int
+	heap   []T
+	// less reports whether a should be extracted before b.
+	less func(a, b T) bool
+	// moved, if set, is called whenever an element is stored at a new index.
+	moved func(v T, i int)
 }
 
-func NewDHeap(degree int) *DHeap {
-	return &DHeap{
+// New returns an empty heap with the given degree, ordered by less.
+// The element for which less reports true against every other element is extracted first,
+// so "a < b" builds a min-heap and "a > b" builds a max-heap.
+func New[T any](degree int, less func(a, b T) bool) *DHeap[T] {
+	if degree < 2 {
+		panic("heap: degree must be at least 2")
+	}
+
+	return &DHeap[T]{
 		degree: degree,
-		heap:   []int{},
+		heap:   []T{},
+		less:   less,
 	}
 }
 
-func (h *DHeap) Insert(value int) {
+// FromSlice builds a heap containing the given items in O(n).
+// The heap takes ownership of items and reorders it in place.
+func FromSlice[T any](degree int, items []T, less func(a, b T) bool) *DHeap[T] {
+	h := New(degree, less)
+	h.heap = items
+	h.heapify()
+	return h
+}
+
+// NewDHeap returns an empty max-heap of ints with the given degree.
+func NewDHeap(degree int) *DHeap[int] {
+	return New(degree, func(a, b int) bool {
+		return a > b
+	})
+}
+
+// Len returns the number of elements in the heap.
+func (h *DHeap[T]) Len() int {
+	return len(h.heap)
+}
+
+// Insert adds value to the heap.
+func (h *DHeap[T]) Insert(value T) {
 	h.heap = append(h.heap, value)
+	h.notify(len(h.heap) - 1)
 	h.bubbleUp(len(h.heap) - 1)
 }
 
-func (h *DHeap) bubbleUp(i int) {
-	parent := h.parent(i)
-	for i > 0 && h.heap[parent] < h.heap[i] {
-		h.heap[i], h.heap[parent] = h.heap[parent], h.heap[i]
-		i = parent
-		parent = h.parent(i)
+// Peek returns the next element to be extracted without removing it.
+// False is returned if the heap is empty.
+func (h *DHeap[T]) Peek() (t T, ok bool) {
+	if len(h.heap) == 0 {
+		return t, false
 	}
-}
 
-func (h *DHeap) parent(i int) int {
-	return (i - 1) / h.degree
+	return h.heap[0], true
 }
 
-func (h *DHeap) ExtractMax() (int, bool) {
+// Extract removes and returns the element at the top of the heap.
+// False is returned if the heap is empty.
+func (h *DHeap[T]) Extract() (t T, ok bool) {
 	if len(h.heap) == 0 {
-		return 0, false
+		return t, false
+	}
+
+	return h.removeAt(0), true
+}
+
+// ExtractMax removes and returns the element at the top of the heap, like Extract.
+// The top is the maximum for max-heaps such as those NewDHeap returns.
+func (h *DHeap[T]) ExtractMax() (T, bool) {
+	return h.Extract()
+}
+
+// Drain removes every element from the heap and returns them in extraction order.
+func (h *DHeap[T]) Drain() []T {
+	result := make([]T, 0, len(h.heap))
+	for len(h.heap) > 0 {
+		result = append(result, h.removeAt(0))
+	}
+	return result
+}
+
+// removeAt removes and returns the element at index i, restoring the heap property.
+func (h *DHeap[T]) removeAt(i int) T {
+	var zero T
+	last := len(h.heap) - 1
+	removed := h.heap[i]
+
+	if i != last {
+		h.swap(i, last)
 	}
+	h.heap[last] = zero
+	h.heap = h.heap[:last]
 
-	max := h.heap[0]
-	h.heap[0] = h.heap[len(h.heap)-1]
-	h.heap = h.heap[:len(h.heap)-1]
+	if i != last {
+		// The element moved into i may belong either above or below it.
+		h.fix(i)
+	}
 
-	h.bubbleDown(0)
+	return removed
+}
 
-	return max, true
+// fix restores the heap property after the element at index i has changed.
+func (h *DHeap[T]) fix(i int) {
+	h.bubbleDown(i)
+	h.bubbleUp(i)
 }
 
-func (h *DHeap) bubbleDown(i int) {
-	max := i
-	children := h.childrenIndices(i)
+func (h *DHeap[T]) heapify() {
+	for i := range h.heap {
+		h.notify(i)
+	}
+
+	// Leaves already satisfy the heap property, so start at the last parent.
+	for i := h.parent(len(h.heap) - 1); i >= 0; i-- {
+		h.bubbleDown(i)
+	}
+}
 
-	for _, child := range children {
-		if child < len(h.heap) && h.heap[child] > h.heap[max] {
-			max = child
+func (h *DHeap[T]) bubbleUp(i int) {
+	for i > 0 {
+		parent := h.parent(i)
+		if !h.less(h.heap[i], h.heap[parent]) {
+			return
 		}
+		h.swap(i, parent)
+		i = parent
 	}
+}
 
-	if max != i {
-		h.heap[i], h.heap[max] = h.heap[max], h.heap[i]
-		h.bubbleDown(max)
+func (h *DHeap[T]) bubbleDown(i int) {
+	for {
+		top := i
+		first := h.degree*i + 1
+
+		for child := first; child < first+h.degree && child < len(h.heap); child++ {
+			if h.less(h.heap[child], h.heap[top]) {
+				top = child
+			}
+		}
+
+		if top == i {
+			return
+		}
+
+		h.swap(i, top)
+		i = top
 	}
 }
 
-func (h *DHeap) childrenIndices(i int) []int {
-	children := make([]int, h.degree)
-	for j := 0; j < h.degree; j++ {
-		children[j] = h.degree*i + j + 1
+func (h *DHeap[T]) parent(i int) int {
+	return (i - 1) / h.degree
+}
+
+func (h *DHeap[T]) swap(i, j int) {
+	h.heap[i], h.heap[j] = h.heap[j], h.heap[i]
+	h.notify(i)
+	h.notify(j)
+}
+
+func (h *DHeap[T]) notify(i int) {
+	if h.moved != nil {
+		h.moved(h.heap[i], i)
 	}
-	return children
 }
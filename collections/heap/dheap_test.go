@@ -1,7 +1,9 @@
 package heap
 
 import (
+	"sort"
 	"testing"
+	"testing/quick"
 )
 
 func TestNewDHeap(t *testing.T) {
@@ -66,3 +68,119 @@ func TestBubbleUpAndDown(t *testing.T) {
 		t.Errorf("Expected max to be 6, got %d", max)
 	}
 }
+
+func TestMinHeap(t *testing.T) {
+	t.Parallel()
+	h := New(4, func(a, b int) bool { return a < b })
+	h.Insert(5)
+	h.Insert(10)
+	h.Insert(3)
+
+	min, _ := h.Extract()
+	if min != 3 {
+		t.Errorf("Expected min to be 3, got %d", min)
+	}
+
+	min, _ = h.Extract()
+	if min != 5 {
+		t.Errorf("Expected min to be 5, got %d", min)
+	}
+}
+
+func TestCustomComparator(t *testing.T) {
+	t.Parallel()
+	type task struct {
+		name     string
+		priority int
+	}
+
+	h := New(2, func(a, b task) bool { return a.priority < b.priority })
+	h.Insert(task{name: "write", priority: 2})
+	h.Insert(task{name: "read", priority: 1})
+	h.Insert(task{name: "sync", priority: 3})
+
+	next, ok := h.Extract()
+	if !ok || next.name != "read" {
+		t.Errorf("Expected read, got %v", next)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	t.Parallel()
+	h := NewDHeap(2)
+	if _, ok := h.Peek(); ok {
+		t.Errorf("Expected ok to be false on an empty heap")
+	}
+
+	h.Insert(1)
+	h.Insert(7)
+	top, ok := h.Peek()
+	if !ok || top != 7 {
+		t.Errorf("Expected peek to be 7, got %d", top)
+	}
+	if h.Len() != 2 {
+		t.Errorf("Expected Peek to leave 2 elements, got %d", h.Len())
+	}
+}
+
+func TestNewWithInvalidDegreePanics(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected New to panic for degree 1")
+		}
+	}()
+	New(1, func(a, b int) bool { return a < b })
+}
+
+func TestFromSlice(t *testing.T) {
+	t.Parallel()
+	h := FromSlice(3, []int{4, 9, 1, 7, 3, 8}, func(a, b int) bool { return a < b })
+	if h.Len() != 6 {
+		t.Errorf("Expected length 6, got %d", h.Len())
+	}
+
+	want := []int{1, 3, 4, 7, 8, 9}
+	got := h.Drain()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+	if h.Len() != 0 {
+		t.Errorf("Expected Drain to empty the heap, got %d elements", h.Len())
+	}
+}
+
+// Property: draining a heap of any degree yields its elements in sorted order
+func TestDrainIsSorted(t *testing.T) {
+	t.Parallel()
+	f := func(xs []int, degree uint8) bool {
+		d := int(degree%8) + 2
+
+		inserted := New(d, func(a, b int) bool { return a < b })
+		for _, x := range xs {
+			inserted.Insert(x)
+		}
+		heapified := FromSlice(d, append([]int(nil), xs...), func(a, b int) bool { return a < b })
+
+		want := append([]int(nil), xs...)
+		sort.Ints(want)
+
+		for _, got := range [][]int{inserted.Drain(), heapified.Drain()} {
+			if len(got) != len(want) {
+				return false
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,40 @@
+package heap
+
+// Heap is a priority queue that hands out its elements in the order defined by a comparison function.
+// DHeap, PairingHeap and BinomialHeap all implement it.
+type Heap[T any] interface {
+	// Insert adds value to the heap.
+	Insert(value T)
+	// Peek returns the next element to be extracted without removing it.
+	Peek() (T, bool)
+	// Extract removes and returns the element at the top of the heap.
+	Extract() (T, bool)
+	// Len returns the number of elements in the heap.
+	Len() int
+	// Drain removes every element from the heap and returns them in extraction order.
+	Drain() []T
+}
+
+// owner identifies the heap that a node belongs to.
+// Melding forwards the owner of the emptied heap to the owner of the other one,
+// so nodes never need to be visited to move them between heaps.
+type owner struct {
+	next *owner
+}
+
+// resolve returns the owner at the end of the forwarding chain, compressing the path as it goes.
+func (o *owner) resolve() *owner {
+	for o.next != nil {
+		if o.next.next != nil {
+			o.next = o.next.next
+		}
+		o = o.next
+	}
+	return o
+}
+
+// absorb makes every node owned by other belong to o, and returns a fresh owner for other.
+func (o *owner) absorb(other *owner) *owner {
+	other.next = o
+	return &owner{}
+}
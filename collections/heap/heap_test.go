@@ -0,0 +1,200 @@
+package heap
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/quick"
+)
+
+func heaps() []struct {
+	name string
+	new  func() Heap[int]
+} {
+	return []struct {
+		name string
+		new  func() Heap[int]
+	}{
+		{name: "Binary DHeap", new: func() Heap[int] { return New(2, lessInt) }},
+		{name: "4-ary DHeap", new: func() Heap[int] { return New(4, lessInt) }},
+		{name: "Pairing Heap", new: func() Heap[int] { return NewPairing(lessInt) }},
+		{name: "Binomial Heap", new: func() Heap[int] { return NewBinomial(lessInt) }},
+	}
+}
+
+// mergeable is implemented by the heaps that support Meld, DecreaseKey and Remove.
+type mergeable[H any, N any] interface {
+	Heap[int]
+	InsertNode(value int) N
+	Contains(n N) bool
+	DecreaseKey(n N, value int) bool
+	Remove(n N) bool
+	Meld(other H)
+}
+
+func TestHeaps(t *testing.T) {
+	// Property: all heaps should extract the same elements in the same (sorted) order
+	heapProperty := func(given []int) bool {
+		want := append([]int(nil), given...)
+		sort.Ints(want)
+
+		for _, test := range heaps() {
+			h := test.new()
+			for _, v := range given {
+				h.Insert(v)
+			}
+
+			if h.Len() != len(given) {
+				t.Errorf("heap: %s: Len() = %v, want %v", test.name, h.Len(), len(given))
+			}
+			if len(want) > 0 {
+				if top, ok := h.Peek(); !ok || top != want[0] {
+					t.Errorf("heap: %s: Peek() = %v, want %v", test.name, top, want[0])
+				}
+			}
+
+			got := h.Drain()
+			if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+				t.Errorf("heap: %s: got %v want %v", test.name, got, want)
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(heapProperty, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHeaps_InterleavedOperations(t *testing.T) {
+	// Property: all heaps agree on every Extract when inserts and extracts are interleaved
+	interleavedProperty := func(given []int, seed int64) bool {
+		for _, test := range heaps() {
+			r := rand.New(rand.NewSource(seed))
+			h := test.new()
+			var model []int
+
+			for _, v := range given {
+				h.Insert(v)
+				model = append(model, v)
+
+				if r.Intn(3) == 0 {
+					sort.Ints(model)
+					got, _ := h.Extract()
+					if got != model[0] {
+						t.Errorf("heap: %s: Extract() = %v, want %v", test.name, got, model[0])
+						return false
+					}
+					model = model[1:]
+				}
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(interleavedProperty, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMergeableHeaps(t *testing.T) {
+	t.Run("Pairing Heap", func(t *testing.T) {
+		testMergeable(t, func() *PairingHeap[int] { return NewPairing(lessInt) })
+	})
+	t.Run("Binomial Heap", func(t *testing.T) {
+		testMergeable(t, func() *BinomialHeap[int] { return NewBinomial(lessInt) })
+	})
+}
+
+func testMergeable[H mergeable[H, N], N comparable](t *testing.T, newHeap func() H) {
+	// Property: after melding, decreasing and removing keys, the heap holds exactly the model's values
+	// (int32 inputs keep the decreased and increased keys from overflowing)
+	property := func(left, right []int32, seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		a, b := newHeap(), newHeap()
+
+		model := map[N]int{}
+		var nodes []N
+		for _, v := range left {
+			n := a.InsertNode(int(v))
+			model[n] = int(v)
+			nodes = append(nodes, n)
+		}
+		for _, v := range right {
+			n := b.InsertNode(int(v))
+			model[n] = int(v)
+			nodes = append(nodes, n)
+		}
+
+		a.Meld(b)
+		if b.Len() != 0 || a.Len() != len(model) {
+			t.Errorf("Meld() left lengths %v and %v, want %v and 0", a.Len(), b.Len(), len(model))
+			return false
+		}
+
+		for _, n := range nodes {
+			if !a.Contains(n) || b.Contains(n) {
+				t.Errorf("Meld() did not move every node")
+				return false
+			}
+
+			switch r.Intn(4) {
+			case 0:
+				if !a.Remove(n) || a.Remove(n) || a.Contains(n) {
+					t.Errorf("Remove() did not remove the node exactly once")
+					return false
+				}
+				delete(model, n)
+			case 1:
+				v := model[n] - r.Intn(1000)
+				if !a.DecreaseKey(n, v) {
+					t.Errorf("DecreaseKey() = false, want true")
+					return false
+				}
+				model[n] = v
+			case 2:
+				if a.DecreaseKey(n, model[n]+1) {
+					t.Errorf("DecreaseKey() to a larger key = true, want false")
+					return false
+				}
+			}
+		}
+
+		var want []int
+		for _, v := range model {
+			want = append(want, v)
+		}
+		sort.Ints(want)
+
+		got := a.Drain()
+		if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Errorf("got %v want %v", got, want)
+			return false
+		}
+		return true
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMeldedHandlesAreNotUsableFromTheEmptiedHeap(t *testing.T) {
+	t.Parallel()
+	a, b := NewPairing(lessInt), NewPairing(lessInt)
+	n := b.InsertNode(1)
+	a.Meld(b)
+
+	if b.Remove(n) || b.DecreaseKey(n, 0) {
+		t.Errorf("a melded node must only be usable through the heap it was melded into")
+	}
+
+	// New nodes in the emptied heap belong to it alone.
+	m := b.InsertNode(2)
+	if a.Contains(m) || !b.Contains(m) {
+		t.Errorf("a node inserted after Meld belongs to the wrong heap")
+	}
+}
@@ -0,0 +1,109 @@
+package heap
+
+// Handle refers to an element stored in an IndexedDHeap.
+// It stays valid until the element is extracted or removed.
+type Handle[V, P any] struct {
+	value    V
+	priority P
+	index    int
+	owner    *IndexedDHeap[V, P]
+}
+
+// Value returns the value the handle refers to.
+func (h *Handle[V, P]) Value() V {
+	return h.value
+}
+
+// Priority returns the current priority of the value.
+func (h *Handle[V, P]) Priority() P {
+	return h.priority
+}
+
+// IndexedDHeap is a d-ary heap of values ordered by a separate priority.
+// Every inserted value gets a Handle, which locates it in the heap so its priority
+// can be changed, or the value removed, in O(log_d n).
+type IndexedDHeap[V, P any] struct {
+	heap *DHeap[*Handle[V, P]]
+}
+
+// NewIndexed returns an empty indexed heap with the given degree, ordered by less on priorities.
+func NewIndexed[V, P any](degree int, less func(a, b P) bool) *IndexedDHeap[V, P] {
+	h := New(degree, func(a, b *Handle[V, P]) bool {
+		return less(a.priority, b.priority)
+	})
+	h.moved = func(handle *Handle[V, P], i int) {
+		handle.index = i
+	}
+
+	return &IndexedDHeap[V, P]{heap: h}
+}
+
+// Len returns the number of values in the heap.
+func (q *IndexedDHeap[V, P]) Len() int {
+	return q.heap.Len()
+}
+
+// Insert adds value with the given priority and returns its handle.
+func (q *IndexedDHeap[V, P]) Insert(value V, priority P) *Handle[V, P] {
+	handle := &Handle[V, P]{
+		value:    value,
+		priority: priority,
+		owner:    q,
+	}
+	q.heap.Insert(handle)
+	return handle
+}
+
+// Peek returns the handle at the top of the heap without removing it.
+// False is returned if the heap is empty.
+func (q *IndexedDHeap[V, P]) Peek() (*Handle[V, P], bool) {
+	return q.heap.Peek()
+}
+
+// Extract removes the value at the top of the heap and returns it with its priority.
+// False is returned if the heap is empty.
+func (q *IndexedDHeap[V, P]) Extract() (value V, priority P, ok bool) {
+	handle, ok := q.heap.Extract()
+	if !ok {
+		return value, priority, false
+	}
+
+	q.release(handle)
+	return handle.value, handle.priority, true
+}
+
+// Contains reports whether the handle refers to a value that is still in this heap.
+func (q *IndexedDHeap[V, P]) Contains(handle *Handle[V, P]) bool {
+	return handle != nil && handle.owner == q
+}
+
+// Update changes the priority of the handle's value, moving it up or down as needed.
+// This covers both DecreaseKey and IncreaseKey.
+// False is returned if the handle is not in the heap.
+func (q *IndexedDHeap[V, P]) Update(handle *Handle[V, P], priority P) bool {
+	if !q.Contains(handle) {
+		return false
+	}
+
+	handle.priority = priority
+	q.heap.fix(handle.index)
+	return true
+}
+
+// Remove deletes the handle's value from the heap.
+// False is returned if the handle is not in the heap.
+func (q *IndexedDHeap[V, P]) Remove(handle *Handle[V, P]) bool {
+	if !q.Contains(handle) {
+		return false
+	}
+
+	q.heap.removeAt(handle.index)
+	q.release(handle)
+	return true
+}
+
+// release detaches a handle that has left the heap, so it cannot be used to modify it again.
+func (q *IndexedDHeap[V, P]) release(handle *Handle[V, P]) {
+	handle.owner = nil
+	handle.index = -1
+}
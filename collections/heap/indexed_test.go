@@ -0,0 +1,166 @@
+package heap
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"testing/quick"
+)
+
+func lessInt(a, b int) bool {
+	return a < b
+}
+
+func ExampleIndexedDHeap() {
+	// Dijkstra's shortest paths on a small weighted graph.
+	graph := map[string]map[string]int{
+		"a": {"b": 7, "c": 2},
+		"b": {"d": 1},
+		"c": {"b": 3, "d": 8},
+		"d": {},
+	}
+
+	dist := map[string]int{"a": 0}
+	handles := map[string]*Handle[string, int]{}
+	q := NewIndexed[string](4, lessInt)
+	handles["a"] = q.Insert("a", 0)
+
+	for q.Len() > 0 {
+		node, d, _ := q.Extract()
+		for next, weight := range graph[node] {
+			best, seen := dist[next]
+			if seen && best <= d+weight {
+				continue
+			}
+			dist[next] = d + weight
+			if h, ok := handles[next]; ok && q.Contains(h) {
+				q.Update(h, d+weight)
+			} else {
+				handles[next] = q.Insert(next, d+weight)
+			}
+		}
+	}
+
+	fmt.Println(dist["a"], dist["b"], dist["c"], dist["d"])
+	// Output:
+	// 0 5 2 6
+}
+
+func TestIndexedDHeap_Update(t *testing.T) {
+	t.Parallel()
+	q := NewIndexed[string](3, lessInt)
+	q.Insert("a", 10)
+	b := q.Insert("b", 20)
+	q.Insert("c", 30)
+
+	// Decrease the key of b so that it moves to the top.
+	if !q.Update(b, 5) {
+		t.Fatalf("Update() = false, want true")
+	}
+	top, _ := q.Peek()
+	if top.Value() != "b" || top.Priority() != 5 {
+		t.Errorf("Peek() = %v, %v, want %v, %v", top.Value(), top.Priority(), "b", 5)
+	}
+
+	// Increase the key of b so that it moves to the bottom.
+	q.Update(b, 40)
+	for _, want := range []string{"a", "c", "b"} {
+		got, _, _ := q.Extract()
+		if got != want {
+			t.Errorf("Extract() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestIndexedDHeap_Remove(t *testing.T) {
+	t.Parallel()
+	q := NewIndexed[string](2, lessInt)
+	a := q.Insert("a", 1)
+	b := q.Insert("b", 2)
+	q.Insert("c", 3)
+
+	if !q.Remove(b) {
+		t.Errorf("Remove() = false, want true")
+	}
+	if q.Contains(b) {
+		t.Errorf("Contains() = true after Remove, want false")
+	}
+	if q.Remove(b) {
+		t.Errorf("Remove() of a removed handle = true, want false")
+	}
+	if q.Update(b, 0) {
+		t.Errorf("Update() of a removed handle = true, want false")
+	}
+	if q.Len() != 2 {
+		t.Errorf("Len() = %v, want %v", q.Len(), 2)
+	}
+
+	q.Extract()
+	if q.Contains(a) {
+		t.Errorf("Contains() = true after Extract, want false")
+	}
+}
+
+func TestIndexedDHeap_ForeignHandle(t *testing.T) {
+	t.Parallel()
+	q1 := NewIndexed[string](2, lessInt)
+	q2 := NewIndexed[string](2, lessInt)
+	h := q1.Insert("a", 1)
+
+	if q2.Contains(h) || q2.Remove(h) || q2.Update(h, 0) {
+		t.Errorf("a handle from another heap must not be usable")
+	}
+	if q2.Contains(nil) {
+		t.Errorf("Contains(nil) = true, want false")
+	}
+}
+
+// Property: after random updates and removals the heap still extracts the remaining values in priority order
+func TestIndexedDHeap_RandomOperations(t *testing.T) {
+	t.Parallel()
+	f := func(priorities []int, seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		q := NewIndexed[int](r.Intn(6)+2, lessInt)
+
+		handles := make([]*Handle[int, int], len(priorities))
+		for i, p := range priorities {
+			handles[i] = q.Insert(i, p)
+		}
+
+		want := map[int]int{}
+		for i, h := range handles {
+			switch r.Intn(3) {
+			case 0:
+				q.Remove(h)
+			case 1:
+				p := r.Int()
+				q.Update(h, p)
+				want[i] = p
+			default:
+				want[i] = priorities[i]
+			}
+		}
+
+		var expected []int
+		for _, p := range want {
+			expected = append(expected, p)
+		}
+		sort.Ints(expected)
+
+		if q.Len() != len(expected) {
+			return false
+		}
+		for _, p := range expected {
+			v, got, ok := q.Extract()
+			if !ok || got != p || want[v] != p {
+				return false
+			}
+		}
+		return q.Len() == 0
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
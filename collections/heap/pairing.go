@@ -0,0 +1,205 @@
+package heap
+
+// PairingHeap is a heap-ordered multiway tree with O(1) Insert, Meld and Peek,
+// and amortized O(log n) Extract, DecreaseKey and Remove.
+type PairingHeap[T any] struct {
+	root  *PairingNode[T]
+	size  int
+	less  func(a, b T) bool
+	owner *owner
+}
+
+// PairingNode is a handle to a value stored in a PairingHeap.
+type PairingNode[T any] struct {
+	value T
+	// child is the leftmost child, next is the right sibling,
+	// and prev is the left sibling or, for a leftmost child, the parent.
+	child, next, prev *PairingNode[T]
+	owner             *owner
+}
+
+// Value returns the value stored in the node.
+func (n *PairingNode[T]) Value() T {
+	return n.value
+}
+
+// NewPairing returns an empty pairing heap ordered by less.
+func NewPairing[T any](less func(a, b T) bool) *PairingHeap[T] {
+	return &PairingHeap[T]{
+		less:  less,
+		owner: &owner{},
+	}
+}
+
+// Len returns the number of elements in the heap.
+func (h *PairingHeap[T]) Len() int {
+	return h.size
+}
+
+// Insert adds value to the heap.
+func (h *PairingHeap[T]) Insert(value T) {
+	h.InsertNode(value)
+}
+
+// InsertNode adds value to the heap and returns a handle for DecreaseKey and Remove.
+func (h *PairingHeap[T]) InsertNode(value T) *PairingNode[T] {
+	n := &PairingNode[T]{value: value, owner: h.owner}
+	h.root = h.link(h.root, n)
+	h.size++
+	return n
+}
+
+// Peek returns the next element to be extracted without removing it.
+// False is returned if the heap is empty.
+func (h *PairingHeap[T]) Peek() (t T, ok bool) {
+	if h.root == nil {
+		return t, false
+	}
+
+	return h.root.value, true
+}
+
+// Extract removes and returns the element at the top of the heap.
+// False is returned if the heap is empty.
+func (h *PairingHeap[T]) Extract() (t T, ok bool) {
+	if h.root == nil {
+		return t, false
+	}
+
+	root := h.root
+	h.root = h.mergePairs(root.child)
+	h.release(root)
+	return root.value, true
+}
+
+// Drain removes every element from the heap and returns them in extraction order.
+func (h *PairingHeap[T]) Drain() []T {
+	result := make([]T, 0, h.size)
+	for h.root != nil {
+		v, _ := h.Extract()
+		result = append(result, v)
+	}
+	return result
+}
+
+// Contains reports whether the node is still stored in this heap.
+func (h *PairingHeap[T]) Contains(n *PairingNode[T]) bool {
+	return n != nil && n.owner != nil && n.owner.resolve() == h.owner
+}
+
+// Meld moves every element of other into h in O(1), leaving other empty.
+// Handles from other remain valid and now refer to elements of h.
+func (h *PairingHeap[T]) Meld(other *PairingHeap[T]) {
+	if other == h || other.root == nil {
+		return
+	}
+
+	h.root = h.link(h.root, other.root)
+	h.size += other.size
+
+	other.root = nil
+	other.size = 0
+	other.owner = h.owner.absorb(other.owner)
+}
+
+// DecreaseKey replaces the node's value with one that is extracted no later than the current one.
+// False is returned if the node is not in the heap, or if value would be extracted after the current value.
+func (h *PairingHeap[T]) DecreaseKey(n *PairingNode[T], value T) bool {
+	if !h.Contains(n) || h.less(n.value, value) {
+		return false
+	}
+
+	n.value = value
+	if n != h.root {
+		h.cut(n)
+		h.root = h.link(h.root, n)
+	}
+	return true
+}
+
+// Remove deletes the node from the heap.
+// False is returned if the node is not in the heap.
+func (h *PairingHeap[T]) Remove(n *PairingNode[T]) bool {
+	if !h.Contains(n) {
+		return false
+	}
+
+	if n == h.root {
+		h.Extract()
+		return true
+	}
+
+	h.cut(n)
+	h.root = h.link(h.root, h.mergePairs(n.child))
+	h.release(n)
+	return true
+}
+
+// link makes the root that is extracted later the leftmost child of the other, and returns the new root.
+func (h *PairingHeap[T]) link(a, b *PairingNode[T]) *PairingNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.less(b.value, a.value) {
+		a, b = b, a
+	}
+
+	b.prev = a
+	b.next = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	a.next, a.prev = nil, nil
+
+	return a
+}
+
+// cut detaches the subtree rooted at n from its parent and siblings.
+func (h *PairingHeap[T]) cut(n *PairingNode[T]) {
+	if n.prev.child == n {
+		n.prev.child = n.next
+	} else {
+		n.prev.next = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	}
+	n.next, n.prev = nil, nil
+}
+
+// mergePairs combines a list of siblings into a single tree using the two-pass method.
+func (h *PairingHeap[T]) mergePairs(first *PairingNode[T]) *PairingNode[T] {
+	// First pass: link siblings in pairs from left to right.
+	var pairs []*PairingNode[T]
+	for first != nil {
+		a, b := first, first.next
+		if b == nil {
+			first = nil
+		} else {
+			first = b.next
+		}
+		a.next, a.prev = nil, nil
+		if b != nil {
+			b.next, b.prev = nil, nil
+		}
+		pairs = append(pairs, h.link(a, b))
+	}
+
+	// Second pass: link the pairs from right to left.
+	var root *PairingNode[T]
+	for i := len(pairs) - 1; i >= 0; i-- {
+		root = h.link(pairs[i], root)
+	}
+	return root
+}
+
+// release detaches a node that has left the heap.
+func (h *PairingHeap[T]) release(n *PairingNode[T]) {
+	n.child, n.next, n.prev = nil, nil, nil
+	n.owner = nil
+	h.size--
+}
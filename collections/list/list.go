@@ -2,15 +2,20 @@
 
 package list
 
+import "iter"
+
 // List implements a doubly-linked list.
 type List[V any] struct {
 	Front, Back *Node[V]
+	len         int
 }
 
 // Node is a node in the linked list.
 type Node[V any] struct {
 	Value      V
 	Prev, Next *Node[V]
+	// list is the list the node currently belongs to, or nil once it is removed.
+	list *List[V]
 }
 
 // New returns an empty linked list.
@@ -18,46 +23,201 @@ func New[V any]() *List[V] {
 	return &List[V]{}
 }
 
-// PushBack adds 'v' to the end of the list.
-func (l *List[V]) PushBack(v V) {
-	l.PushBackNode(&Node[V]{
+// Len returns the number of nodes in the list.
+func (l *List[V]) Len() int {
+	return l.len
+}
+
+// PushBack adds 'v' to the end of the list and returns its node.
+func (l *List[V]) PushBack(v V) *Node[V] {
+	n := &Node[V]{
 		Value: v,
-	})
+	}
+	l.PushBackNode(n)
+	return n
 }
 
-// PushFront adds 'v' to the beginning of the list.
-func (l *List[V]) PushFront(v V) {
-	l.PushFrontNode(&Node[V]{
+// PushFront adds 'v' to the beginning of the list and returns its node.
+func (l *List[V]) PushFront(v V) *Node[V] {
+	n := &Node[V]{
 		Value: v,
-	})
+	}
+	l.PushFrontNode(n)
+	return n
 }
 
 // PushBackNode adds the node 'n' to the back of the list.
+// It panics if 'n' already belongs to a list.
 func (l *List[V]) PushBackNode(n *Node[V]) {
-	n.Next = nil
-	n.Prev = l.Back
+	l.insert(n, l.Back, nil)
+}
+
+// PushFrontNode adds the node 'n' to the front of the list.
+// It panics if 'n' already belongs to a list.
+func (l *List[V]) PushFrontNode(n *Node[V]) {
+	l.insert(n, nil, l.Front)
+}
+
+// InsertBefore adds 'v' immediately before 'mark' and returns its node.
+// Nil is returned and the list is left unchanged if 'mark' does not belong to the list.
+func (l *List[V]) InsertBefore(v V, mark *Node[V]) *Node[V] {
+	if mark.list != l {
+		return nil
+	}
+
+	n := &Node[V]{
+		Value: v,
+	}
+	l.insert(n, mark.Prev, mark)
+	return n
+}
+
+// InsertAfter adds 'v' immediately after 'mark' and returns its node.
+// Nil is returned and the list is left unchanged if 'mark' does not belong to the list.
+func (l *List[V]) InsertAfter(v V, mark *Node[V]) *Node[V] {
+	if mark.list != l {
+		return nil
+	}
+
+	n := &Node[V]{
+		Value: v,
+	}
+	l.insert(n, mark, mark.Next)
+	return n
+}
+
+// PushBackList moves every node of 'other' to the end of the list, leaving 'other' empty.
+// The nodes keep their identity, so references to them stay valid.
+// Splicing a list onto itself does nothing.
+func (l *List[V]) PushBackList(other *List[V]) {
+	if other == l || other.Front == nil {
+		return
+	}
+
+	for n := other.Front; n != nil; n = n.Next {
+		n.list = l
+	}
+
 	if l.Back != nil {
-		l.Back.Next = n
+		l.Back.Next = other.Front
+		other.Front.Prev = l.Back
 	} else {
-		l.Front = n
+		l.Front = other.Front
 	}
-	l.Back = n
+	l.Back = other.Back
+	l.len += other.len
+
+	other.Front, other.Back, other.len = nil, nil, 0
 }
 
-// PushFrontNode adds the node 'n' to the front of the list.
-func (l *List[V]) PushFrontNode(n *Node[V]) {
-	n.Next = l.Front
-	n.Prev = nil
-	if l.Front != nil {
-		l.Front.Prev = n
+// Remove removes the node 'n' from the list and clears its links.
+// False is returned if 'n' does not belong to the list, including when it was already removed.
+func (l *List[V]) Remove(n *Node[V]) bool {
+	if n.list != l {
+		return false
+	}
+
+	l.unlink(n)
+	n.Prev, n.Next, n.list = nil, nil, nil
+	return true
+}
+
+// MoveToFront moves the node 'n' to the front of the list.
+// The list is left unchanged if 'n' does not belong to it.
+func (l *List[V]) MoveToFront(n *Node[V]) {
+	if n.list != l || l.Front == n {
+		return
+	}
+
+	l.unlink(n)
+	n.list = nil
+	l.insert(n, nil, l.Front)
+}
+
+// MoveToBack moves the node 'n' to the back of the list.
+// The list is left unchanged if 'n' does not belong to it.
+func (l *List[V]) MoveToBack(n *Node[V]) {
+	if n.list != l || l.Back == n {
+		return
+	}
+
+	l.unlink(n)
+	n.list = nil
+	l.insert(n, l.Back, nil)
+}
+
+// Find returns the first node from the front whose value satisfies 'match', or nil if there is none.
+func (l *List[V]) Find(match func(v V) bool) *Node[V] {
+	for n := l.Front; n != nil; n = n.Next {
+		if match(n.Value) {
+			return n
+		}
+	}
+	return nil
+}
+
+// All returns an iterator over the values in the list from front to back.
+func (l *List[V]) All() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for n := range l.Nodes() {
+			if !yield(n.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the values in the list from back to front.
+func (l *List[V]) Backward() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for n := l.Back; n != nil; {
+			prev := n.Prev
+			if !yield(n.Value) {
+				return
+			}
+			n = prev
+		}
+	}
+}
+
+// Nodes returns an iterator over the nodes in the list from front to back.
+// The node being visited may be removed or moved during iteration.
+func (l *List[V]) Nodes() iter.Seq[*Node[V]] {
+	return func(yield func(*Node[V]) bool) {
+		for n := l.Front; n != nil; {
+			next := n.Next
+			if !yield(n) {
+				return
+			}
+			n = next
+		}
+	}
+}
+
+// insert links the detached node 'n' between 'prev' and 'next', either of which may be nil at the ends.
+func (l *List[V]) insert(n, prev, next *Node[V]) {
+	if n.list != nil {
+		panic("list: node already belongs to a list")
+	}
+
+	n.Prev = prev
+	n.Next = next
+	if prev != nil {
+		prev.Next = n
+	} else {
+		l.Front = n
+	}
+	if next != nil {
+		next.Prev = n
 	} else {
 		l.Back = n
 	}
-	l.Front = n
+	n.list = l
+	l.len++
 }
 
-// Remove removes the node 'n' from the list.
-func (l *List[V]) Remove(n *Node[V]) {
+// unlink detaches 'n' from its neighbours without clearing its own links.
+func (l *List[V]) unlink(n *Node[V]) {
 	if n.Next != nil {
 		n.Next.Prev = n.Prev
 	} else {
@@ -68,22 +228,5 @@ func (l *List[V]) Remove(n *Node[V]) {
 	} else {
 		l.Front = n.Next
 	}
-}
-
-// Each calls 'fn' on every element from this node onward in the list.
-func (n *Node[V]) Each(fn func(val V)) {
-	node := n
-	for node != nil {
-		fn(node.Value)
-		node = node.Next
-	}
-}
-
-// EachReverse calls 'fn' on every element from this node backward in the list.
-func (n *Node[V]) EachReverse(fn func(val V)) {
-	node := n
-	for node != nil {
-		fn(node.Value)
-		node = node.Prev
-	}
+	l.len--
 }
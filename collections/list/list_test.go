@@ -5,7 +5,10 @@ package list
 import (
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"math/rand"
+	"slices"
 	"testing"
+	"testing/quick"
 )
 
 func Example() {
@@ -15,9 +18,9 @@ func Example() {
 	l.PushBack(2)
 	l.PushBack(3)
 
-	l.Front.Each(func(i int) {
+	for i := range l.All() {
 		fmt.Println(i)
-	})
+	}
 	// Output:
 	// 0
 	// 1
@@ -34,4 +37,185 @@ func TestAddRemove(t *testing.T) {
 
 	require.Equal(t, 0, l.Front.Value)
 	require.Equal(t, 9, l.Back.Value)
+	require.Equal(t, 10, l.Len())
+}
+
+func TestRemoveTwice(t *testing.T) {
+	l := New[int]()
+	l.PushBack(0)
+	n := l.PushBack(1)
+	l.PushBack(2)
+
+	require.True(t, l.Remove(n))
+	require.Nil(t, n.Prev)
+	require.Nil(t, n.Next)
+	require.False(t, l.Remove(n))
+	require.Equal(t, []int{0, 2}, slices.Collect(l.All()))
+	require.Equal(t, 2, l.Len())
+}
+
+func TestForeignNodes(t *testing.T) {
+	l, other := New[int](), New[int]()
+	l.PushBack(0)
+	foreign := other.PushBack(1)
+
+	require.False(t, l.Remove(foreign))
+	require.Nil(t, l.InsertBefore(2, foreign))
+	require.Nil(t, l.InsertAfter(2, foreign))
+	l.MoveToFront(foreign)
+	l.MoveToBack(foreign)
+
+	require.Equal(t, []int{0}, slices.Collect(l.All()))
+	require.Equal(t, []int{1}, slices.Collect(other.All()))
+	require.Panics(t, func() { l.PushBackNode(foreign) })
+}
+
+func TestInsertAndMove(t *testing.T) {
+	l := New[int]()
+	two := l.PushBack(2)
+	l.InsertBefore(1, two)
+	four := l.InsertAfter(4, two)
+	l.InsertBefore(3, four)
+	zero := l.PushBack(0)
+
+	l.MoveToFront(zero)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, slices.Collect(l.All()))
+
+	l.MoveToBack(two)
+	require.Equal(t, []int{0, 1, 3, 4, 2}, slices.Collect(l.All()))
+	require.Equal(t, []int{2, 4, 3, 1, 0}, slices.Collect(l.Backward()))
+}
+
+func TestPushBackList(t *testing.T) {
+	l, other := New[int](), New[int]()
+	l.PushBack(0)
+	one := other.PushBack(1)
+	other.PushBack(2)
+
+	l.PushBackList(other)
+	l.PushBackList(l)
+
+	require.Equal(t, []int{0, 1, 2}, slices.Collect(l.All()))
+	require.Equal(t, 3, l.Len())
+	require.Equal(t, 0, other.Len())
+	require.Nil(t, other.Front)
+	require.Nil(t, other.Back)
+
+	// Spliced nodes now belong to l.
+	require.True(t, l.Remove(one))
+	require.False(t, other.Remove(one))
+}
+
+func TestFind(t *testing.T) {
+	l := New[string]()
+	l.PushBack("a")
+	b := l.PushBack("b")
+	l.PushBack("b")
+
+	require.Same(t, b, l.Find(func(s string) bool { return s == "b" }))
+	require.Nil(t, l.Find(func(s string) bool { return s == "c" }))
+}
+
+func TestRemoveWhileIterating(t *testing.T) {
+	l := New[int]()
+	for i := 0; i < 10; i++ {
+		l.PushBack(i)
+	}
+
+	for n := range l.Nodes() {
+		if n.Value%2 == 0 {
+			l.Remove(n)
+		}
+	}
+	require.Equal(t, []int{1, 3, 5, 7, 9}, slices.Collect(l.All()))
+}
+
+// Property: every sequence of operations leaves the list matching a slice model,
+// with consistent links in both directions
+func TestMatchesModel(t *testing.T) {
+	f := func(ops []uint8, seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		l, other := New[int](), New[int]()
+		var nodes []*Node[int]
+		var model []int
+
+		// pick returns a random node from the list along with its position in the model.
+		pick := func() (*Node[int], int) {
+			i := r.Intn(len(nodes))
+			return nodes[i], i
+		}
+
+		for i, op := range ops {
+			switch op % 9 {
+			case 0:
+				nodes = append(nodes, l.PushBack(i))
+				model = append(model, i)
+			case 1:
+				nodes = slices.Insert(nodes, 0, l.PushFront(i))
+				model = slices.Insert(model, 0, i)
+			case 2:
+				if len(nodes) > 0 {
+					n, at := pick()
+					nodes = slices.Insert(nodes, at, l.InsertBefore(i, n))
+					model = slices.Insert(model, at, i)
+				}
+			case 3:
+				if len(nodes) > 0 {
+					n, at := pick()
+					nodes = slices.Insert(nodes, at+1, l.InsertAfter(i, n))
+					model = slices.Insert(model, at+1, i)
+				}
+			case 4:
+				if len(nodes) > 0 {
+					n, at := pick()
+					if !l.Remove(n) || l.Remove(n) {
+						return false
+					}
+					nodes = slices.Delete(nodes, at, at+1)
+					model = slices.Delete(model, at, at+1)
+				}
+			case 5:
+				if len(nodes) > 0 {
+					n, at := pick()
+					v := model[at]
+					l.MoveToFront(n)
+					nodes = append([]*Node[int]{n}, slices.Delete(nodes, at, at+1)...)
+					model = append([]int{v}, slices.Delete(model, at, at+1)...)
+				}
+			case 6:
+				if len(nodes) > 0 {
+					n, at := pick()
+					v := model[at]
+					l.MoveToBack(n)
+					nodes = append(slices.Delete(nodes, at, at+1), n)
+					model = append(slices.Delete(model, at, at+1), v)
+				}
+			case 7:
+				nodes = append(nodes, other.PushBack(i))
+				model = append(model, i)
+				l.PushBackList(other)
+			case 8:
+				if len(nodes) > 0 {
+					_, at := pick()
+					want := model[at]
+					n := l.Find(func(v int) bool { return v == want })
+					if n != nodes[at] {
+						return false
+					}
+				}
+			}
+		}
+
+		if l.Len() != len(model) || other.Len() != 0 {
+			return false
+		}
+		if !slices.Equal(slices.Collect(l.All()), model) {
+			return false
+		}
+		backward := slices.Collect(l.Backward())
+		slices.Reverse(backward)
+		return slices.Equal(backward, model)
+	}
+
+	require.NoError(t, quick.Check(f, &quick.Config{MaxCount: 500}))
 }
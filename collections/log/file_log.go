@@ -109,6 +109,26 @@ func (fl *FileLog) Read(offset uint64) (record []byte, nextOffset uint64, err er
 	return record, nextOffset, nil
 }
 
+// Sync commits the appended records to stable storage, so they survive a crash of the machine.
+func (fl *FileLog) Sync() error {
+	return fl.file.Sync()
+}
+
+// SyncDir commits the entries of a directory to stable storage, so the files created, renamed or removed in it
+// survive a crash of the machine. A new log is only durable once its directory has been synced.
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
 func (fl *FileLog) Close() error {
 	if fl.file != nil {
 		err := fl.file.Close()
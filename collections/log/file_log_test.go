@@ -3,6 +3,7 @@ package log
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -115,6 +116,38 @@ func TestReadAfterCloseReturnsError(t *testing.T) {
 	}
 }
 
+func TestSyncAfterCloseReturnsError(t *testing.T) {
+	t.Parallel()
+	log, cleanup := CreateFileLog(t)
+	defer cleanup()
+
+	if _, err := log.Append([]byte("hello, world")); err != nil {
+		t.Fatalf("cannot append: %v", err)
+	}
+	if err := log.Sync(); err != nil {
+		t.Errorf("cannot sync log: %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("cannot close log: %v", err)
+	}
+
+	if err := log.Sync(); err == nil {
+		t.Errorf("Sync after Close did not return an error")
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	if err := SyncDir(dir); err != nil {
+		t.Errorf("cannot sync directory: %v", err)
+	}
+	if err := SyncDir(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("SyncDir of a missing directory did not return an error")
+	}
+}
+
 func TestReadWithInvalidOffsetReturnsError(t *testing.T) {
 	t.Parallel()
 	log, cleanup := CreateFileLog(t)
@@ -0,0 +1,290 @@
+package persistent
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"practice/collections"
+)
+
+var seed = maphash.MakeSeed()
+
+// Map is an immutable hash map stored in a hash array mapped trie (HAMT).
+// Each level of the trie consumes five bits of the key's hash, and nodes only store the
+// children that exist, indexed through a bitmap. Set and Delete copy the O(log32 n) nodes on
+// the path to the key, and share the rest with the version they were derived from.
+// The zero value is an empty map.
+type Map[K comparable, V any] struct {
+	root *mapNode[K, V]
+	size int
+	// hash overrides the hash function, so tests can force collisions.
+	hash func(K) uint64
+}
+
+// mapNode is either a bitmap-indexed node or, once every hash bit is used up,
+// a collision node holding the pairs whose keys share the same hash.
+type mapNode[K comparable, V any] struct {
+	bitmap     uint32
+	entries    []mapEntry[K, V]
+	collisions []collections.Pair[K, V]
+	hash       uint64
+}
+
+// mapEntry is either a key-value pair or, if child is set, a subtrie.
+type mapEntry[K comparable, V any] struct {
+	hash  uint64
+	key   K
+	value V
+	child *mapNode[K, V]
+}
+
+// MapOf returns a map holding the given pairs. Later pairs win over earlier ones with the same key.
+func MapOf[K comparable, V any](pairs ...collections.Pair[K, V]) Map[K, V] {
+	var m Map[K, V]
+	for _, p := range pairs {
+		m = m.Set(p.Key, p.Value)
+	}
+	return m
+}
+
+// Len returns the number of keys in the map.
+func (m Map[K, V]) Len() int {
+	return m.size
+}
+
+// Get returns the value stored under key.
+// False is returned if the key is not in the map.
+func (m Map[K, V]) Get(key K) (v V, ok bool) {
+	if m.root == nil {
+		return v, false
+	}
+	return m.root.get(m.hashOf(key), 0, key)
+}
+
+// Set returns a map in which key maps to value.
+func (m Map[K, V]) Set(key K, value V) Map[K, V] {
+	entry := mapEntry[K, V]{hash: m.hashOf(key), key: key, value: value}
+
+	root, added := m.root.set(0, entry)
+	m.root = root
+	if added {
+		m.size++
+	}
+	return m
+}
+
+// Delete returns a map without key. If the key is not in the map, m is returned unchanged.
+func (m Map[K, V]) Delete(key K) Map[K, V] {
+	if m.root == nil {
+		return m
+	}
+
+	root, removed := m.root.delete(m.hashOf(key), 0, key)
+	if removed {
+		m.root = root
+		m.size--
+	}
+	return m
+}
+
+// All returns an iterator over the key-value pairs, in no particular order.
+func (m Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.root.each(yield)
+	}
+}
+
+// Entries returns the key-value pairs, in no particular order.
+func (m Map[K, V]) Entries() []collections.Pair[K, V] {
+	result := make([]collections.Pair[K, V], 0, m.size)
+	for k, v := range m.All() {
+		result = append(result, collections.Pair[K, V]{Key: k, Value: v})
+	}
+	return result
+}
+
+func (m Map[K, V]) hashOf(key K) uint64 {
+	if m.hash != nil {
+		return m.hash(key)
+	}
+	return maphash.Comparable(seed, key)
+}
+
+// position returns the bit for hash at shift, and the index of its entry in n.
+func (n *mapNode[K, V]) position(hash uint64, shift uint) (uint32, int) {
+	bit := uint32(1) << ((hash >> shift) & mask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *mapNode[K, V]) get(hash uint64, shift uint, key K) (v V, ok bool) {
+	for ; shift < 64; shift += branchBits {
+		bit, i := n.position(hash, shift)
+		if n.bitmap&bit == 0 {
+			return v, false
+		}
+
+		e := n.entries[i]
+		if e.child == nil {
+			if e.key == key {
+				return e.value, true
+			}
+			return v, false
+		}
+		n = e.child
+	}
+
+	for _, p := range n.collisions {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return v, false
+}
+
+// set returns a copy of n holding entry, and whether the key was added rather than replaced.
+func (n *mapNode[K, V]) set(shift uint, entry mapEntry[K, V]) (*mapNode[K, V], bool) {
+	if n == nil {
+		n = &mapNode[K, V]{}
+	}
+
+	if shift >= 64 {
+		c := &mapNode[K, V]{hash: entry.hash, collisions: append([]collections.Pair[K, V](nil), n.collisions...)}
+		for i, p := range c.collisions {
+			if p.Key == entry.key {
+				c.collisions[i].Value = entry.value
+				return c, false
+			}
+		}
+		c.collisions = append(c.collisions, collections.Pair[K, V]{Key: entry.key, Value: entry.value})
+		return c, true
+	}
+
+	bit, i := n.position(entry.hash, shift)
+	if n.bitmap&bit == 0 {
+		c := &mapNode[K, V]{bitmap: n.bitmap | bit, entries: make([]mapEntry[K, V], 0, len(n.entries)+1)}
+		c.entries = append(c.entries, n.entries[:i]...)
+		c.entries = append(c.entries, entry)
+		c.entries = append(c.entries, n.entries[i:]...)
+		return c, true
+	}
+
+	c := n.clone()
+	e := n.entries[i]
+	switch {
+	case e.child != nil:
+		child, added := e.child.set(shift+branchBits, entry)
+		c.entries[i].child = child
+		return c, added
+	case e.key == entry.key:
+		c.entries[i] = entry
+		return c, false
+	default:
+		// Two keys share this slot, so push both down into a new subtrie.
+		child, _ := (*mapNode[K, V])(nil).set(shift+branchBits, e)
+		child, _ = child.set(shift+branchBits, entry)
+		c.entries[i] = mapEntry[K, V]{child: child}
+		return c, true
+	}
+}
+
+// delete returns a copy of n without key, or nil if nothing is left, and whether the key was found.
+func (n *mapNode[K, V]) delete(hash uint64, shift uint, key K) (*mapNode[K, V], bool) {
+	if shift >= 64 {
+		for i, p := range n.collisions {
+			if p.Key == key {
+				if len(n.collisions) == 1 {
+					return nil, true
+				}
+				c := &mapNode[K, V]{hash: n.hash, collisions: make([]collections.Pair[K, V], 0, len(n.collisions)-1)}
+				c.collisions = append(c.collisions, n.collisions[:i]...)
+				c.collisions = append(c.collisions, n.collisions[i+1:]...)
+				return c, true
+			}
+		}
+		return n, false
+	}
+
+	bit, i := n.position(hash, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	e := n.entries[i]
+	if e.child == nil {
+		if e.key != key {
+			return n, false
+		}
+		return n.without(bit, i), true
+	}
+
+	child, removed := e.child.delete(hash, shift+branchBits, key)
+	if !removed {
+		return n, false
+	}
+	if child == nil {
+		return n.without(bit, i), true
+	}
+
+	c := n.clone()
+	if lifted, ok := child.single(); ok {
+		// A subtrie holding a single pair is replaced by the pair itself.
+		c.entries[i] = lifted
+	} else {
+		c.entries[i].child = child
+	}
+	return c, true
+}
+
+// single returns the only pair in n, if n holds exactly one pair and no subtries.
+func (n *mapNode[K, V]) single() (mapEntry[K, V], bool) {
+	if len(n.collisions) == 1 {
+		p := n.collisions[0]
+		return mapEntry[K, V]{hash: n.hash, key: p.Key, value: p.Value}, true
+	}
+	if len(n.entries) == 1 && n.entries[0].child == nil {
+		return n.entries[0], true
+	}
+	return mapEntry[K, V]{}, false
+}
+
+// without returns a copy of n without the entry at index i, or nil if nothing is left.
+func (n *mapNode[K, V]) without(bit uint32, i int) *mapNode[K, V] {
+	if len(n.entries) == 1 {
+		return nil
+	}
+
+	c := &mapNode[K, V]{bitmap: n.bitmap &^ bit, entries: make([]mapEntry[K, V], 0, len(n.entries)-1)}
+	c.entries = append(c.entries, n.entries[:i]...)
+	c.entries = append(c.entries, n.entries[i+1:]...)
+	return c
+}
+
+func (n *mapNode[K, V]) clone() *mapNode[K, V] {
+	return &mapNode[K, V]{
+		bitmap:  n.bitmap,
+		entries: append([]mapEntry[K, V](nil), n.entries...),
+	}
+}
+
+// each yields every pair below n. False is returned if yield asked to stop.
+func (n *mapNode[K, V]) each(yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	for _, p := range n.collisions {
+		if !yield(p.Key, p.Value) {
+			return false
+		}
+	}
+	for _, e := range n.entries {
+		if e.child != nil {
+			if !e.child.each(yield) {
+				return false
+			}
+		} else if !yield(e.key, e.value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package persistent
+
+import (
+	"practice/collections"
+	"testing"
+	"testing/quick"
+)
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+	m := MapOf(
+		collections.Pair[string, int]{Key: "a", Value: 1},
+		collections.Pair[string, int]{Key: "b", Value: 2},
+	)
+
+	n := m.Set("c", 3).Set("a", 10).Delete("b")
+
+	if v, ok := m.Get("a"); !ok || v != 1 || m.Len() != 2 {
+		t.Errorf("original map changed: Get(a) = %v, %v with Len() %v", v, ok, m.Len())
+	}
+	if v, ok := n.Get("a"); !ok || v != 10 {
+		t.Errorf("Get(a) = %v, %v, want 10, true", v, ok)
+	}
+	if _, ok := n.Get("b"); ok {
+		t.Errorf("Get(b) after Delete returned ok")
+	}
+	if n.Len() != 2 {
+		t.Errorf("Len() = %v, want %v", n.Len(), 2)
+	}
+	if len(n.Entries()) != 2 {
+		t.Errorf("Entries() = %v, want 2 pairs", n.Entries())
+	}
+
+	var empty Map[string, int]
+	if empty.Delete("a").Len() != 0 {
+		t.Errorf("Delete() on an empty map changed its length")
+	}
+}
+
+func TestMapHashCollisions(t *testing.T) {
+	t.Parallel()
+	// Every key has the same hash, so all of them end up in one collision node.
+	m := Map[int, int]{hash: func(int) uint64 { return 42 }}
+	for i := 0; i < 10; i++ {
+		m = m.Set(i, i*i)
+	}
+
+	for i := 0; i < 10; i++ {
+		if v, ok := m.Get(i); !ok || v != i*i {
+			t.Errorf("Get(%v) = %v, %v, want %v, true", i, v, ok, i*i)
+		}
+	}
+
+	for i := 0; i < 9; i++ {
+		m = m.Delete(i)
+	}
+	if v, ok := m.Get(9); !ok || v != 81 || m.Len() != 1 {
+		t.Errorf("Get(9) = %v, %v with Len() %v, want 81, true with Len() 1", v, ok, m.Len())
+	}
+}
+
+// Property: setting and deleting keys never changes an earlier version of the map
+func TestMapVersionsNeverChange(t *testing.T) {
+	t.Parallel()
+	check := func(m Map[uint16, int], ops []int32) bool {
+		var versions []Map[uint16, int]
+		var snapshots []map[uint16]int
+
+		model := map[uint16]int{}
+		for _, op := range ops {
+			key := uint16(op) % 512
+			if op < 0 {
+				m = m.Delete(key)
+				delete(model, key)
+			} else {
+				m = m.Set(key, int(op))
+				model[key] = int(op)
+			}
+
+			snapshot := make(map[uint16]int, len(model))
+			for k, v := range model {
+				snapshot[k] = v
+			}
+			versions = append(versions, m)
+			snapshots = append(snapshots, snapshot)
+		}
+
+		for i, version := range versions {
+			if version.Len() != len(snapshots[i]) {
+				return false
+			}
+			for _, p := range version.Entries() {
+				if want, ok := snapshots[i][p.Key]; !ok || want != p.Value {
+					return false
+				}
+			}
+			for k, want := range snapshots[i] {
+				if got, ok := version.Get(k); !ok || got != want {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	withDefaultHash := func(ops []int32) bool {
+		return check(Map[uint16, int]{}, ops)
+	}
+	// A tiny hash space makes keys collide on every level, down to the collision nodes.
+	withWeakHash := func(ops []int32) bool {
+		return check(Map[uint16, int]{hash: func(k uint16) uint64 { return uint64(k % 7) }}, ops)
+	}
+
+	for _, f := range []func([]int32) bool{withDefaultHash, withWeakHash} {
+		if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+			t.Error(err)
+		}
+	}
+}
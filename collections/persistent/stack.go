@@ -0,0 +1,58 @@
+package persistent
+
+import "iter"
+
+// Stack is an immutable LIFO stack. Push and Pop return new versions that share every
+// element below the top with the version they were derived from.
+// The zero value is an empty stack.
+type Stack[T any] struct {
+	top  *stackNode[T]
+	size int
+}
+
+type stackNode[T any] struct {
+	value T
+	next  *stackNode[T]
+}
+
+// Len returns the number of elements in the stack.
+func (s Stack[T]) Len() int {
+	return s.size
+}
+
+// Push returns a stack with 'v' on top of s.
+func (s Stack[T]) Push(v T) Stack[T] {
+	return Stack[T]{
+		top:  &stackNode[T]{value: v, next: s.top},
+		size: s.size + 1,
+	}
+}
+
+// Peek returns the top element.
+// False is returned if the stack is empty.
+func (s Stack[T]) Peek() (t T, ok bool) {
+	if s.top == nil {
+		return t, false
+	}
+	return s.top.value, true
+}
+
+// Pop returns the top element and the stack below it.
+// False is returned if the stack is empty.
+func (s Stack[T]) Pop() (t T, rest Stack[T], ok bool) {
+	if s.top == nil {
+		return t, s, false
+	}
+	return s.top.value, Stack[T]{top: s.top.next, size: s.size - 1}, true
+}
+
+// All returns an iterator over the elements from the top of the stack down.
+func (s Stack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := s.top; n != nil; n = n.next {
+			if !yield(n.value) {
+				return
+			}
+		}
+	}
+}
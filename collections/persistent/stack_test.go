@@ -0,0 +1,81 @@
+package persistent
+
+import (
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func stackValues[T any](s Stack[T]) []T {
+	var values []T
+	for v := range s.All() {
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestStack(t *testing.T) {
+	t.Parallel()
+	var empty Stack[int]
+	one := empty.Push(1)
+	two := one.Push(2)
+
+	if v, ok := two.Peek(); !ok || v != 2 || two.Len() != 2 {
+		t.Errorf("Peek() = %v, %v with Len() %v, want 2, true with Len() 2", v, ok, two.Len())
+	}
+
+	v, rest, ok := two.Pop()
+	if !ok || v != 2 || rest.Len() != 1 {
+		t.Errorf("Pop() = %v, %v with Len() %v, want 2, true with Len() 1", v, ok, rest.Len())
+	}
+	if _, _, ok := empty.Pop(); ok {
+		t.Errorf("Pop() on an empty stack returned ok")
+	}
+
+	// Popping from two left it unchanged.
+	if !reflect.DeepEqual(stackValues(two), []int{2, 1}) {
+		t.Errorf("All() = %v, want %v", stackValues(two), []int{2, 1})
+	}
+}
+
+// Property: pushing and popping never changes an earlier version of the stack
+func TestStackVersionsNeverChange(t *testing.T) {
+	t.Parallel()
+	f := func(ops []int8) bool {
+		var versions []Stack[int8]
+		var snapshots [][]int8
+
+		var s Stack[int8]
+		var model []int8
+		for _, op := range ops {
+			if op < 0 {
+				_, s, _ = s.Pop()
+				if len(model) > 0 {
+					model = model[1:]
+				}
+			} else {
+				s = s.Push(op)
+				model = append([]int8{op}, model...)
+			}
+			versions = append(versions, s)
+			snapshots = append(snapshots, model)
+		}
+
+		for i, version := range versions {
+			got := stackValues(version)
+			if len(got) != len(snapshots[i]) || version.Len() != len(got) {
+				return false
+			}
+			for j := range got {
+				if got[j] != snapshots[i][j] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,183 @@
+package persistent
+
+import "iter"
+
+const (
+	// branchBits is the number of index bits consumed by each level of a Vector or Map.
+	branchBits = 5
+	width      = 1 << branchBits
+	mask       = width - 1
+)
+
+// Vector is an immutable indexed sequence stored in a 32-way trie.
+// Append, Set and Pop copy only the O(log32 n) nodes on the path to the element they change,
+// and share the rest of the trie with the version they were derived from.
+// The zero value is an empty vector.
+type Vector[T any] struct {
+	root *vectorNode[T]
+	size int
+	// shift is the number of index bits below the root.
+	shift uint
+}
+
+// vectorNode is a leaf holding values when it is at shift zero, and an inner node otherwise.
+type vectorNode[T any] struct {
+	children []*vectorNode[T]
+	values   []T
+}
+
+// VectorOf returns a vector holding the given values.
+func VectorOf[T any](values ...T) Vector[T] {
+	var v Vector[T]
+	for _, value := range values {
+		v = v.Append(value)
+	}
+	return v
+}
+
+// Len returns the number of elements in the vector.
+func (v Vector[T]) Len() int {
+	return v.size
+}
+
+// Get returns the i-th element. It panics if i is out of range.
+func (v Vector[T]) Get(i int) T {
+	v.check(i)
+
+	n := v.root
+	for shift := v.shift; shift > 0; shift -= branchBits {
+		n = n.children[(i>>shift)&mask]
+	}
+	return n.values[i&mask]
+}
+
+// Set returns a vector whose i-th element is 'value'. It panics if i is out of range.
+func (v Vector[T]) Set(i int, value T) Vector[T] {
+	v.check(i)
+
+	v.root = v.root.set(v.shift, i, value)
+	return v
+}
+
+// Append returns a vector with 'value' added at the end.
+func (v Vector[T]) Append(value T) Vector[T] {
+	if v.root != nil && v.size == 1<<(v.shift+branchBits) {
+		// The trie is full, so grow it by one level.
+		root := &vectorNode[T]{children: make([]*vectorNode[T], width)}
+		root.children[0] = v.root
+		v.root = root
+		v.shift += branchBits
+	}
+
+	v.root = v.root.set(v.shift, v.size, value)
+	v.size++
+	return v
+}
+
+// Pop returns the last element and the vector without it.
+// False is returned if the vector is empty.
+func (v Vector[T]) Pop() (t T, rest Vector[T], ok bool) {
+	if v.size == 0 {
+		return t, v, false
+	}
+
+	last := v.Get(v.size - 1)
+	if v.size == 1 {
+		return last, Vector[T]{}, true
+	}
+
+	v.root = v.root.pop(v.shift, v.size-1)
+	v.size--
+
+	// Drop levels that only have a single child.
+	for v.shift > 0 && v.root.children[1] == nil {
+		v.root = v.root.children[0]
+		v.shift -= branchBits
+	}
+
+	return last, v, true
+}
+
+// All returns an iterator over the indices and elements in order.
+func (v Vector[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		v.root.each(v.shift, 0, v.size, yield)
+	}
+}
+
+func (v Vector[T]) check(i int) {
+	if i < 0 || i >= v.size {
+		panic("persistent: index out of range")
+	}
+}
+
+// set returns a copy of n (or a new node if n is nil) with the i-th value replaced.
+func (n *vectorNode[T]) set(shift uint, i int, value T) *vectorNode[T] {
+	c := n.clone(shift)
+
+	if shift == 0 {
+		c.values[i&mask] = value
+	} else {
+		j := (i >> shift) & mask
+		c.children[j] = c.children[j].set(shift-branchBits, i, value)
+	}
+	return c
+}
+
+// pop returns a copy of n without its last value, i, or nil if nothing is left in n.
+func (n *vectorNode[T]) pop(shift uint, i int) *vectorNode[T] {
+	j := (i >> shift) & mask
+	if j == 0 && i&((1<<shift)-1) == 0 {
+		// i is the first value below n
+		return nil
+	}
+
+	c := n.clone(shift)
+	if shift == 0 {
+		var zero T
+		c.values[j] = zero
+	} else {
+		c.children[j] = c.children[j].pop(shift-branchBits, i)
+	}
+	return c
+}
+
+func (n *vectorNode[T]) clone(shift uint) *vectorNode[T] {
+	c := &vectorNode[T]{}
+	if shift == 0 {
+		c.values = make([]T, width)
+		if n != nil {
+			copy(c.values, n.values)
+		}
+	} else {
+		c.children = make([]*vectorNode[T], width)
+		if n != nil {
+			copy(c.children, n.children)
+		}
+	}
+	return c
+}
+
+// each yields the values below n, whose first index is base, stopping at size.
+// False is returned if yield asked to stop.
+func (n *vectorNode[T]) each(shift uint, base, size int, yield func(int, T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if shift == 0 {
+		for j := 0; j < width && base+j < size; j++ {
+			if !yield(base+j, n.values[j]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for j, child := range n.children {
+		if !child.each(shift-branchBits, base+j<<shift, size, yield) {
+			return false
+		}
+	}
+	return true
+}
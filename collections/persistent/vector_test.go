@@ -0,0 +1,115 @@
+package persistent
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+func TestVector(t *testing.T) {
+	t.Parallel()
+	var v Vector[int]
+	for i := 0; i < 5000; i++ {
+		v = v.Append(i)
+	}
+
+	if v.Len() != 5000 {
+		t.Errorf("Len() = %v, want %v", v.Len(), 5000)
+	}
+	for i := 0; i < 5000; i++ {
+		if v.Get(i) != i {
+			t.Fatalf("Get(%v) = %v, want %v", i, v.Get(i), i)
+		}
+	}
+
+	w := v.Set(1234, -1)
+	if v.Get(1234) != 1234 || w.Get(1234) != -1 {
+		t.Errorf("Set() changed the original vector")
+	}
+
+	for i := 4999; i >= 0; i-- {
+		var last int
+		last, v, _ = v.Pop()
+		if last != i {
+			t.Fatalf("Pop() = %v, want %v", last, i)
+		}
+	}
+	if _, _, ok := v.Pop(); ok {
+		t.Errorf("Pop() on an empty vector returned ok")
+	}
+}
+
+func TestVectorOf(t *testing.T) {
+	t.Parallel()
+	v := VectorOf("a", "b", "c")
+
+	var got []string
+	for i, s := range v.All() {
+		if v.Get(i) != s {
+			t.Errorf("All() yielded %v at %v, want %v", s, i, v.Get(i))
+		}
+		got = append(got, s)
+	}
+	if len(got) != 3 || got[0] != "a" || got[2] != "c" {
+		t.Errorf("got %v, want %v", got, []string{"a", "b", "c"})
+	}
+}
+
+func TestVectorGetOutOfRangePanics(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Get(1) on a vector of length 1 did not panic")
+		}
+	}()
+	VectorOf(1).Get(1)
+}
+
+// Property: appending, setting and popping never changes an earlier version of the vector
+func TestVectorVersionsNeverChange(t *testing.T) {
+	t.Parallel()
+	f := func(ops []uint16, seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		var versions []Vector[int]
+		var snapshots [][]int
+
+		var v Vector[int]
+		var model []int
+		for _, op := range ops {
+			switch {
+			case op%4 == 0 && len(model) > 0:
+				_, v, _ = v.Pop()
+				model = model[:len(model)-1]
+			case op%4 == 1 && len(model) > 0:
+				i := r.Intn(len(model))
+				v = v.Set(i, int(op))
+				model = append([]int(nil), model...)
+				model[i] = int(op)
+			default:
+				// Append runs of values so the trie grows several levels.
+				for j := 0; j < int(op%64); j++ {
+					v = v.Append(j)
+					model = append(model, j)
+				}
+			}
+			versions = append(versions, v)
+			snapshots = append(snapshots, append([]int(nil), model...))
+		}
+
+		for i, version := range versions {
+			if version.Len() != len(snapshots[i]) {
+				return false
+			}
+			for j, value := range version.All() {
+				if value != snapshots[i][j] || version.Get(j) != value {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
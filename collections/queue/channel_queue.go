@@ -1,19 +1,192 @@
 package queue
 
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
 
-// BoundedChannelQueue WARNING: has the potential to block if a channel send "ch <- x" is attempted on a full queue
-type BoundedChannelQueue struct {
-	queue chan interface{}
+// ErrFull is returned by Push on a full queue that rejects overflowing items.
+var ErrFull = errors.New("queue: full")
+
+// OverflowPolicy decides what a bounded queue does with an item pushed while it is full.
+type OverflowPolicy int
+
+const (
+	// Block makes Push wait until there is space.
+	Block OverflowPolicy = iota
+	// Reject makes Push fail with ErrFull.
+	Reject
+	// DropNewest discards the pushed item.
+	DropNewest
+	// DropOldest discards the item at the head of the queue to make space for the pushed one.
+	DropOldest
+)
+
+// BoundedChannelQueue is a Queue backed by a buffered channel.
+// Once it is full, Push follows the queue's OverflowPolicy, and TryPush only succeeds
+// if the item ends up in the queue.
+type BoundedChannelQueue[T any] struct {
+	queue  chan T
+	policy OverflowPolicy
+	// dropped counts the items discarded by the DropNewest and DropOldest policies, but not those TryPush refuses.
+	dropped atomic.Uint64
+	// done is closed by Close to wake up blocked callers.
+	done chan struct{}
+	// sealed is closed once no Push can add to queue anymore.
+	sealed chan struct{}
+	// pushing is held for reading by every Push, so Close can wait for in-flight sends.
+	pushing sync.RWMutex
+	once    sync.Once
+}
+
+// NewBoundedChannelQueue returns a queue that holds at most capacity items and
+// handles overflow according to policy.
+func NewBoundedChannelQueue[T any](capacity int, policy OverflowPolicy) *BoundedChannelQueue[T] {
+	if capacity < 1 {
+		panic("queue: capacity must be at least 1")
+	}
+
+	return &BoundedChannelQueue[T]{
+		queue:  make(chan T, capacity),
+		policy: policy,
+		done:   make(chan struct{}),
+		sealed: make(chan struct{}),
+	}
+}
+
+func (c *BoundedChannelQueue[T]) Len() int {
+	return len(c.queue)
+}
+
+// Cap returns the maximum number of items the queue holds.
+func (c *BoundedChannelQueue[T]) Cap() int {
+	return cap(c.queue)
+}
+
+// Dropped returns the number of items discarded because the queue was full.
+// An item that TryPush refuses is not counted, since the caller still holds it.
+func (c *BoundedChannelQueue[T]) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+func (c *BoundedChannelQueue[T]) TryPush(x T) bool {
+	c.pushing.RLock()
+	defer c.pushing.RUnlock()
+
+	if c.isClosed() {
+		return false
+	}
+
+	if c.policy == DropOldest {
+		c.pushEvicting(x)
+		return true
+	}
+
+	select {
+	case c.queue <- x:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *BoundedChannelQueue[T]) Push(ctx context.Context, x T) error {
+	c.pushing.RLock()
+	defer c.pushing.RUnlock()
+
+	if c.isClosed() {
+		return ErrClosed
+	}
+
+	switch c.policy {
+	case Reject:
+		select {
+		case c.queue <- x:
+			return nil
+		default:
+			return ErrFull
+		}
+	case DropNewest:
+		select {
+		case c.queue <- x:
+		default:
+			c.dropped.Add(1)
+		}
+		return nil
+	case DropOldest:
+		c.pushEvicting(x)
+		return nil
+	}
+
+	select {
+	case c.queue <- x:
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pushEvicting adds x, discarding items from the head until there is space for it.
+func (c *BoundedChannelQueue[T]) pushEvicting(x T) {
+	for {
+		select {
+		case c.queue <- x:
+			return
+		default:
+		}
+
+		// A consumer may empty the slot first, in which case nothing is dropped.
+		select {
+		case <-c.queue:
+			c.dropped.Add(1)
+		default:
+		}
+	}
 }
 
-func NewBoundedChannelQueue() *BoundedChannelQueue {
-	return &BoundedChannelQueue{queue: make(chan interface{}, 1000)}
+func (c *BoundedChannelQueue[T]) TryPop() (t T, ok bool) {
+	select {
+	case x := <-c.queue:
+		return x, true
+	default:
+		return t, false
+	}
 }
 
-func (c *BoundedChannelQueue) Push(x interface{}) {
-	c.queue <- x
+func (c *BoundedChannelQueue[T]) Pop(ctx context.Context) (t T, err error) {
+	select {
+	case x := <-c.queue:
+		return x, nil
+	case <-c.done:
+		// Wait for in-flight pushes before deciding the queue is empty.
+		<-c.sealed
+		if x, ok := c.TryPop(); ok {
+			return x, nil
+		}
+		return t, ErrClosed
+	case <-ctx.Done():
+		return t, ctx.Err()
+	}
 }
 
-func (c *BoundedChannelQueue) Pop() interface{} {
-	return <- c.queue
-}
\ No newline at end of file
+func (c *BoundedChannelQueue[T]) Close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.pushing.Lock()
+		defer c.pushing.Unlock()
+		close(c.sealed)
+	})
+}
+
+func (c *BoundedChannelQueue[T]) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
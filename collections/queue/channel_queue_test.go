@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func fill(q *BoundedChannelQueue[int], items ...int) {
+	for _, item := range items {
+		q.Push(context.Background(), item)
+	}
+}
+
+func drain(q *BoundedChannelQueue[int]) []int {
+	var items []int
+	for {
+		item, ok := q.TryPop()
+		if !ok {
+			return items
+		}
+		items = append(items, item)
+	}
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBoundedChannelQueue_Capacity(t *testing.T) {
+	t.Parallel()
+	q := NewBoundedChannelQueue[int](3, Block)
+	if q.Cap() != 3 {
+		t.Errorf("Cap() = %v, want %v", q.Cap(), 3)
+	}
+
+	fill(q, 1, 2, 3)
+	if q.TryPush(4) {
+		t.Errorf("TryPush() on a full queue = true, want false")
+	}
+}
+
+func TestBoundedChannelQueue_InvalidCapacityPanics(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected NewBoundedChannelQueue to panic for capacity 0")
+		}
+	}()
+	NewBoundedChannelQueue[int](0, Block)
+}
+
+func TestBoundedChannelQueue_Block(t *testing.T) {
+	t.Parallel()
+	q := NewBoundedChannelQueue[int](2, Block)
+	fill(q, 1, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Push(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Push() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if q.Dropped() != 0 {
+		t.Errorf("Dropped() = %v, want 0", q.Dropped())
+	}
+}
+
+func TestBoundedChannelQueue_Reject(t *testing.T) {
+	t.Parallel()
+	q := NewBoundedChannelQueue[int](2, Reject)
+	fill(q, 1, 2)
+
+	if err := q.Push(context.Background(), 3); !errors.Is(err, ErrFull) {
+		t.Errorf("Push() error = %v, want %v", err, ErrFull)
+	}
+	if got := drain(q); !equal(got, []int{1, 2}) {
+		t.Errorf("got %v, want %v", got, []int{1, 2})
+	}
+	if q.Dropped() != 0 {
+		t.Errorf("Dropped() = %v, want 0", q.Dropped())
+	}
+}
+
+func TestBoundedChannelQueue_DropNewest(t *testing.T) {
+	t.Parallel()
+	q := NewBoundedChannelQueue[int](2, DropNewest)
+	fill(q, 1, 2, 3, 4)
+
+	if q.TryPush(5) {
+		t.Errorf("TryPush() on a full queue = true, want false")
+	}
+	if got := drain(q); !equal(got, []int{1, 2}) {
+		t.Errorf("got %v, want %v", got, []int{1, 2})
+	}
+	// The item that TryPush refused is not counted
+	if q.Dropped() != 2 {
+		t.Errorf("Dropped() = %v, want 2", q.Dropped())
+	}
+}
+
+func TestBoundedChannelQueue_DropOldest(t *testing.T) {
+	t.Parallel()
+	q := NewBoundedChannelQueue[int](2, DropOldest)
+	fill(q, 1, 2, 3, 4)
+
+	if !q.TryPush(5) {
+		t.Errorf("TryPush() on a full queue = false, want true")
+	}
+	if got := drain(q); !equal(got, []int{4, 5}) {
+		t.Errorf("got %v, want %v", got, []int{4, 5})
+	}
+	if q.Dropped() != 3 {
+		t.Errorf("Dropped() = %v, want 3", q.Dropped())
+	}
+}
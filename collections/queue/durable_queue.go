@@ -0,0 +1,451 @@
+package queue
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"practice/collections/log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownMessage is returned by Ack for a message that is not waiting to be acknowledged.
+var ErrUnknownMessage = errors.New("queue: unknown or already acknowledged message")
+
+const (
+	segmentSuffix = ".log"
+	cursorFile    = "cursor"
+)
+
+// Message is an item delivered by a DurableQueue.
+type Message struct {
+	// ID identifies the message when it is acknowledged. IDs increase in push order.
+	ID   uint64
+	Data []byte
+}
+
+// DurableQueue is a FIFO queue of byte records that survives process restarts.
+//
+// Records are appended to a directory of log.FileLog segments. Each segment is named after the
+// ID of its first record, and a new one is started once the current one reaches the segment size.
+// Delivery is at-least-once: a popped message stays in the log until it is acknowledged with Ack,
+// and every message that was not acknowledged when the queue was closed is delivered again after
+// it is reopened. The ID below which every message has been acknowledged is persisted in a
+// separate cursor file, and segments that lie entirely below it are deleted.
+//
+// Push and Ack sync what they write, and the directory is synced whenever a segment or the cursor
+// file is created, so a message or acknowledgement survives a crash of the machine once the call returns.
+type DurableQueue struct {
+	lock        sync.Mutex
+	dir         string
+	segmentSize uint64
+	segments    []*segment
+	// cursor is the lowest ID that has not been acknowledged.
+	cursor uint64
+	// next is the ID of the next message to deliver.
+	next uint64
+	// end is the ID that the next pushed record will get.
+	end uint64
+	// acked holds acknowledged IDs above the cursor, for messages acknowledged out of order.
+	acked  map[uint64]struct{}
+	closed bool
+	// changed is closed and replaced whenever a record is pushed or the queue is closed.
+	changed chan struct{}
+}
+
+type segment struct {
+	base    uint64
+	path    string
+	log     *log.FileLog
+	offsets []uint64
+	size    uint64
+}
+
+// OpenDurableQueue opens the queue stored in dir, creating it if needed.
+// A segment is rolled over once it holds at least segmentSize bytes.
+func OpenDurableQueue(dir string, segmentSize uint64) (*DurableQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := log.SyncDir(filepath.Dir(dir)); err != nil {
+		return nil, err
+	}
+
+	q := &DurableQueue{
+		dir:         dir,
+		segmentSize: segmentSize,
+		acked:       make(map[uint64]struct{}),
+		changed:     make(chan struct{}),
+	}
+
+	cursor, err := q.readCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.openSegments(); err != nil {
+		q.closeSegments()
+		return nil, err
+	}
+
+	q.end = cursor
+	if len(q.segments) > 0 {
+		last := q.segments[len(q.segments)-1]
+		q.end = last.base + uint64(len(last.offsets))
+		if first := q.segments[0].base; cursor < first {
+			cursor = first
+		}
+	}
+	if cursor > q.end {
+		q.closeSegments()
+		return nil, fmt.Errorf("queue: cursor %d is past the end of the log %d", cursor, q.end)
+	}
+
+	q.cursor, q.next = cursor, cursor
+	if err := q.reclaim(); err != nil {
+		q.closeSegments()
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// Push appends data to the queue and syncs it to stable storage.
+// If the sync fails, the error is returned but the record stays in the queue, as at-least-once delivery allows.
+func (q *DurableQueue) Push(data []byte) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	seg, err := q.activeSegment()
+	if err != nil {
+		return err
+	}
+
+	offset, err := seg.log.Append(data)
+	if err != nil {
+		return err
+	}
+
+	// Each record is framed by its 8-byte length and 4-byte checksum.
+	seg.offsets = append(seg.offsets, offset)
+	seg.size = offset + 8 + uint64(len(data)) + 4
+	q.end++
+
+	close(q.changed)
+	q.changed = make(chan struct{})
+	return seg.log.Sync()
+}
+
+// Len returns the number of messages that have not been delivered yet.
+func (q *DurableQueue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return int(q.end - q.next)
+}
+
+// Unacked returns the number of delivered messages that have not been acknowledged.
+func (q *DurableQueue) Unacked() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return int(q.next-q.cursor) - len(q.acked)
+}
+
+// TryPop delivers the next message without waiting.
+// False is returned if every message has been delivered.
+// Once the queue is closed, ErrClosed is returned even if messages remain: they are delivered after it is reopened.
+func (q *DurableQueue) TryPop() (Message, bool, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return Message{}, false, ErrClosed
+	}
+
+	if q.next == q.end {
+		return Message{}, false, nil
+	}
+
+	m, err := q.deliver()
+	return m, err == nil, err
+}
+
+// Pop delivers the next message, waiting until one is pushed.
+// An error is returned if ctx is done first, or if the queue is closed.
+// Like TryPop, it returns ErrClosed after Close even if messages remain.
+func (q *DurableQueue) Pop(ctx context.Context) (Message, error) {
+	q.lock.Lock()
+	for {
+		if q.closed {
+			q.lock.Unlock()
+			return Message{}, ErrClosed
+		}
+
+		if q.next < q.end {
+			m, err := q.deliver()
+			q.lock.Unlock()
+			return m, err
+		}
+
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		}
+
+		q.lock.Lock()
+	}
+}
+
+// Ack acknowledges a delivered message, so it is not delivered again after a restart.
+func (q *DurableQueue) Ack(id uint64) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	if _, ok := q.acked[id]; ok || id < q.cursor || id >= q.next {
+		return ErrUnknownMessage
+	}
+
+	if id != q.cursor {
+		q.acked[id] = struct{}{}
+		return nil
+	}
+
+	// Move the cursor past every message acknowledged so far.
+	cursor := id + 1
+	for {
+		if _, ok := q.acked[cursor]; !ok {
+			break
+		}
+		delete(q.acked, cursor)
+		cursor++
+	}
+
+	if err := q.writeCursor(cursor); err != nil {
+		return err
+	}
+	q.cursor = cursor
+
+	return q.reclaim()
+}
+
+// Close closes the segment files and wakes up every blocked Pop.
+// Messages that have not been acknowledged are delivered again once the queue is reopened.
+func (q *DurableQueue) Close() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return nil
+	}
+
+	q.closed = true
+	close(q.changed)
+	return q.closeSegments()
+}
+
+// deliver reads the next message. The caller must hold the lock.
+func (q *DurableQueue) deliver() (Message, error) {
+	id := q.next
+
+	for _, seg := range q.segments {
+		if id >= seg.base+uint64(len(seg.offsets)) {
+			continue
+		}
+
+		data, _, err := seg.log.Read(seg.offsets[id-seg.base])
+		if err != nil {
+			return Message{}, err
+		}
+
+		q.next++
+		return Message{ID: id, Data: data}, nil
+	}
+
+	return Message{}, fmt.Errorf("queue: message %d is missing from the log", id)
+}
+
+// activeSegment returns the segment to append to, rolling over to a new one when it is full.
+func (q *DurableQueue) activeSegment() (*segment, error) {
+	if n := len(q.segments); n > 0 && q.segments[n-1].size < q.segmentSize {
+		return q.segments[n-1], nil
+	}
+
+	path := filepath.Join(q.dir, fmt.Sprintf("%020d%s", q.end, segmentSuffix))
+	fileLog, err := log.NewFileLog(path)
+	if err != nil {
+		return nil, err
+	}
+	// The records pushed to the segment are lost in a crash unless the directory holds it.
+	if err := log.SyncDir(q.dir); err != nil {
+		fileLog.Close()
+		return nil, err
+	}
+
+	seg := &segment{base: q.end, path: path, log: fileLog}
+	q.segments = append(q.segments, seg)
+	return seg, nil
+}
+
+// reclaim deletes every segment whose messages have all been acknowledged.
+// The last segment is kept, since new records are appended to it.
+func (q *DurableQueue) reclaim() error {
+	for len(q.segments) > 1 {
+		seg := q.segments[0]
+		if seg.base+uint64(len(seg.offsets)) > q.cursor {
+			return nil
+		}
+
+		if err := seg.log.Close(); err != nil {
+			return err
+		}
+		if err := os.Remove(seg.path); err != nil {
+			return err
+		}
+		q.segments = q.segments[1:]
+	}
+	return nil
+}
+
+// openSegments opens every segment in the directory and indexes its records.
+func (q *DurableQueue) openSegments() error {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return err
+	}
+
+	var bases []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+
+	for i, base := range bases {
+		if n := len(q.segments); n > 0 {
+			prev := q.segments[n-1]
+			if prev.base+uint64(len(prev.offsets)) != base {
+				return fmt.Errorf("queue: segment %d does not follow segment %d", base, prev.base)
+			}
+		}
+
+		path := filepath.Join(q.dir, fmt.Sprintf("%020d%s", base, segmentSuffix))
+		seg, err := openSegment(base, path, i == len(bases)-1)
+		if err != nil {
+			return err
+		}
+		q.segments = append(q.segments, seg)
+	}
+
+	return nil
+}
+
+// openSegment scans a segment for its records. If last is set, a record that was only partly
+// written before a crash is cut off; in any other segment it is reported as an error.
+func openSegment(base uint64, path string, last bool) (*segment, error) {
+	fileLog, err := log.NewFileLog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seg := &segment{base: base, path: path, log: fileLog}
+	for {
+		_, next, err := fileLog.Read(seg.size)
+		if err == io.EOF {
+			return seg, nil
+		}
+		if err != nil {
+			if !last {
+				fileLog.Close()
+				return nil, fmt.Errorf("queue: segment %d: %w", base, err)
+			}
+			if err := os.Truncate(path, int64(seg.size)); err != nil {
+				fileLog.Close()
+				return nil, err
+			}
+			return seg, nil
+		}
+
+		seg.offsets = append(seg.offsets, seg.size)
+		seg.size = next
+	}
+}
+
+func (q *DurableQueue) closeSegments() error {
+	var result error
+	for _, seg := range q.segments {
+		if err := seg.log.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// readCursor returns the persisted cursor, or zero for a new queue.
+func (q *DurableQueue) readCursor() (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(q.dir, cursorFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("queue: corrupt cursor file")
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// writeCursor persists the cursor by replacing the cursor file, so a crash leaves either the old or the new value.
+// The directory is synced after the rename, which makes the new value durable.
+func (q *DurableQueue) writeCursor(cursor uint64) error {
+	tmp, err := os.CreateTemp(q.dir, cursorFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], cursor)
+	if _, err := tmp.Write(buf[:]); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(q.dir, cursorFile)); err != nil {
+		return err
+	}
+	return log.SyncDir(q.dir)
+}
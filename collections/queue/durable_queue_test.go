@@ -0,0 +1,241 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openDurableQueue(t *testing.T, dir string, segmentSize uint64) *DurableQueue {
+	q, err := OpenDurableQueue(dir, segmentSize)
+	if err != nil {
+		t.Fatalf("cannot open queue: %v", err)
+	}
+	return q
+}
+
+func pushAll(t *testing.T, q *DurableQueue, records ...string) {
+	for _, record := range records {
+		if err := q.Push([]byte(record)); err != nil {
+			t.Fatalf("cannot push %q: %v", record, err)
+		}
+	}
+}
+
+func popAll(t *testing.T, q *DurableQueue) []Message {
+	var messages []Message
+	for {
+		m, ok, err := q.TryPop()
+		if err != nil {
+			t.Fatalf("cannot pop: %v", err)
+		}
+		if !ok {
+			return messages
+		}
+		messages = append(messages, m)
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestDurableQueue_FIFO(t *testing.T) {
+	t.Parallel()
+	q := openDurableQueue(t, t.TempDir(), 1024)
+	defer q.Close()
+
+	pushAll(t, q, "a", "b", "c")
+	if q.Len() != 3 {
+		t.Errorf("Len() = %v, want %v", q.Len(), 3)
+	}
+
+	for i, want := range []string{"a", "b", "c"} {
+		m, err := q.Pop(context.Background())
+		if err != nil {
+			t.Fatalf("cannot pop: %v", err)
+		}
+		if string(m.Data) != want || m.ID != uint64(i) {
+			t.Errorf("Pop() = %v %q, want %v %q", m.ID, m.Data, i, want)
+		}
+	}
+	if q.Unacked() != 3 {
+		t.Errorf("Unacked() = %v, want %v", q.Unacked(), 3)
+	}
+}
+
+func TestDurableQueue_RedeliversUnackedAfterRestart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q := openDurableQueue(t, dir, 1024)
+	pushAll(t, q, "a", "b", "c", "d")
+
+	messages := popAll(t, q)
+	// Acknowledge a and c. The cursor can only move past a, so c is delivered again.
+	q.Ack(messages[0].ID)
+	q.Ack(messages[2].ID)
+	if err := q.Close(); err != nil {
+		t.Fatalf("cannot close queue: %v", err)
+	}
+
+	q = openDurableQueue(t, dir, 1024)
+	defer q.Close()
+
+	var got []string
+	for _, m := range popAll(t, q) {
+		got = append(got, string(m.Data))
+	}
+	want := []string{"b", "c", "d"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestDurableQueue_PushAfterRestart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q := openDurableQueue(t, dir, 1024)
+	pushAll(t, q, "a")
+	q.Close()
+
+	q = openDurableQueue(t, dir, 1024)
+	defer q.Close()
+	pushAll(t, q, "b")
+
+	messages := popAll(t, q)
+	if len(messages) != 2 || messages[1].ID != 1 || string(messages[1].Data) != "b" {
+		t.Errorf("got %v, want a and b", messages)
+	}
+}
+
+func TestDurableQueue_Ack(t *testing.T) {
+	t.Parallel()
+	q := openDurableQueue(t, t.TempDir(), 1024)
+	defer q.Close()
+	pushAll(t, q, "a", "b")
+
+	if err := q.Ack(0); !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("Ack() of an undelivered message = %v, want %v", err, ErrUnknownMessage)
+	}
+
+	popAll(t, q)
+	if err := q.Ack(1); err != nil {
+		t.Errorf("Ack() = %v", err)
+	}
+	if err := q.Ack(1); !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("second Ack() = %v, want %v", err, ErrUnknownMessage)
+	}
+	if err := q.Ack(0); err != nil {
+		t.Errorf("Ack() = %v", err)
+	}
+	if q.Unacked() != 0 {
+		t.Errorf("Unacked() = %v, want 0", q.Unacked())
+	}
+}
+
+func TestDurableQueue_ReclaimsConsumedSegments(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	// Each record takes 13 bytes, so every segment holds two of them.
+	q := openDurableQueue(t, dir, 20)
+	defer q.Close()
+	pushAll(t, q, "a", "b", "c", "d", "e", "f")
+
+	if n := len(segmentFiles(t, dir)); n != 3 {
+		t.Fatalf("got %v segments, want %v", n, 3)
+	}
+
+	messages := popAll(t, q)
+	for _, m := range messages[:4] {
+		if err := q.Ack(m.ID); err != nil {
+			t.Fatalf("Ack() = %v", err)
+		}
+	}
+
+	if n := len(segmentFiles(t, dir)); n != 1 {
+		t.Errorf("got %v segments after acknowledging them, want %v", n, 1)
+	}
+}
+
+func TestDurableQueue_TruncatesTornWrite(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q := openDurableQueue(t, dir, 1024)
+	pushAll(t, q, "a", "b")
+	q.Close()
+
+	// Simulate a crash in the middle of appending a record.
+	path := segmentFiles(t, dir)[0]
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 0, 0, 0, 0, 5, 'h', 'e'})
+	f.Close()
+
+	q = openDurableQueue(t, dir, 1024)
+	defer q.Close()
+	pushAll(t, q, "c")
+
+	var got []string
+	for _, m := range popAll(t, q) {
+		got = append(got, string(m.Data))
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("got %q, want %q", got, []string{"a", "b", "c"})
+	}
+}
+
+func TestDurableQueue_PopWaitsForPush(t *testing.T) {
+	t.Parallel()
+	q := openDurableQueue(t, t.TempDir(), 1024)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Push([]byte("a"))
+	}()
+
+	m, err := q.Pop(context.Background())
+	if err != nil || string(m.Data) != "a" {
+		t.Errorf("Pop() = %q, %v, want %q, nil", m.Data, err, "a")
+	}
+
+	q.Close()
+	if _, err := q.Pop(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Pop() after Close = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestDurableQueue_PopAfterClose(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	q := openDurableQueue(t, dir, 1024)
+	pushAll(t, q, "a", "b")
+	q.Close()
+
+	if _, _, err := q.TryPop(); !errors.Is(err, ErrClosed) {
+		t.Errorf("TryPop() after Close = %v, want %v", err, ErrClosed)
+	}
+	if _, err := q.Pop(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Pop() after Close = %v, want %v", err, ErrClosed)
+	}
+
+	// The undelivered messages are still there once the queue is reopened
+	q = openDurableQueue(t, dir, 1024)
+	defer q.Close()
+	if messages := popAll(t, q); len(messages) != 2 {
+		t.Errorf("got %v messages after reopening, want %v", len(messages), 2)
+	}
+}
@@ -0,0 +1,252 @@
+package queue
+
+import (
+	"context"
+	"practice/collections/deque"
+	"sync"
+)
+
+// FairQueue is a thread-safe queue shared by many tenants, each with its own bounded FIFO queue.
+// Pop serves tenants by weighted round-robin: every turn a tenant may pop up to its weight in
+// items before the next tenant with queued items gets a turn, so a tenant with a large backlog
+// cannot starve the others. Tenants have a weight of one unless SetWeight says otherwise.
+// The queue only keeps track of tenants that have queued items or a weight other than one.
+type FairQueue[K comparable, T any] struct {
+	lock     sync.Mutex
+	capacity int
+	tenants  map[K]*tenantQueue[K, T]
+	// active holds the tenants with queued items, in the order they get their turns.
+	active *deque.Deque[*tenantQueue[K, T]]
+	size   int
+	closed bool
+	// changed is closed and replaced whenever an item is pushed or popped, or the queue is closed.
+	changed chan struct{}
+}
+
+type tenantQueue[K comparable, T any] struct {
+	key    K
+	items  *deque.Deque[T]
+	weight int
+	// quota is how many more items the tenant may pop during its current turn.
+	quota int
+}
+
+// TenantStats describes the state of one tenant in a FairQueue.
+type TenantStats struct {
+	// Depth is the number of queued items.
+	Depth  int
+	Weight int
+}
+
+// NewFairQueue returns a FairQueue in which every tenant may queue at most capacity items.
+// A capacity of zero or less means tenants are unbounded.
+func NewFairQueue[K comparable, T any](capacity int) *FairQueue[K, T] {
+	return &FairQueue[K, T]{
+		capacity: capacity,
+		tenants:  make(map[K]*tenantQueue[K, T]),
+		active:   deque.New[*tenantQueue[K, T]](),
+		changed:  make(chan struct{}),
+	}
+}
+
+// SetWeight sets the number of items the tenant may pop per turn. It panics if weight is less than one.
+func (q *FairQueue[K, T]) SetWeight(tenant K, weight int) {
+	if weight < 1 {
+		panic("queue: weight must be at least 1")
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	t := q.tenant(tenant)
+	t.weight = weight
+	q.forget(t)
+}
+
+// Len returns the number of items queued by all tenants.
+func (q *FairQueue[K, T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.size
+}
+
+// Stats returns the depth and weight of every tenant the queue keeps track of:
+// those with queued items or a weight set by SetWeight.
+func (q *FairQueue[K, T]) Stats() map[K]TenantStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	stats := make(map[K]TenantStats, len(q.tenants))
+	for key, t := range q.tenants {
+		stats[key] = TenantStats{
+			Depth:  t.items.Len(),
+			Weight: t.weight,
+		}
+	}
+	return stats
+}
+
+// TryPush adds v to the tenant's queue without waiting.
+// False is returned if the tenant's queue is full or the queue is closed.
+func (q *FairQueue[K, T]) TryPush(tenant K, v T) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed || q.full(q.tenants[tenant]) {
+		return false
+	}
+
+	q.push(q.tenant(tenant), v)
+	return true
+}
+
+// Push adds v to the tenant's queue, waiting for space if it is full.
+// An error is returned if the queue is closed or ctx is done before v is added.
+func (q *FairQueue[K, T]) Push(ctx context.Context, tenant K, v T) error {
+	q.lock.Lock()
+	for {
+		if q.closed {
+			q.lock.Unlock()
+			return ErrClosed
+		}
+
+		if !q.full(q.tenants[tenant]) {
+			q.push(q.tenant(tenant), v)
+			q.lock.Unlock()
+			return nil
+		}
+
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		q.lock.Lock()
+	}
+}
+
+// TryPop removes and returns the next item and its tenant without waiting.
+// False is returned if the queue is empty.
+func (q *FairQueue[K, T]) TryPop() (tenant K, v T, ok bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.pop()
+}
+
+// Pop removes and returns the next item and its tenant, waiting until one is available.
+// An error is returned if ctx is done first, or if the queue is closed and empty.
+func (q *FairQueue[K, T]) Pop(ctx context.Context) (tenant K, v T, err error) {
+	q.lock.Lock()
+	for {
+		if key, item, ok := q.pop(); ok {
+			q.lock.Unlock()
+			return key, item, nil
+		}
+
+		if q.closed {
+			q.lock.Unlock()
+			return tenant, v, ErrClosed
+		}
+
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return tenant, v, ctx.Err()
+		}
+
+		q.lock.Lock()
+	}
+}
+
+// Close stops the queue from accepting new items and wakes up every blocked Push and Pop.
+// Pop keeps returning the remaining items. Closing a closed queue has no effect.
+func (q *FairQueue[K, T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.broadcast()
+	}
+}
+
+// tenant returns the tenant's queue, creating it on first use. The caller must hold the lock.
+func (q *FairQueue[K, T]) tenant(key K) *tenantQueue[K, T] {
+	t, ok := q.tenants[key]
+	if !ok {
+		t = &tenantQueue[K, T]{key: key, items: deque.New[T](), weight: 1}
+		q.tenants[key] = t
+	}
+	return t
+}
+
+// forget drops the tenant if it is idle and has the default weight. The caller must hold the lock.
+func (q *FairQueue[K, T]) forget(t *tenantQueue[K, T]) {
+	if t.items.Len() == 0 && t.weight == 1 {
+		delete(q.tenants, t.key)
+	}
+}
+
+// full reports whether the tenant's queue is full. A tenant the queue does not keep track of is empty.
+func (q *FairQueue[K, T]) full(t *tenantQueue[K, T]) bool {
+	return t != nil && q.capacity > 0 && t.items.Len() >= q.capacity
+}
+
+// push appends v to the tenant's queue. The caller must hold the lock.
+func (q *FairQueue[K, T]) push(t *tenantQueue[K, T], v T) {
+	if t.items.Len() == 0 {
+		// the tenant joins the back of the round
+		q.active.PushBack(t)
+	}
+
+	t.items.PushBack(v)
+	q.size++
+	q.broadcast()
+}
+
+// pop takes the next item from the tenant whose turn it is. The caller must hold the lock.
+func (q *FairQueue[K, T]) pop() (key K, v T, ok bool) {
+	t, ok := q.active.Front()
+	if !ok {
+		return key, v, false
+	}
+
+	if t.quota == 0 {
+		// a new turn starts
+		t.quota = t.weight
+	}
+
+	v, _ = t.items.PopFront()
+	t.quota--
+	q.size--
+
+	if t.items.Len() == 0 {
+		// the tenant leaves the round, and starts afresh when it pushes again
+		t.quota = 0
+		q.active.PopFront()
+		q.forget(t)
+	} else if t.quota == 0 {
+		// the turn is over, move to the back of the round
+		q.active.PopFront()
+		q.active.PushBack(t)
+	}
+
+	q.broadcast()
+	return t.key, v, true
+}
+
+// broadcast wakes up every goroutine waiting on the current changed channel.
+// The caller must hold the lock.
+func (q *FairQueue[K, T]) broadcast() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
@@ -0,0 +1,209 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func popTenants(q *FairQueue[string, int], n int) []string {
+	var tenants []string
+	for i := 0; i < n; i++ {
+		tenant, _, ok := q.TryPop()
+		if !ok {
+			break
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants
+}
+
+func TestFairQueue_NoisyTenantDoesNotStarveOthers(t *testing.T) {
+	t.Parallel()
+	q := NewFairQueue[string, int](0)
+	for i := 0; i < 100; i++ {
+		q.TryPush("noisy", i)
+	}
+	q.TryPush("quiet", 0)
+	q.TryPush("other", 0)
+
+	got := popTenants(q, 3)
+	want := []string{"noisy", "quiet", "other"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFairQueue_PerTenantFIFO(t *testing.T) {
+	t.Parallel()
+	q := NewFairQueue[string, int](0)
+	for i := 0; i < 10; i++ {
+		q.TryPush("a", i)
+		q.TryPush("b", i)
+	}
+
+	next := map[string]int{}
+	for q.Len() > 0 {
+		tenant, v, _ := q.TryPop()
+		if v != next[tenant] {
+			t.Fatalf("tenant %v: got %v, want %v", tenant, v, next[tenant])
+		}
+		next[tenant]++
+	}
+}
+
+func TestFairQueue_Weights(t *testing.T) {
+	t.Parallel()
+	q := NewFairQueue[string, int](0)
+	q.SetWeight("gold", 3)
+	for i := 0; i < 30; i++ {
+		q.TryPush("gold", i)
+		q.TryPush("bronze", i)
+	}
+
+	counts := map[string]int{}
+	for _, tenant := range popTenants(q, 40) {
+		counts[tenant]++
+	}
+	if counts["gold"] != 30 || counts["bronze"] != 10 {
+		t.Errorf("got %v, want 30 gold and 10 bronze", counts)
+	}
+}
+
+func TestFairQueue_PerTenantCapacity(t *testing.T) {
+	t.Parallel()
+	q := NewFairQueue[string, int](2)
+	q.TryPush("a", 1)
+	q.TryPush("a", 2)
+
+	if q.TryPush("a", 3) {
+		t.Errorf("TryPush() on a full tenant = true, want false")
+	}
+	if !q.TryPush("b", 1) {
+		t.Errorf("a full tenant must not block other tenants")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Push(ctx, "a", 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Push() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- q.Push(context.Background(), "a", 3)
+	}()
+	popTenants(q, 1)
+	if err := <-done; err != nil {
+		t.Errorf("Push() = %v, want nil", err)
+	}
+}
+
+func TestFairQueue_Stats(t *testing.T) {
+	t.Parallel()
+	q := NewFairQueue[string, int](0)
+	q.SetWeight("a", 2)
+	q.TryPush("a", 1)
+	q.TryPush("a", 2)
+	q.TryPush("b", 1)
+	q.TryPop()
+
+	stats := q.Stats()
+	if got := stats["a"]; got != (TenantStats{Depth: 1, Weight: 2}) {
+		t.Errorf("Stats()[a] = %+v", got)
+	}
+	if got := stats["b"]; got != (TenantStats{Depth: 1, Weight: 1}) {
+		t.Errorf("Stats()[b] = %+v", got)
+	}
+}
+
+func TestFairQueue_ForgetsIdleTenants(t *testing.T) {
+	t.Parallel()
+	q := NewFairQueue[string, int](1)
+	q.SetWeight("weighted", 2)
+	q.TryPush("a", 1)
+	q.TryPush("a", 2)
+	q.TryPush("b", 1)
+	popTenants(q, 2)
+
+	// Only the tenant with a weight is kept once everyone is idle
+	if stats := q.Stats(); len(stats) != 1 || stats["weighted"].Weight != 2 {
+		t.Errorf("Stats() = %+v, want only the weighted tenant", stats)
+	}
+
+	// A push that fails does not add the tenant
+	q.Close()
+	q.TryPush("c", 1)
+	q.Push(context.Background(), "d", 1)
+	if stats := q.Stats(); len(stats) != 1 {
+		t.Errorf("Stats() after failed pushes = %+v, want only the weighted tenant", stats)
+	}
+}
+
+func TestFairQueue_Close(t *testing.T) {
+	t.Parallel()
+	q := NewFairQueue[string, int](0)
+	q.TryPush("a", 1)
+	q.Close()
+
+	if err := q.Push(context.Background(), "a", 2); !errors.Is(err, ErrClosed) {
+		t.Errorf("Push() after Close error = %v, want %v", err, ErrClosed)
+	}
+	if _, v, err := q.Pop(context.Background()); err != nil || v != 1 {
+		t.Errorf("Pop() = %v, %v, want %v, nil", v, err, 1)
+	}
+	if _, _, err := q.Pop(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Pop() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestFairQueue_ConcurrentProducersAndConsumers(t *testing.T) {
+	t.Parallel()
+	const tenants, perTenant = 4, 500
+	q := NewFairQueue[int, int](8)
+	ctx := context.Background()
+
+	var producing sync.WaitGroup
+	for tenant := 0; tenant < tenants; tenant++ {
+		producing.Add(1)
+		go func(tenant int) {
+			defer producing.Done()
+			for i := 0; i < perTenant; i++ {
+				q.Push(ctx, tenant, i)
+			}
+		}(tenant)
+	}
+
+	var lock sync.Mutex
+	next := make([]int, tenants)
+	var consuming sync.WaitGroup
+	for c := 0; c < 2; c++ {
+		consuming.Add(1)
+		go func() {
+			defer consuming.Done()
+			for {
+				tenant, _, err := q.Pop(ctx)
+				if err != nil {
+					return
+				}
+				lock.Lock()
+				next[tenant]++
+				lock.Unlock()
+			}
+		}()
+	}
+
+	producing.Wait()
+	q.Close()
+	consuming.Wait()
+
+	for tenant, n := range next {
+		if n != perTenant {
+			t.Errorf("tenant %v: popped %v items, want %v", tenant, n, perTenant)
+		}
+	}
+}
@@ -1,29 +1,50 @@
 package queue
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
-type node struct {
-	data interface{}
-	next *node
+type node[T any] struct {
+	data T
+	next *node[T]
 }
 
-type LinkedQueue struct {
-	head *node
-	tail *node
-	lock *sync.Mutex
+// LinkedQueue is an unbounded Queue backed by a singly-linked list.
+// Push only blocks while waiting for the lock.
+type LinkedQueue[T any] struct {
+	head   *node[T]
+	tail   *node[T]
+	size   int
+	closed bool
+	lock   *sync.Mutex
+	// changed is closed and replaced whenever an item is pushed or the queue is closed.
+	changed chan struct{}
 }
 
-func NewLinkedQueue() *LinkedQueue {
-	return &LinkedQueue{
-		lock: &sync.Mutex{},
+func NewLinkedQueue[T any]() *LinkedQueue[T] {
+	return &LinkedQueue[T]{
+		lock:    &sync.Mutex{},
+		changed: make(chan struct{}),
 	}
 }
 
-func (l *LinkedQueue) Push(x interface{}) {
+func (l *LinkedQueue[T]) Len() int {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	n := &node{data: x}
+	return l.size
+}
+
+func (l *LinkedQueue[T]) TryPush(x T) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.closed {
+		return false
+	}
+
+	n := &node[T]{data: x}
 
 	if l.tail == nil {
 		// add node to empty queue
@@ -32,15 +53,73 @@ func (l *LinkedQueue) Push(x interface{}) {
 		// add node to non-empty queue
 		l.tail.next, l.tail = n, n
 	}
+	l.size++
+
+	// wake up waiting consumers
+	close(l.changed)
+	l.changed = make(chan struct{})
+	return true
+}
+
+func (l *LinkedQueue[T]) Push(ctx context.Context, x T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !l.TryPush(x) {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (l *LinkedQueue[T]) TryPop() (t T, ok bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.pop()
+}
+
+func (l *LinkedQueue[T]) Pop(ctx context.Context) (t T, err error) {
+	l.lock.Lock()
+	for {
+		if x, ok := l.pop(); ok {
+			l.lock.Unlock()
+			return x, nil
+		}
+
+		if l.closed {
+			l.lock.Unlock()
+			return t, ErrClosed
+		}
+
+		changed := l.changed
+		l.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return t, ctx.Err()
+		}
+
+		l.lock.Lock()
+	}
 }
 
-func (l *LinkedQueue) Pop() interface{} {
+func (l *LinkedQueue[T]) Close() {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
+	if !l.closed {
+		l.closed = true
+		close(l.changed)
+		l.changed = make(chan struct{})
+	}
+}
+
+// pop removes the head of the queue. The caller must hold the lock.
+func (l *LinkedQueue[T]) pop() (t T, ok bool) {
 	if l.head == nil {
 		// nothing on queue
-		return nil
+		return t, false
 	}
 
 	// remove node from queue
@@ -51,6 +130,7 @@ func (l *LinkedQueue) Pop() interface{} {
 		// queue has no elements now
 		l.tail = nil
 	}
+	l.size--
 
-	return n.data
+	return n.data, true
 }
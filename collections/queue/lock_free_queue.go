@@ -0,0 +1,153 @@
+package queue
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+type lockFreeNode[T any] struct {
+	data T
+	next atomic.Pointer[lockFreeNode[T]]
+}
+
+// LockFreeQueue is an unbounded multi-producer/multi-consumer Queue that never takes a lock.
+// It is the Michael-Scott queue ("Simple, Fast, and Practical Non-Blocking and Blocking
+// Concurrent Queue Algorithms", 1996): a linked list with a sentinel head whose head and tail
+// pointers are only ever moved with compare-and-swap.
+// Pop has nothing to block on, so it polls with an exponential backoff while the queue is empty.
+type LockFreeQueue[T any] struct {
+	// head points to the sentinel; the first item is head.next.
+	head atomic.Pointer[lockFreeNode[T]]
+	tail atomic.Pointer[lockFreeNode[T]]
+	size atomic.Int64
+	// pushing counts TryPush calls in progress, so Pop can tell when a closed queue is drained.
+	pushing atomic.Int64
+	closed  atomic.Bool
+}
+
+func NewLockFreeQueue[T any]() *LockFreeQueue[T] {
+	q := &LockFreeQueue[T]{}
+	sentinel := &lockFreeNode[T]{}
+	q.head.Store(sentinel)
+	q.tail.Store(sentinel)
+	return q
+}
+
+func (q *LockFreeQueue[T]) Len() int {
+	// The counter trails the list, so it can briefly dip below zero.
+	if n := q.size.Load(); n > 0 {
+		return int(n)
+	}
+	return 0
+}
+
+func (q *LockFreeQueue[T]) TryPush(x T) bool {
+	q.pushing.Add(1)
+	defer q.pushing.Add(-1)
+
+	if q.closed.Load() {
+		return false
+	}
+
+	n := &lockFreeNode[T]{data: x}
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+
+		if tail != q.tail.Load() {
+			// tail moved while we were reading it
+			continue
+		}
+
+		if next != nil {
+			// tail is lagging behind, help the other producer move it
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		if tail.next.CompareAndSwap(nil, n) {
+			// linked in, now try to swing tail (someone else may beat us to it)
+			q.tail.CompareAndSwap(tail, n)
+			q.size.Add(1)
+			return true
+		}
+	}
+}
+
+func (q *LockFreeQueue[T]) Push(ctx context.Context, x T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !q.TryPush(x) {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (q *LockFreeQueue[T]) TryPop() (t T, ok bool) {
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+
+		if head != q.head.Load() {
+			// head moved while we were reading it
+			continue
+		}
+
+		if next == nil {
+			// only the sentinel is left
+			return t, false
+		}
+
+		if head == tail {
+			// tail is lagging behind, help the producer move it
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		// read the value before the CAS, since another consumer may pop next right after it
+		x := next.data
+		if q.head.CompareAndSwap(head, next) {
+			q.size.Add(-1)
+			return x, true
+		}
+	}
+}
+
+func (q *LockFreeQueue[T]) Pop(ctx context.Context) (t T, err error) {
+	backoff := time.Microsecond
+	for spins := 0; ; spins++ {
+		if x, ok := q.TryPop(); ok {
+			return x, nil
+		}
+
+		if q.closed.Load() && q.pushing.Load() == 0 {
+			// no new push can start, so one last look decides whether the queue is drained
+			if x, ok := q.TryPop(); ok {
+				return x, nil
+			}
+			return t, ErrClosed
+		}
+
+		if err := ctx.Err(); err != nil {
+			return t, err
+		}
+
+		if spins < 16 {
+			runtime.Gosched()
+			continue
+		}
+
+		time.Sleep(backoff)
+		if backoff < time.Millisecond {
+			backoff *= 2
+		}
+	}
+}
+
+func (q *LockFreeQueue[T]) Close() {
+	q.closed.Store(true)
+}
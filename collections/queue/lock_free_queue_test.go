@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TestLockFreeQueue_Stress hammers the queue with TryPush and TryPop from many goroutines.
+// Run it with -race to check the memory ordering of the atomic operations.
+func TestLockFreeQueue_Stress(t *testing.T) {
+	t.Parallel()
+	const workers, perWorker = 8, 2000
+	q := NewLockFreeQueue[int]()
+
+	seen := make([]int32, workers*perWorker)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				q.TryPush(w*perWorker + i)
+
+				// every worker pops as often as it pushes, racing the others for the head
+				for {
+					v, ok := q.TryPop()
+					if ok {
+						lock.Lock()
+						seen[v]++
+						lock.Unlock()
+						break
+					}
+					runtime.Gosched()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for v, n := range seen {
+		if n != 1 {
+			t.Fatalf("item %v was popped %v times, want 1", v, n)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %v, want 0", q.Len())
+	}
+}
+
+// TestLockFreeQueue_PerProducerOrder checks that items from a single producer stay in order.
+func TestLockFreeQueue_PerProducerOrder(t *testing.T) {
+	t.Parallel()
+	const producers, perProducer = 4, 5000
+	q := NewLockFreeQueue[[2]int]()
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.TryPush([2]int{p, i})
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		q.Close()
+	}()
+
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	for {
+		v, err := q.Pop(context.Background())
+		if err != nil {
+			break
+		}
+		if v[1] != last[v[0]]+1 {
+			t.Fatalf("producer %v: got item %v after %v", v[0], v[1], last[v[0]])
+		}
+		last[v[0]] = v[1]
+	}
+
+	for p, l := range last {
+		if l != perProducer-1 {
+			t.Errorf("producer %v: last item %v, want %v", p, l, perProducer-1)
+		}
+	}
+}
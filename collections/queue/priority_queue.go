@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"context"
+	"practice/collections/heap"
+	"sync"
+)
+
+// PriorityQueue is a Queue backed by a heap.DHeap.
+// Instead of FIFO order, it pops the item that the heap orders first.
+type PriorityQueue[T any] struct {
+	lock     sync.Mutex
+	heap     *heap.DHeap[T]
+	capacity int
+	closed   bool
+	// changed is closed and replaced whenever an item is pushed or popped, or the queue is closed.
+	changed chan struct{}
+}
+
+// NewPriorityQueue returns an unbounded priority queue using a heap of the given degree.
+func NewPriorityQueue[T any](degree int, less func(a, b T) bool) *PriorityQueue[T] {
+	return NewBoundedPriorityQueue(degree, 0, less)
+}
+
+// NewBoundedPriorityQueue returns a priority queue that holds at most capacity items.
+// A capacity of zero or less means the queue is unbounded.
+func NewBoundedPriorityQueue[T any](degree, capacity int, less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		heap:     heap.New(degree, less),
+		capacity: capacity,
+		changed:  make(chan struct{}),
+	}
+}
+
+// Len returns the number of items in the queue.
+func (q *PriorityQueue[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.heap.Len()
+}
+
+// Push adds v to the queue, waiting for space if the queue is full.
+// An error is returned if the queue is closed or ctx is done before v is added.
+func (q *PriorityQueue[T]) Push(ctx context.Context, v T) error {
+	q.lock.Lock()
+	for {
+		if q.closed {
+			q.lock.Unlock()
+			return ErrClosed
+		}
+
+		if !q.full() {
+			q.heap.Insert(v)
+			q.broadcast()
+			q.lock.Unlock()
+			return nil
+		}
+
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		q.lock.Lock()
+	}
+}
+
+// TryPush adds v to the queue without waiting.
+// False is returned if the queue is full or closed.
+func (q *PriorityQueue[T]) TryPush(v T) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed || q.full() {
+		return false
+	}
+
+	q.heap.Insert(v)
+	q.broadcast()
+	return true
+}
+
+// Pop removes and returns the highest priority item, waiting until one is available.
+// An error is returned if ctx is done first, or if the queue is closed and empty.
+func (q *PriorityQueue[T]) Pop(ctx context.Context) (t T, err error) {
+	q.lock.Lock()
+	for {
+		if v, ok := q.heap.Extract(); ok {
+			q.broadcast()
+			q.lock.Unlock()
+			return v, nil
+		}
+
+		if q.closed {
+			q.lock.Unlock()
+			return t, ErrClosed
+		}
+
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return t, ctx.Err()
+		}
+
+		q.lock.Lock()
+	}
+}
+
+// TryPop removes and returns the highest priority item without waiting.
+// False is returned if the queue is empty.
+func (q *PriorityQueue[T]) TryPop() (t T, ok bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	v, ok := q.heap.Extract()
+	if ok {
+		q.broadcast()
+	}
+	return v, ok
+}
+
+// Close stops the queue from accepting new items and wakes up every blocked Push and Pop.
+// Closing a closed queue has no effect.
+func (q *PriorityQueue[T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.broadcast()
+	}
+}
+
+func (q *PriorityQueue[T]) full() bool {
+	return q.capacity > 0 && q.heap.Len() >= q.capacity
+}
+
+// broadcast wakes up every goroutine waiting on the current changed channel.
+// The caller must hold the lock.
+func (q *PriorityQueue[T]) broadcast() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newMinPriorityQueue(capacity int) *PriorityQueue[int] {
+	return NewBoundedPriorityQueue(4, capacity, func(a, b int) bool { return a < b })
+}
+
+func TestPriorityQueue_Order(t *testing.T) {
+	t.Parallel()
+	q := newMinPriorityQueue(0)
+	ctx := context.Background()
+
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		if err := q.Push(ctx, v); err != nil {
+			t.Fatalf("Push() = %v", err)
+		}
+	}
+
+	for want := 1; want <= 5; want++ {
+		got, err := q.Pop(ctx)
+		if err != nil || got != want {
+			t.Errorf("Pop() = %v, %v, want %v, nil", got, err, want)
+		}
+	}
+}
+
+func TestPriorityQueue_BoundedPushWaitsForSpace(t *testing.T) {
+	t.Parallel()
+	q := newMinPriorityQueue(2)
+	ctx := context.Background()
+	q.Push(ctx, 1)
+	q.Push(ctx, 2)
+
+	if q.TryPush(3) {
+		t.Fatalf("TryPush() on a full queue returned true")
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := q.Push(timeout, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Push() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- q.Push(ctx, 3)
+	}()
+
+	if v, _ := q.TryPop(); v != 1 {
+		t.Errorf("TryPop() = %v, want %v", v, 1)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Push() = %v, want nil", err)
+	}
+	if q.Len() != 2 {
+		t.Errorf("Len() = %v, want %v", q.Len(), 2)
+	}
+}
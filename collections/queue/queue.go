@@ -1,6 +1,35 @@
 package queue
 
-type Queue interface {
-	Push(x interface{})
-	Pop() interface{}
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned by operations on a queue that has been closed.
+var ErrClosed = errors.New("queue: closed")
+
+// Queue is a thread-safe queue shared by producers and consumers.
+//
+// Every implementation follows the same contract:
+//   - TryPush and TryPop never block; they report whether they succeeded.
+//   - Push blocks while the queue is full, and Pop blocks while it is empty,
+//     until they succeed, ctx is done (returning ctx.Err()), or the queue is closed.
+//   - Close wakes up every blocked Push and Pop. After Close, Push fails with ErrClosed and
+//     TryPush returns false, while Pop keeps returning the remaining items and fails with
+//     ErrClosed once the queue is empty. Closing a closed queue has no effect.
+//
+// Items are popped in FIFO order unless the implementation documents otherwise.
+type Queue[T any] interface {
+	// TryPush adds v without waiting. False is returned if the queue is full or closed.
+	TryPush(v T) bool
+	// TryPop removes and returns the next item without waiting. False is returned if the queue is empty.
+	TryPop() (T, bool)
+	// Push adds v, waiting for space if the queue is full.
+	Push(ctx context.Context, v T) error
+	// Pop removes and returns the next item, waiting until one is available.
+	Pop(ctx context.Context) (T, error)
+	// Len returns the number of items in the queue.
+	Len() int
+	// Close stops the queue from accepting new items.
+	Close()
 }
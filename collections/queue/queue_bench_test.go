@@ -0,0 +1,27 @@
+package queue
+
+import (
+	"context"
+	"testing"
+)
+
+// BenchmarkQueues measures a push followed by a pop from every parallel goroutine.
+// Compare the implementations with, for example, `go test -bench Queues -cpu 1,4,16`.
+func BenchmarkQueues(b *testing.B) {
+	for _, test := range queues() {
+		if !test.fifo {
+			continue
+		}
+		test := test
+		b.Run(test.name, func(b *testing.B) {
+			q := test.new()
+			ctx := context.Background()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.Push(ctx, 1)
+					q.Pop(ctx)
+				}
+			})
+		})
+	}
+}
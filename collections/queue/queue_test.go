@@ -1,35 +1,58 @@
 package queue
 
 import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
 	"testing"
 	"testing/quick"
+	"time"
 )
 
-func TestQueues(t *testing.T) {
+type queueTest struct {
+	name string
+	new  func() Queue[int]
+	// fifo is false for queues that pop in a different order, which are only fed increasing values.
+	fifo bool
+}
 
-	tests := []struct {
-		name  string
-		queue Queue
-	}{
-		{name: "Bounded Channel Queue", queue: NewBoundedChannelQueue()},
-		{name: "Linked Queue", queue: NewLinkedQueue()},
+func queues() []queueTest {
+	return []queueTest{
+		{name: "Bounded Channel Queue", new: func() Queue[int] { return NewBoundedChannelQueue[int](1000, Block) }, fifo: true},
+		{name: "Bounded Channel Queue (DropOldest)", new: func() Queue[int] { return NewBoundedChannelQueue[int](1000, DropOldest) }, fifo: true},
+		{name: "Linked Queue", new: func() Queue[int] { return NewLinkedQueue[int]() }, fifo: true},
+		{name: "Lock-Free Queue", new: func() Queue[int] { return NewLockFreeQueue[int]() }, fifo: true},
+		{name: "Priority Queue", new: func() Queue[int] { return newMinPriorityQueue(0) }},
 	}
+}
 
+func TestQueues(t *testing.T) {
 	// Property: all queues should behave the same way in a single-threaded environment (FIFO)
 	queueProperty := func(given []int) bool {
 
 		// test each queue
-		for _, test := range tests {
+		for _, test := range queues() {
+			if !test.fifo {
+				continue
+			}
+			q := test.new()
 
 			// push all items into queue
 			for _, item := range given {
-				test.queue.Push(item)
+				if !q.TryPush(item) {
+					t.Errorf("test: %s: could not push %d", test.name, item)
+				}
+			}
+
+			if q.Len() != len(given) {
+				t.Errorf("test: %s: got length %d want %d", test.name, q.Len(), len(given))
 			}
 
 			// pop all items from queue & assert equality
 			for i := 0; i < len(given); i++ {
 				want := given[i]
-				got := test.queue.Pop()
+				got, _ := q.TryPop()
 				if want != got {
 					t.Errorf("test: %s: got %d want %d", test.name, got, want)
 				}
@@ -39,7 +62,145 @@ func TestQueues(t *testing.T) {
 		return true
 	}
 
-	if err := quick.Check(queueProperty, &quick.Config{MaxCount: 1}); err != nil {
+	if err := quick.Check(queueProperty, &quick.Config{MaxCount: 100}); err != nil {
 		t.Error(err)
 	}
 }
+
+// TestQueueContract runs every queue against the contract documented on Queue
+func TestQueueContract(t *testing.T) {
+	for _, test := range queues() {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			testQueueContract(t, test.new)
+		})
+	}
+}
+
+func testQueueContract(t *testing.T, newQueue func() Queue[int]) {
+	t.Run("TryPop on an empty queue", func(t *testing.T) {
+		q := newQueue()
+		if _, ok := q.TryPop(); ok {
+			t.Errorf("TryPop() on an empty queue returned ok")
+		}
+	})
+
+	t.Run("Pop waits for Push", func(t *testing.T) {
+		q := newQueue()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			q.Push(context.Background(), 42)
+		}()
+
+		got, err := q.Pop(context.Background())
+		if err != nil || got != 42 {
+			t.Errorf("Pop() = %v, %v, want %v, nil", got, err, 42)
+		}
+	})
+
+	t.Run("Pop honors the context", func(t *testing.T) {
+		q := newQueue()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := q.Pop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Pop() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("Close wakes up waiters", func(t *testing.T) {
+		q := newQueue()
+		errs := make(chan error, 3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				_, err := q.Pop(context.Background())
+				errs <- err
+			}()
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		q.Close()
+		for i := 0; i < 3; i++ {
+			if err := <-errs; !errors.Is(err, ErrClosed) {
+				t.Errorf("Pop() error = %v, want %v", err, ErrClosed)
+			}
+		}
+	})
+
+	t.Run("Close keeps remaining items", func(t *testing.T) {
+		q := newQueue()
+		ctx := context.Background()
+		q.Push(ctx, 1)
+		q.Push(ctx, 2)
+		q.Close()
+		q.Close()
+
+		if err := q.Push(ctx, 3); !errors.Is(err, ErrClosed) {
+			t.Errorf("Push() after Close error = %v, want %v", err, ErrClosed)
+		}
+		if q.TryPush(3) {
+			t.Errorf("TryPush() after Close = true, want false")
+		}
+		for want := 1; want <= 2; want++ {
+			if got, err := q.Pop(ctx); err != nil || got != want {
+				t.Errorf("Pop() = %v, %v, want %v, nil", got, err, want)
+			}
+		}
+		if _, err := q.Pop(ctx); !errors.Is(err, ErrClosed) {
+			t.Errorf("Pop() error = %v, want %v", err, ErrClosed)
+		}
+	})
+
+	t.Run("Concurrent producers and consumers", func(t *testing.T) {
+		const producers, perProducer = 4, 250
+		q := newQueue()
+		ctx := context.Background()
+
+		var producing sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			producing.Add(1)
+			go func(p int) {
+				defer producing.Done()
+				for i := 0; i < perProducer; i++ {
+					if err := q.Push(ctx, p*perProducer+i); err != nil {
+						t.Errorf("Push() = %v", err)
+					}
+				}
+			}(p)
+		}
+
+		var lock sync.Mutex
+		var got []int
+		var consuming sync.WaitGroup
+		for c := 0; c < 4; c++ {
+			consuming.Add(1)
+			go func() {
+				defer consuming.Done()
+				for {
+					v, err := q.Pop(ctx)
+					if err != nil {
+						return
+					}
+					lock.Lock()
+					got = append(got, v)
+					lock.Unlock()
+				}
+			}()
+		}
+
+		producing.Wait()
+		q.Close()
+		consuming.Wait()
+
+		sort.Ints(got)
+		if len(got) != producers*perProducer {
+			t.Fatalf("got %v items, want %v", len(got), producers*perProducer)
+		}
+		for i, v := range got {
+			if v != i {
+				t.Fatalf("item %v = %v, want %v", i, v, i)
+			}
+		}
+	})
+}
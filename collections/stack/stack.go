@@ -1,5 +1,7 @@
 package stack
 
+import "iter"
+
 // Stack implements a LIFO stack with peeking.
 type Stack[T any] struct {
 	data []T
@@ -12,15 +14,55 @@ func New[T any]() *Stack[T] {
 	}
 }
 
+// Len returns the number of elements in the stack.
+func (s *Stack[T]) Len() int {
+	return len(s.data)
+}
+
 func (s *Stack[T]) Push(v T) {
 	s.data = append(s.data, v)
 }
 
+// Pop removes and returns the top element, or the zero value if the stack is empty.
+// Use TryPop to tell an empty stack apart from a stored zero value.
 func (s *Stack[T]) Pop() (t T) {
+	t, _ = s.TryPop()
+	return t
+}
+
+// TryPop removes and returns the top element.
+// False is returned if the stack is empty.
+func (s *Stack[T]) TryPop() (t T, ok bool) {
 	if len(s.data) == 0 {
-		return t
+		return t, false
 	}
 	v := s.data[len(s.data)-1]
+	s.data[len(s.data)-1] = t
 	s.data = s.data[:len(s.data)-1]
-	return v
+	return v, true
+}
+
+// Peek returns the top element without removing it.
+// False is returned if the stack is empty.
+func (s *Stack[T]) Peek() (t T, ok bool) {
+	if len(s.data) == 0 {
+		return t, false
+	}
+	return s.data[len(s.data)-1], true
+}
+
+// Clear removes every element from the stack.
+func (s *Stack[T]) Clear() {
+	s.data = nil
+}
+
+// All returns an iterator over the elements from the top of the stack down.
+func (s *Stack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(s.data) - 1; i >= 0; i-- {
+			if !yield(s.data[i]) {
+				return
+			}
+		}
+	}
 }
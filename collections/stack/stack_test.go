@@ -1,7 +1,11 @@
 package stack_test
 
 import (
+	"iter"
 	"practice/collections/stack"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -28,3 +32,155 @@ func TestStacks(t *testing.T) {
 		t.Error("Expected 0")
 	}
 }
+
+// lifo is the API shared by every stack implementation.
+type lifo interface {
+	Push(v int)
+	Pop() int
+	TryPop() (int, bool)
+	Peek() (int, bool)
+	Len() int
+	Clear()
+	All() iter.Seq[int]
+}
+
+func stacks() map[string]func() lifo {
+	return map[string]func() lifo{
+		"Stack":        func() lifo { return stack.New[int]() },
+		"SyncStack":    func() lifo { return stack.NewSync[int]() },
+		"TreiberStack": func() lifo { return stack.NewTreiber[int]() },
+	}
+}
+
+func TestStackAPI(t *testing.T) {
+	for name, newStack := range stacks() {
+		t.Run(name, func(t *testing.T) {
+			s := newStack()
+			if _, ok := s.TryPop(); ok {
+				t.Errorf("TryPop() on an empty stack returned ok")
+			}
+			if _, ok := s.Peek(); ok {
+				t.Errorf("Peek() on an empty stack returned ok")
+			}
+
+			s.Push(0)
+			s.Push(1)
+			s.Push(2)
+
+			if v, ok := s.Peek(); !ok || v != 2 || s.Len() != 3 {
+				t.Errorf("Peek() = %v, %v with Len() %v, want 2, true with Len() 3", v, ok, s.Len())
+			}
+
+			var got []int
+			for v := range s.All() {
+				got = append(got, v)
+			}
+			if !reflect.DeepEqual(got, []int{2, 1, 0}) {
+				t.Errorf("All() = %v, want %v", got, []int{2, 1, 0})
+			}
+
+			s.TryPop()
+			s.TryPop()
+			// A stored zero value is distinguishable from an empty stack.
+			if v, ok := s.TryPop(); !ok || v != 0 {
+				t.Errorf("TryPop() = %v, %v, want 0, true", v, ok)
+			}
+
+			s.Push(1)
+			s.Clear()
+			if s.Len() != 0 {
+				t.Errorf("Len() after Clear = %v, want 0", s.Len())
+			}
+		})
+	}
+}
+
+func TestStackIterationStopsEarly(t *testing.T) {
+	s := stack.New[int]()
+	for i := 0; i < 10; i++ {
+		s.Push(i)
+	}
+
+	var got []int
+	for v := range s.All() {
+		if v < 7 {
+			break
+		}
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, []int{9, 8, 7}) {
+		t.Errorf("got %v, want %v", got, []int{9, 8, 7})
+	}
+}
+
+// Property: the concurrent stacks lose and duplicate nothing under concurrent pushes and pops
+func TestConcurrentStacks(t *testing.T) {
+	for _, name := range []string{"SyncStack", "TreiberStack"} {
+		t.Run(name, func(t *testing.T) {
+			const workers, perWorker = 8, 1000
+			s := stacks()[name]()
+			seen := make([]atomic.Int32, workers*perWorker)
+
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < perWorker; i++ {
+						s.Push(w*perWorker + i)
+						if v, ok := s.TryPop(); ok {
+							seen[v].Add(1)
+						}
+					}
+				}(w)
+			}
+			wg.Wait()
+
+			for v, ok := s.TryPop(); ok; v, ok = s.TryPop() {
+				seen[v].Add(1)
+			}
+			for v := range seen {
+				if n := seen[v].Load(); n != 1 {
+					t.Fatalf("item %v was popped %v times, want 1", v, n)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentStacksClearWhilePushing(t *testing.T) {
+	for _, name := range []string{"SyncStack", "TreiberStack"} {
+		t.Run(name, func(t *testing.T) {
+			s := stacks()[name]()
+			stop := make(chan struct{})
+
+			var wg sync.WaitGroup
+			for w := 0; w < 4; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						select {
+						case <-stop:
+							return
+						default:
+							s.Push(1)
+						}
+					}
+				}()
+			}
+
+			// Clear returns however fast the producers push
+			for i := 0; i < 100; i++ {
+				s.Clear()
+			}
+			close(stop)
+			wg.Wait()
+
+			s.Clear()
+			if s.Len() != 0 {
+				t.Errorf("Len() after Clear = %v, want 0", s.Len())
+			}
+		})
+	}
+}
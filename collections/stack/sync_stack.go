@@ -0,0 +1,81 @@
+package stack
+
+import (
+	"iter"
+	"sync"
+)
+
+// SyncStack is a Stack that is safe for concurrent use, guarded by a mutex.
+type SyncStack[T any] struct {
+	lock  sync.Mutex
+	stack Stack[T]
+}
+
+// NewSync returns an empty concurrent-safe stack.
+func NewSync[T any]() *SyncStack[T] {
+	return &SyncStack[T]{}
+}
+
+func (s *SyncStack[T]) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stack.Len()
+}
+
+func (s *SyncStack[T]) Push(v T) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stack.Push(v)
+}
+
+// Pop removes and returns the top element, or the zero value if the stack is empty.
+func (s *SyncStack[T]) Pop() T {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stack.Pop()
+}
+
+// TryPop removes and returns the top element.
+// False is returned if the stack is empty.
+func (s *SyncStack[T]) TryPop() (T, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stack.TryPop()
+}
+
+// Peek returns the top element without removing it.
+// False is returned if the stack is empty.
+func (s *SyncStack[T]) Peek() (T, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stack.Peek()
+}
+
+// Clear removes every element from the stack.
+func (s *SyncStack[T]) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stack.Clear()
+}
+
+// All returns an iterator over a snapshot of the elements from the top of the stack down.
+// The snapshot is taken when iteration starts, so the loop body may modify the stack.
+func (s *SyncStack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.lock.Lock()
+		snapshot := Stack[T]{data: append([]T(nil), s.stack.data...)}
+		s.lock.Unlock()
+
+		for v := range snapshot.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
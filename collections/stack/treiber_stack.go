@@ -0,0 +1,96 @@
+package stack
+
+import (
+	"iter"
+	"sync/atomic"
+)
+
+type treiberNode[T any] struct {
+	value T
+	next  *treiberNode[T]
+}
+
+// TreiberStack is a lock-free stack that is safe for concurrent use.
+// It is a linked list whose top is only ever replaced with compare-and-swap (R. K. Treiber, 1986).
+// Nodes are never reused, so the garbage collector rules out the ABA problem.
+type TreiberStack[T any] struct {
+	top  atomic.Pointer[treiberNode[T]]
+	size atomic.Int64
+}
+
+// NewTreiber returns an empty lock-free stack.
+func NewTreiber[T any]() *TreiberStack[T] {
+	return &TreiberStack[T]{}
+}
+
+// Len returns the number of elements in the stack. It is only a snapshot under concurrent use.
+func (s *TreiberStack[T]) Len() int {
+	if n := s.size.Load(); n > 0 {
+		return int(n)
+	}
+	return 0
+}
+
+func (s *TreiberStack[T]) Push(v T) {
+	n := &treiberNode[T]{value: v}
+	for {
+		n.next = s.top.Load()
+		if s.top.CompareAndSwap(n.next, n) {
+			s.size.Add(1)
+			return
+		}
+	}
+}
+
+// Pop removes and returns the top element, or the zero value if the stack is empty.
+func (s *TreiberStack[T]) Pop() T {
+	v, _ := s.TryPop()
+	return v
+}
+
+// TryPop removes and returns the top element.
+// False is returned if the stack is empty.
+func (s *TreiberStack[T]) TryPop() (t T, ok bool) {
+	for {
+		top := s.top.Load()
+		if top == nil {
+			return t, false
+		}
+		if s.top.CompareAndSwap(top, top.next) {
+			s.size.Add(-1)
+			return top.value, true
+		}
+	}
+}
+
+// Peek returns the top element without removing it.
+// False is returned if the stack is empty.
+func (s *TreiberStack[T]) Peek() (t T, ok bool) {
+	top := s.top.Load()
+	if top == nil {
+		return t, false
+	}
+	return top.value, true
+}
+
+// Clear removes every element from the stack.
+// It detaches the whole list at once, so it returns even while other goroutines keep pushing.
+func (s *TreiberStack[T]) Clear() {
+	var n int64
+	for top := s.top.Swap(nil); top != nil; top = top.next {
+		n++
+	}
+	s.size.Add(-n)
+}
+
+// All returns an iterator over the elements from the top of the stack down.
+// Nodes are immutable, so it walks a consistent snapshot taken when iteration starts.
+func (s *TreiberStack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := s.top.Load(); n != nil; n = n.next {
+			if !yield(n.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package tree
+
+import (
+	"cmp"
+	"iter"
+	"practice/collections"
+)
+
+// AVLTree is an OrderedMap kept balanced by requiring the heights of every node's subtrees to differ by at most one.
+// It is more rigidly balanced than a RedBlackTree, which makes searches slightly faster and updates slightly slower.
+// Every node also records the size of its subtree, so Rank and Select run in O(log n).
+type AVLTree[K cmp.Ordered, V any] struct {
+	root *avlNode[K, V]
+}
+
+type avlNode[K cmp.Ordered, V any] struct {
+	key         K
+	value       V
+	left, right *avlNode[K, V]
+	height      int
+	size        int
+}
+
+func NewAVLTree[K cmp.Ordered, V any]() *AVLTree[K, V] {
+	return &AVLTree[K, V]{}
+}
+
+func (t *AVLTree[K, V]) Size() int {
+	return t.root.count()
+}
+
+func (t *AVLTree[K, V]) IsEmpty() bool {
+	return t.root == nil
+}
+
+// Height returns the height of the tree.
+func (t *AVLTree[K, V]) Height() int {
+	return t.root.depth()
+}
+
+func (t *AVLTree[K, V]) Search(key K) (*V, bool) {
+	for x := t.root; x != nil; {
+		switch {
+		case key < x.key:
+			x = x.left
+		case key > x.key:
+			x = x.right
+		default:
+			return &x.value, true
+		}
+	}
+	return nil, false
+}
+
+func (t *AVLTree[K, V]) Insert(key K, value V) bool {
+	var inserted bool
+	t.root = t.root.insert(key, value, &inserted)
+	return inserted
+}
+
+func (t *AVLTree[K, V]) Delete(key K) bool {
+	var deleted bool
+	t.root = t.root.delete(key, &deleted)
+	return deleted
+}
+
+func (t *AVLTree[K, V]) Minimum() (*K, *V) {
+	return t.root.minimum().entry()
+}
+
+func (t *AVLTree[K, V]) Maximum() (*K, *V) {
+	x := t.root
+	for x != nil && x.right != nil {
+		x = x.right
+	}
+	return x.entry()
+}
+
+func (t *AVLTree[K, V]) Floor(key K) (*K, *V) {
+	var floor *avlNode[K, V]
+	for x := t.root; x != nil; {
+		switch {
+		case key < x.key:
+			x = x.left
+		case key > x.key:
+			floor, x = x, x.right
+		default:
+			return x.entry()
+		}
+	}
+	return floor.entry()
+}
+
+func (t *AVLTree[K, V]) Ceiling(key K) (*K, *V) {
+	var ceiling *avlNode[K, V]
+	for x := t.root; x != nil; {
+		switch {
+		case key < x.key:
+			ceiling, x = x, x.left
+		case key > x.key:
+			x = x.right
+		default:
+			return x.entry()
+		}
+	}
+	return ceiling.entry()
+}
+
+func (t *AVLTree[K, V]) Rank(key K) int {
+	rank := 0
+	for x := t.root; x != nil; {
+		if key <= x.key {
+			x = x.left
+		} else {
+			rank += x.left.count() + 1
+			x = x.right
+		}
+	}
+	return rank
+}
+
+func (t *AVLTree[K, V]) Select(i int) (*K, *V) {
+	if i < 0 || i >= t.Size() {
+		return nil, nil
+	}
+
+	x := t.root
+	for {
+		left := x.left.count()
+		switch {
+		case i < left:
+			x = x.left
+		case i > left:
+			i -= left + 1
+			x = x.right
+		default:
+			return x.entry()
+		}
+	}
+}
+
+func (t *AVLTree[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	t.root.each(low, high, func(x *avlNode[K, V]) bool {
+		result = append(result, collections.Pair[K, V]{Key: x.key, Value: x.value})
+		return true
+	})
+	return result
+}
+
+func (t *AVLTree[K, V]) Entries() []collections.Pair[K, V] {
+	result := make([]collections.Pair[K, V], 0, t.Size())
+	for k, v := range t.All() {
+		result = append(result, collections.Pair[K, V]{Key: k, Value: v})
+	}
+	return result
+}
+
+func (t *AVLTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.root.all(yield)
+	}
+}
+
+// all yields the entries of the subtree in ascending order, returning false once yield does.
+func (n *avlNode[K, V]) all(yield func(K, V) bool) bool {
+	return n == nil || n.left.all(yield) && yield(n.key, n.value) && n.right.all(yield)
+}
+
+// each calls fn on the nodes with keys in [low, high] in ascending order until fn returns false.
+func (n *avlNode[K, V]) each(low, high K, fn func(x *avlNode[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.key > low && !n.left.each(low, high, fn) {
+		return false
+	}
+	if n.key >= low && n.key <= high && !fn(n) {
+		return false
+	}
+	if n.key < high {
+		return n.right.each(low, high, fn)
+	}
+	return true
+}
+
+func (n *avlNode[K, V]) insert(key K, value V, inserted *bool) *avlNode[K, V] {
+	if n == nil {
+		*inserted = true
+		return &avlNode[K, V]{key: key, value: value, height: 1, size: 1}
+	}
+
+	switch {
+	case key < n.key:
+		n.left = n.left.insert(key, value, inserted)
+	case key > n.key:
+		n.right = n.right.insert(key, value, inserted)
+	default:
+		return n
+	}
+	return n.rebalance()
+}
+
+func (n *avlNode[K, V]) delete(key K, deleted *bool) *avlNode[K, V] {
+	if n == nil {
+		return nil
+	}
+
+	switch {
+	case key < n.key:
+		n.left = n.left.delete(key, deleted)
+	case key > n.key:
+		n.right = n.right.delete(key, deleted)
+	default:
+		*deleted = true
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+
+		// Replace the node with its successor.
+		successor := n.right.minimum()
+		successor.right = n.right.deleteMinimum()
+		successor.left = n.left
+		n = successor
+	}
+	return n.rebalance()
+}
+
+func (n *avlNode[K, V]) deleteMinimum() *avlNode[K, V] {
+	if n.left == nil {
+		return n.right
+	}
+	n.left = n.left.deleteMinimum()
+	return n.rebalance()
+}
+
+// rebalance restores the AVL property at n after one of its subtrees changed height by at most one,
+// returning the new root of the subtree.
+func (n *avlNode[K, V]) rebalance() *avlNode[K, V] {
+	n.update()
+	switch balance := n.left.depth() - n.right.depth(); {
+	case balance > 1:
+		if n.left.left.depth() < n.left.right.depth() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case balance < -1:
+		if n.right.right.depth() < n.right.left.depth() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+func (n *avlNode[K, V]) rotateLeft() *avlNode[K, V] {
+	y := n.right
+	n.right = y.left
+	y.left = n
+	n.update()
+	y.update()
+	return y
+}
+
+func (n *avlNode[K, V]) rotateRight() *avlNode[K, V] {
+	y := n.left
+	n.left = y.right
+	y.right = n
+	n.update()
+	y.update()
+	return y
+}
+
+func (n *avlNode[K, V]) update() {
+	n.height = max(n.left.depth(), n.right.depth()) + 1
+	n.size = n.left.count() + n.right.count() + 1
+}
+
+func (n *avlNode[K, V]) depth() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *avlNode[K, V]) count() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *avlNode[K, V]) minimum() *avlNode[K, V] {
+	for n != nil && n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func (n *avlNode[K, V]) entry() (*K, *V) {
+	if n == nil {
+		return nil, nil
+	}
+	return &n.key, &n.value
+}
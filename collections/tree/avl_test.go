@@ -0,0 +1,56 @@
+package tree
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// balanced reports whether every node of the subtree has correct heights and sizes,
+// and subtrees whose heights differ by at most one.
+func (n *avlNode[K, V]) balanced() bool {
+	if n == nil {
+		return true
+	}
+	if diff := n.left.depth() - n.right.depth(); diff < -1 || diff > 1 {
+		return false
+	}
+	return n.height == max(n.left.depth(), n.right.depth())+1 &&
+		n.size == n.left.count()+n.right.count()+1 &&
+		n.left.balanced() && n.right.balanced()
+}
+
+// Property: the tree stays balanced through any sequence of inserts and deletes
+func TestAVLTree_StaysBalanced(t *testing.T) {
+	t.Parallel()
+	f := func(ops []int16) bool {
+		tree := NewAVLTree[int16, struct{}]()
+		for _, op := range ops {
+			if op < 0 {
+				tree.Delete(-op)
+			} else {
+				tree.Insert(op, struct{}{})
+			}
+			if !tree.root.balanced() {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAVLTree_Height(t *testing.T) {
+	t.Parallel()
+	tree := NewAVLTree[int, int]()
+	for i := 0; i < 1<<12; i++ {
+		tree.Insert(i, i)
+	}
+
+	// An AVL tree with n nodes is at most about 1.44 log2(n) high.
+	if tree.Height() > 18 {
+		t.Errorf("Height() = %v, want at most %v", tree.Height(), 18)
+	}
+}
@@ -0,0 +1,414 @@
+package tree
+
+import (
+	"cmp"
+	"iter"
+	"practice/collections"
+	"slices"
+)
+
+// BTree is an OrderedMap that stores many sorted entries per node, so it stays very shallow
+// and makes good use of CPU caches. Its implementation follows 'Introduction to Algorithms' by Cormen et al.:
+// full nodes are split on the way down during inserts, and nodes are refilled on the way down during deletes,
+// so neither ever has to walk back up the tree.
+// Every node also records the number of entries in its subtree, so Rank and Select run in O(log n).
+type BTree[K cmp.Ordered, V any] struct {
+	root *bTreeNode[K, V]
+	// degree is the minimum degree: every node except the root holds between degree-1 and 2*degree-1 entries.
+	degree int
+}
+
+type bTreeNode[K cmp.Ordered, V any] struct {
+	entries []collections.Pair[K, V]
+	// children is empty for leaves and holds len(entries)+1 subtrees otherwise.
+	children []*bTreeNode[K, V]
+	size     int
+}
+
+// NewBTree returns an empty B-tree with the given minimum degree.
+func NewBTree[K cmp.Ordered, V any](degree int) *BTree[K, V] {
+	if degree < 2 {
+		panic("tree: B-tree degree must be at least 2")
+	}
+
+	return &BTree[K, V]{
+		root:   &bTreeNode[K, V]{},
+		degree: degree,
+	}
+}
+
+func (t *BTree[K, V]) Size() int {
+	return t.root.size
+}
+
+func (t *BTree[K, V]) IsEmpty() bool {
+	return t.Size() == 0
+}
+
+func (t *BTree[K, V]) Search(key K) (*V, bool) {
+	x := t.root
+	for {
+		i, found := x.find(key)
+		if found {
+			return &x.entries[i].Value, true
+		}
+		if x.leaf() {
+			return nil, false
+		}
+		x = x.children[i]
+	}
+}
+
+func (t *BTree[K, V]) Insert(key K, value V) bool {
+	if _, found := t.Search(key); found {
+		return false
+	}
+
+	if len(t.root.entries) == 2*t.degree-1 {
+		root := &bTreeNode[K, V]{
+			children: []*bTreeNode[K, V]{t.root},
+			size:     t.root.size,
+		}
+		t.splitChild(root, 0)
+		t.root = root
+	}
+
+	x := t.root
+	for {
+		x.size++
+		i, _ := x.find(key)
+		if x.leaf() {
+			x.entries = slices.Insert(x.entries, i, collections.Pair[K, V]{Key: key, Value: value})
+			return true
+		}
+
+		if len(x.children[i].entries) == 2*t.degree-1 {
+			t.splitChild(x, i)
+			if key > x.entries[i].Key {
+				i++
+			}
+		}
+		x = x.children[i]
+	}
+}
+
+// splitChild splits the full child i of x in two around its median entry, which moves up into x.
+func (t *BTree[K, V]) splitChild(x *bTreeNode[K, V], i int) {
+	y := x.children[i]
+	median := y.entries[t.degree-1]
+
+	z := &bTreeNode[K, V]{entries: slices.Clone(y.entries[t.degree:])}
+	clear(y.entries[t.degree-1:])
+	y.entries = y.entries[:t.degree-1]
+	if !y.leaf() {
+		z.children = slices.Clone(y.children[t.degree:])
+		clear(y.children[t.degree:])
+		y.children = y.children[:t.degree]
+	}
+	z.resize()
+	y.size -= z.size + 1
+
+	x.entries = slices.Insert(x.entries, i, median)
+	x.children = slices.Insert(x.children, i+1, z)
+}
+
+func (t *BTree[K, V]) Delete(key K) bool {
+	if _, found := t.Search(key); !found {
+		return false
+	}
+
+	t.delete(t.root, key)
+	if len(t.root.entries) == 0 && !t.root.leaf() {
+		// The root's last entry was merged into its only child.
+		t.root = t.root.children[0]
+	}
+	return true
+}
+
+// delete removes key, which must be present, from the subtree rooted at x.
+// x must hold at least degree entries unless it is the root.
+func (t *BTree[K, V]) delete(x *bTreeNode[K, V], key K) {
+	for {
+		x.size--
+		i, found := x.find(key)
+
+		if found && x.leaf() {
+			x.entries = slices.Delete(x.entries, i, i+1)
+			return
+		}
+
+		if found {
+			// Replace the entry with its predecessor or successor from a child that can spare one,
+			// or merge both children around it and carry on deleting from the merged node.
+			if left := x.children[i]; len(left.entries) >= t.degree {
+				predecessor := left.maximum()
+				x.entries[i] = predecessor
+				x, key = left, predecessor.Key
+			} else if right := x.children[i+1]; len(right.entries) >= t.degree {
+				successor := right.minimum()
+				x.entries[i] = successor
+				x, key = right, successor.Key
+			} else {
+				x = t.merge(x, i)
+			}
+			continue
+		}
+
+		// Make sure the child we descend into can lose an entry.
+		if len(x.children[i].entries) == t.degree-1 {
+			switch {
+			case i > 0 && len(x.children[i-1].entries) >= t.degree:
+				t.rotateRight(x, i-1)
+			case i < len(x.children)-1 && len(x.children[i+1].entries) >= t.degree:
+				t.rotateLeft(x, i)
+			case i < len(x.children)-1:
+				t.merge(x, i)
+			default:
+				i--
+				t.merge(x, i)
+			}
+		}
+		x = x.children[i]
+	}
+}
+
+// merge combines child i of x, entry i of x and child i+1 of x into child i, which it returns.
+func (t *BTree[K, V]) merge(x *bTreeNode[K, V], i int) *bTreeNode[K, V] {
+	left, right := x.children[i], x.children[i+1]
+	left.entries = append(append(left.entries, x.entries[i]), right.entries...)
+	left.children = append(left.children, right.children...)
+	left.size += right.size + 1
+
+	x.entries = slices.Delete(x.entries, i, i+1)
+	x.children = slices.Delete(x.children, i+1, i+2)
+	return left
+}
+
+// rotateRight moves entry i of x down into child i+1 and replaces it with the last entry of child i.
+func (t *BTree[K, V]) rotateRight(x *bTreeNode[K, V], i int) {
+	left, right := x.children[i], x.children[i+1]
+	right.entries = slices.Insert(right.entries, 0, x.entries[i])
+	x.entries[i] = left.entries[len(left.entries)-1]
+	left.entries = left.entries[:len(left.entries)-1]
+
+	moved := 1
+	if !left.leaf() {
+		child := left.children[len(left.children)-1]
+		left.children = left.children[:len(left.children)-1]
+		right.children = slices.Insert(right.children, 0, child)
+		moved += child.size
+	}
+	left.size -= moved
+	right.size += moved
+}
+
+// rotateLeft moves entry i of x down into child i and replaces it with the first entry of child i+1.
+func (t *BTree[K, V]) rotateLeft(x *bTreeNode[K, V], i int) {
+	left, right := x.children[i], x.children[i+1]
+	left.entries = append(left.entries, x.entries[i])
+	x.entries[i] = right.entries[0]
+	right.entries = slices.Delete(right.entries, 0, 1)
+
+	moved := 1
+	if !right.leaf() {
+		child := right.children[0]
+		right.children = slices.Delete(right.children, 0, 1)
+		left.children = append(left.children, child)
+		moved += child.size
+	}
+	left.size += moved
+	right.size -= moved
+}
+
+func (t *BTree[K, V]) Minimum() (*K, *V) {
+	if t.IsEmpty() {
+		return nil, nil
+	}
+
+	x := t.root
+	for !x.leaf() {
+		x = x.children[0]
+	}
+	return &x.entries[0].Key, &x.entries[0].Value
+}
+
+func (t *BTree[K, V]) Maximum() (*K, *V) {
+	if t.IsEmpty() {
+		return nil, nil
+	}
+
+	x := t.root
+	for !x.leaf() {
+		x = x.children[len(x.children)-1]
+	}
+	last := &x.entries[len(x.entries)-1]
+	return &last.Key, &last.Value
+}
+
+func (t *BTree[K, V]) Floor(key K) (*K, *V) {
+	var floor *collections.Pair[K, V]
+	x := t.root
+	for {
+		i, found := x.find(key)
+		if found {
+			return &x.entries[i].Key, &x.entries[i].Value
+		}
+		if i > 0 {
+			floor = &x.entries[i-1]
+		}
+		if x.leaf() {
+			break
+		}
+		x = x.children[i]
+	}
+	if floor == nil {
+		return nil, nil
+	}
+	return &floor.Key, &floor.Value
+}
+
+func (t *BTree[K, V]) Ceiling(key K) (*K, *V) {
+	var ceiling *collections.Pair[K, V]
+	x := t.root
+	for {
+		i, found := x.find(key)
+		if found {
+			return &x.entries[i].Key, &x.entries[i].Value
+		}
+		if i < len(x.entries) {
+			ceiling = &x.entries[i]
+		}
+		if x.leaf() {
+			break
+		}
+		x = x.children[i]
+	}
+	if ceiling == nil {
+		return nil, nil
+	}
+	return &ceiling.Key, &ceiling.Value
+}
+
+func (t *BTree[K, V]) Rank(key K) int {
+	rank := 0
+	x := t.root
+	for {
+		i, found := x.find(key)
+		rank += i
+		if x.leaf() {
+			return rank
+		}
+		for _, child := range x.children[:i] {
+			rank += child.size
+		}
+		if found {
+			return rank + x.children[i].size
+		}
+		x = x.children[i]
+	}
+}
+
+func (t *BTree[K, V]) Select(i int) (*K, *V) {
+	if i < 0 || i >= t.Size() {
+		return nil, nil
+	}
+
+	x := t.root
+	for !x.leaf() {
+		j := 0
+		for ; i >= x.children[j].size; j++ {
+			i -= x.children[j].size
+			if i == 0 {
+				return &x.entries[j].Key, &x.entries[j].Value
+			}
+			i--
+		}
+		x = x.children[j]
+	}
+	return &x.entries[i].Key, &x.entries[i].Value
+}
+
+func (t *BTree[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	t.root.each(low, high, func(p collections.Pair[K, V]) bool {
+		result = append(result, p)
+		return true
+	})
+	return result
+}
+
+func (t *BTree[K, V]) Entries() []collections.Pair[K, V] {
+	result := make([]collections.Pair[K, V], 0, t.Size())
+	for k, v := range t.All() {
+		result = append(result, collections.Pair[K, V]{Key: k, Value: v})
+	}
+	return result
+}
+
+func (t *BTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.root.all(yield)
+	}
+}
+
+// find returns the index of the first entry whose key is not less than key, and whether that entry holds key.
+func (n *bTreeNode[K, V]) find(key K) (int, bool) {
+	return slices.BinarySearchFunc(n.entries, key, func(p collections.Pair[K, V], key K) int {
+		return cmp.Compare(p.Key, key)
+	})
+}
+
+func (n *bTreeNode[K, V]) leaf() bool {
+	return len(n.children) == 0
+}
+
+// resize recomputes the size of n from its entries and children.
+func (n *bTreeNode[K, V]) resize() {
+	n.size = len(n.entries)
+	for _, child := range n.children {
+		n.size += child.size
+	}
+}
+
+func (n *bTreeNode[K, V]) minimum() collections.Pair[K, V] {
+	for !n.leaf() {
+		n = n.children[0]
+	}
+	return n.entries[0]
+}
+
+func (n *bTreeNode[K, V]) maximum() collections.Pair[K, V] {
+	for !n.leaf() {
+		n = n.children[len(n.children)-1]
+	}
+	return n.entries[len(n.entries)-1]
+}
+
+// each calls fn on the entries with keys in [low, high] in ascending order until fn returns false.
+func (n *bTreeNode[K, V]) each(low, high K, fn func(p collections.Pair[K, V]) bool) bool {
+	i, _ := n.find(low)
+	for ; ; i++ {
+		if !n.leaf() && !n.children[i].each(low, high, fn) {
+			return false
+		}
+		if i == len(n.entries) || n.entries[i].Key > high {
+			return true
+		}
+		if !fn(n.entries[i]) {
+			return false
+		}
+	}
+}
+
+// all yields the entries of the subtree in ascending order, returning false once yield does.
+func (n *bTreeNode[K, V]) all(yield func(K, V) bool) bool {
+	for i, p := range n.entries {
+		if !n.leaf() && !n.children[i].all(yield) {
+			return false
+		}
+		if !yield(p.Key, p.Value) {
+			return false
+		}
+	}
+	return n.leaf() || n.children[len(n.children)-1].all(yield)
+}
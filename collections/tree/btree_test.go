@@ -0,0 +1,69 @@
+package tree
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// valid reports whether every node of the subtree below the root holds between degree-1 and 2*degree-1 entries,
+// internal nodes have one more child than entries, sizes are correct and all leaves are at the same depth.
+func (n *bTreeNode[K, V]) valid(degree int, root bool, depth int, leafDepth *int) bool {
+	if len(n.entries) > 2*degree-1 || !root && len(n.entries) < degree-1 {
+		return false
+	}
+
+	size := len(n.entries)
+	if n.leaf() {
+		if *leafDepth == -1 {
+			*leafDepth = depth
+		}
+		return n.size == size && depth == *leafDepth
+	}
+
+	if len(n.children) != len(n.entries)+1 {
+		return false
+	}
+	for _, child := range n.children {
+		if !child.valid(degree, false, depth+1, leafDepth) {
+			return false
+		}
+		size += child.size
+	}
+	return n.size == size
+}
+
+// Property: every node stays within its bounds through any sequence of inserts and deletes
+func TestBTree_NodesStayValid(t *testing.T) {
+	t.Parallel()
+	for _, degree := range []int{2, 3, 5} {
+		f := func(ops []int16) bool {
+			tree := NewBTree[int16, struct{}](degree)
+			for _, op := range ops {
+				if op < 0 {
+					tree.Delete(-op % 256)
+				} else {
+					tree.Insert(op%256, struct{}{})
+				}
+				leafDepth := -1
+				if !tree.root.valid(degree, true, 0, &leafDepth) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+			t.Errorf("degree %v: %v", degree, err)
+		}
+	}
+}
+
+func TestNewBTree_PanicsOnSmallDegree(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewBTree(1) did not panic")
+		}
+	}()
+	NewBTree[int, int](1)
+}
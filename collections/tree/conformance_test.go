@@ -0,0 +1,370 @@
+package tree
+
+import (
+	"cmp"
+	"iter"
+	"math/rand"
+	"practice/collections"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+// orderedMap is the surface shared by every ordered map in this package, including the skip lists
+// that do not implement all of OrderedMap. The conformance suite checks the rest of OrderedMap
+// on the implementations that have it.
+type orderedMap[K, V any] interface {
+	Insert(key K, value V) bool
+	Search(key K) (*V, bool)
+	Delete(key K) bool
+	Minimum() (*K, *V)
+	Maximum() (*K, *V)
+	Range(low K, high K) []collections.Pair[K, V]
+	Entries() []collections.Pair[K, V]
+	Size() int
+	IsEmpty() bool
+}
+
+type conformanceTest struct {
+	name string
+	new  func() orderedMap[int, int]
+}
+
+func implementations() []conformanceTest {
+	return []conformanceTest{
+		{"RedBlackTree", func() orderedMap[int, int] { return NewRedBlackTree[int, int]() }},
+		{"RedBlackTreeFunc", func() orderedMap[int, int] { return NewRedBlackTreeFunc[int, int](cmp.Compare[int]) }},
+		{"AVLTree", func() orderedMap[int, int] { return NewAVLTree[int, int]() }},
+		{"BTree/2", func() orderedMap[int, int] { return NewBTree[int, int](2) }},
+		{"BTree/3", func() orderedMap[int, int] { return NewBTree[int, int](3) }},
+		{"BTree/32", func() orderedMap[int, int] { return NewBTree[int, int](32) }},
+		{"Treap", func() orderedMap[int, int] { return NewTreap[int, int]() }},
+		{"SkipList", func() orderedMap[int, int] { return NewSkipList[int, int]() }},
+		{"LockFreeSkipList", func() orderedMap[int, int] { return NewLockFreeSkipList[int, int]() }},
+	}
+}
+
+// fullImplementations returns the implementations of the whole OrderedMap interface.
+func fullImplementations() []conformanceTest {
+	var result []conformanceTest
+	for _, test := range implementations() {
+		if _, ok := test.new().(OrderedMap[int, int]); ok {
+			result = append(result, test)
+		}
+	}
+	return result
+}
+
+// referenceMap is a deliberately simple OrderedMap backed by a sorted slice,
+// against which the real implementations are checked and benchmarked.
+type referenceMap[K cmp.Ordered, V any] struct {
+	entries []collections.Pair[K, V]
+}
+
+func (m *referenceMap[K, V]) find(key K) (int, bool) {
+	return slices.BinarySearchFunc(m.entries, key, func(p collections.Pair[K, V], key K) int {
+		return cmp.Compare(p.Key, key)
+	})
+}
+
+func (m *referenceMap[K, V]) at(i int) (*K, *V) {
+	if i < 0 || i >= len(m.entries) {
+		return nil, nil
+	}
+	return &m.entries[i].Key, &m.entries[i].Value
+}
+
+func (m *referenceMap[K, V]) Insert(key K, value V) bool {
+	i, found := m.find(key)
+	if !found {
+		m.entries = slices.Insert(m.entries, i, collections.Pair[K, V]{Key: key, Value: value})
+	}
+	return !found
+}
+
+func (m *referenceMap[K, V]) Search(key K) (*V, bool) {
+	i, found := m.find(key)
+	if !found {
+		return nil, false
+	}
+	return &m.entries[i].Value, true
+}
+
+func (m *referenceMap[K, V]) Delete(key K) bool {
+	i, found := m.find(key)
+	if found {
+		m.entries = slices.Delete(m.entries, i, i+1)
+	}
+	return found
+}
+
+func (m *referenceMap[K, V]) Minimum() (*K, *V) { return m.at(0) }
+func (m *referenceMap[K, V]) Maximum() (*K, *V) { return m.at(len(m.entries) - 1) }
+func (m *referenceMap[K, V]) Rank(key K) int    { i, _ := m.find(key); return i }
+func (m *referenceMap[K, V]) Select(i int) (*K, *V) {
+	return m.at(i)
+}
+
+func (m *referenceMap[K, V]) Floor(key K) (*K, *V) {
+	i, found := m.find(key)
+	if found {
+		return m.at(i)
+	}
+	return m.at(i - 1)
+}
+
+func (m *referenceMap[K, V]) Ceiling(key K) (*K, *V) {
+	i, _ := m.find(key)
+	return m.at(i)
+}
+
+func (m *referenceMap[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	for i, _ := m.find(low); i < len(m.entries) && m.entries[i].Key <= high; i++ {
+		result = append(result, m.entries[i])
+	}
+	return result
+}
+
+func (m *referenceMap[K, V]) Entries() []collections.Pair[K, V] {
+	return slices.Clone(m.entries)
+}
+
+func (m *referenceMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, p := range m.entries {
+			if !yield(p.Key, p.Value) {
+				return
+			}
+		}
+	}
+}
+
+func (m *referenceMap[K, V]) Size() int     { return len(m.entries) }
+func (m *referenceMap[K, V]) IsEmpty() bool { return len(m.entries) == 0 }
+
+// agree returns a function reporting whether another result of an OrderedMap query matches (k1, v1),
+// so that both calls can be passed along directly, as in agree(m.Floor(k))(model.Floor(k)).
+func agree(k1 *int, v1 *int) func(k2 *int, v2 *int) bool {
+	return func(k2 *int, v2 *int) bool {
+		if k1 == nil || k2 == nil {
+			return k1 == nil && k2 == nil && v1 == nil && v2 == nil
+		}
+		return *k1 == *k2 && *v1 == *v2
+	}
+}
+
+func TestOrderedMapConformance_Basics(t *testing.T) {
+	t.Parallel()
+	for _, test := range implementations() {
+		t.Run(test.name, func(t *testing.T) {
+			m := test.new()
+			if k, v := m.Minimum(); k != nil || v != nil {
+				t.Errorf("Minimum() of an empty map = %v, %v, want nil, nil", k, v)
+			}
+			if k, v := m.Maximum(); k != nil || v != nil {
+				t.Errorf("Maximum() of an empty map = %v, %v, want nil, nil", k, v)
+			}
+
+			for _, k := range []int{5, 3, 1, 4, 2} {
+				if !m.Insert(k, k*100) {
+					t.Errorf("Insert(%v) = false, want true", k)
+				}
+			}
+			if m.Insert(3, 0) {
+				t.Errorf("Insert() of a duplicate key = true, want false")
+			}
+			if v, ok := m.Search(3); !ok || *v != 300 {
+				t.Errorf("Search(3) = %v, %v, want 300, true", v, ok)
+			}
+
+			if k, v := m.Minimum(); *k != 1 || *v != 100 {
+				t.Errorf("Minimum() = %v, %v, want 1, 100", *k, *v)
+			}
+			if k, v := m.Maximum(); *k != 5 || *v != 500 {
+				t.Errorf("Maximum() = %v, %v, want 5, 500", *k, *v)
+			}
+
+			want := []collections.Pair[int, int]{{Key: 2, Value: 200}, {Key: 3, Value: 300}, {Key: 4, Value: 400}}
+			if got := m.Range(2, 4); !slices.Equal(got, want) {
+				t.Errorf("Range(2, 4) = %v, want %v", got, want)
+			}
+
+			if !m.Delete(5) || m.Delete(5) || m.Size() != 4 {
+				t.Errorf("Delete(5) twice did not return true then false, Size() = %v", m.Size())
+			}
+			if k, _ := m.Maximum(); *k != 4 {
+				t.Errorf("Maximum() after Delete = %v, want 4", *k)
+			}
+		})
+	}
+}
+
+// Property: every ordered map answers every query exactly like the reference model after any sequence of updates.
+// The queries outside orderedMap are only checked on implementations of the whole OrderedMap interface.
+func TestOrderedMapConformance(t *testing.T) {
+	t.Parallel()
+	for _, test := range implementations() {
+		t.Run(test.name, func(t *testing.T) {
+			f := func(ops []int16, seed int64) bool {
+				r := rand.New(rand.NewSource(seed))
+				m, model := test.new(), &referenceMap[int, int]{}
+				full, isFull := m.(OrderedMap[int, int])
+
+				for i, op := range ops {
+					key := int(op) % 128
+					var ok bool
+					if op < 0 {
+						ok = m.Delete(-key) == model.Delete(-key)
+					} else {
+						ok = m.Insert(key, i) == model.Insert(key, i)
+					}
+					if !ok || m.Size() != model.Size() || m.IsEmpty() != model.IsEmpty() {
+						return false
+					}
+
+					// Query keys on and just outside the boundaries of the key space.
+					query := r.Intn(132) - 2
+					v1, ok1 := m.Search(query)
+					v2, ok2 := model.Search(query)
+					if ok1 != ok2 || ok1 && *v1 != *v2 {
+						return false
+					}
+					if !isFull {
+						continue
+					}
+					if !agree(full.Floor(query))(model.Floor(query)) {
+						return false
+					}
+					if !agree(full.Ceiling(query))(model.Ceiling(query)) {
+						return false
+					}
+					if full.Rank(query) != model.Rank(query) {
+						return false
+					}
+					rank := r.Intn(model.Size()+2) - 1
+					if !agree(full.Select(rank))(model.Select(rank)) {
+						return false
+					}
+				}
+
+				if !agree(m.Minimum())(model.Minimum()) {
+					return false
+				}
+				if !agree(m.Maximum())(model.Maximum()) {
+					return false
+				}
+				low := r.Intn(140) - 5
+				high := low + r.Intn(60)
+				if !slices.Equal(m.Range(low, high), model.Range(low, high)) {
+					return false
+				}
+				if !slices.Equal(m.Range(high, low), model.Range(high, low)) {
+					return false
+				}
+				return slices.Equal(m.Entries(), model.Entries())
+			}
+
+			if err := quick.Check(f, &quick.Config{MaxCount: 300}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestOrderedMapConformance_AllStopsEarly(t *testing.T) {
+	t.Parallel()
+	for _, test := range fullImplementations() {
+		t.Run(test.name, func(t *testing.T) {
+			m := test.new().(OrderedMap[int, int])
+			for _, k := range rand.New(rand.NewSource(1)).Perm(1000) {
+				m.Insert(k, -k)
+			}
+
+			var keys []int
+			for k, v := range m.All() {
+				if v != -k {
+					t.Fatalf("All() yielded %v, %v", k, v)
+				}
+				if k == 100 {
+					break
+				}
+				keys = append(keys, k)
+			}
+
+			if len(keys) != 100 || !slices.IsSorted(keys) {
+				t.Errorf("All() yielded %v keys before the break, want the 100 smallest in order", len(keys))
+			}
+		})
+	}
+}
+
+func TestOrderedMapConformance_SequentialKeys(t *testing.T) {
+	t.Parallel()
+	for _, test := range fullImplementations() {
+		t.Run(test.name, func(t *testing.T) {
+			m := test.new().(OrderedMap[int, int])
+			for k := 0; k < 10000; k++ {
+				m.Insert(k, k)
+			}
+			for k := 0; k < 10000; k += 2 {
+				m.Delete(k)
+			}
+
+			for i := 0; i < 5000; i++ {
+				if k, _ := m.Select(i); k == nil || *k != 2*i+1 {
+					t.Fatalf("Select(%v) = %v, want %v", i, k, 2*i+1)
+				}
+				if rank := m.Rank(2*i + 1); rank != i {
+					t.Fatalf("Rank(%v) = %v, want %v", 2*i+1, rank, i)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkOrderedMapConformance(b *testing.B) {
+	keys := rand.New(rand.NewSource(1)).Perm(1 << 14)
+	maps := append(implementations(), conformanceTest{
+		"Reference", func() orderedMap[int, int] { return &referenceMap[int, int]{} },
+	})
+
+	for _, test := range maps {
+		b.Run(test.name+"/InsertDelete", func(b *testing.B) {
+			m := test.new()
+			for i := 0; i < b.N; i++ {
+				k := keys[i%len(keys)]
+				if !m.Insert(k, i) {
+					m.Delete(k)
+				}
+			}
+		})
+
+		b.Run(test.name+"/Search", func(b *testing.B) {
+			m := test.new()
+			for _, k := range keys {
+				m.Insert(k, k)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Search(keys[i%len(keys)])
+			}
+		})
+
+		if _, ok := test.new().(OrderedMap[int, int]); !ok {
+			continue
+		}
+		b.Run(test.name+"/FloorRankSelect", func(b *testing.B) {
+			m := test.new().(OrderedMap[int, int])
+			for _, k := range keys {
+				m.Insert(2*k, k)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keys[i%len(keys)]
+				m.Floor(2*k + 1)
+				m.Select(m.Rank(2 * k))
+			}
+		})
+	}
+}
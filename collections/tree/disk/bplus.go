@@ -0,0 +1,958 @@
+// Package disk provides ordered maps that live in files rather than in memory.
+package disk
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"practice/collections"
+	"practice/collections/log"
+	"practice/collections/tree"
+	"slices"
+	"sort"
+)
+
+var (
+	// ErrClosed is returned by operations on a closed tree.
+	ErrClosed = errors.New("disk: tree is closed")
+	// ErrFailed is returned by every operation after a write failed part-way. Reopening the tree recovers
+	// its last committed state.
+	ErrFailed = errors.New("disk: tree failed and must be reopened")
+	// ErrTooLarge is returned by Insert for an entry that would take more than a quarter of a page.
+	ErrTooLarge = errors.New("disk: entry is too large for a page")
+)
+
+const (
+	defaultPageSize       = 4096
+	defaultPoolSize       = 256
+	defaultCheckpointSize = 4 << 20
+
+	journalSuffix = ".wal"
+)
+
+// Config describes how a BPlusTree stores its entries.
+type Config[K, V any] struct {
+	// Keys and Values encode the entries in pages.
+	Keys   Codec[K]
+	Values Codec[V]
+	// Compare orders the keys. It must agree with the order the file was written in.
+	Compare func(a, b K) int
+	// PageSize is the size of a page in bytes, 4096 by default. It cannot change once the file is created.
+	PageSize int
+	// PoolSize is the number of pages the buffer pool caches, 256 by default.
+	PoolSize int
+	// CheckpointSize is the journal size in bytes that triggers a checkpoint, 4 MiB by default.
+	CheckpointSize int
+}
+
+// BPlusTree is an ordered map stored in a file, for data sets that no longer fit in a RedBlackTree.
+// It offers the lookups, updates and iterators of tree.RedBlackTree, but each access may go to disk, so they also
+// return an error. Rank, Select and cursors are left out: nodes do not store the sizes of their subtrees, and a
+// cursor would have to keep pages pinned in the buffer pool between calls.
+//
+// The file is divided into fixed-size pages. Entries live in the leaves, which are linked to their siblings so
+// range scans walk the leaf level without climbing back up the tree. Recently used pages are cached in a buffer pool
+// with LRU eviction. Every Insert or Delete that changes the tree is atomic and durable once it returns:
+// the images of the pages it changed are appended to a journal, a log.FileLog next to the file, and synced
+// before any of them may be written to the file itself. Opening the tree replays the journal, so a crash loses
+// nothing that was committed. A checkpoint writes the cached pages back and empties the journal; it runs
+// whenever the journal outgrows Config.CheckpointSize and when the tree is closed.
+//
+// Pointers returned by a BPlusTree point at copies, so they stay valid when the tree changes.
+// A BPlusTree is not safe for concurrent use.
+type BPlusTree[K, V any] struct {
+	file    *os.File
+	layout  *layout[K, V]
+	pool    *bufferPool[K, V]
+	journal *journal
+	compare func(a, b K) int
+	meta    meta
+
+	checkpointSize uint64
+	// dirty holds the pages changed by the operation in progress, in the order they were first changed.
+	dirty   []*node[K, V]
+	changed map[uint64]bool
+	// version counts committed operations, so iterators can tell when the tree changes under them.
+	version uint64
+	// failed is the error that left the tree unusable.
+	failed error
+	closed bool
+	// err is the error that stopped the last iteration.
+	err error
+}
+
+// Open opens the tree stored at path, creating it if needed, and recovers every operation committed to its journal.
+// It panics if the config is missing a codec or the comparison, or asks for an unsupported page or pool size.
+func Open[K, V any](path string, config Config[K, V]) (*BPlusTree[K, V], error) {
+	if config.Keys == nil || config.Values == nil || config.Compare == nil {
+		panic("disk: config needs Keys, Values and Compare")
+	}
+	pageSize := cmp.Or(config.PageSize, defaultPageSize)
+	if pageSize < minPageSize || pageSize > maxPageSize {
+		panic(fmt.Sprintf("disk: page size must be between %d and %d", minPageSize, maxPageSize))
+	}
+	poolSize := cmp.Or(config.PoolSize, defaultPoolSize)
+	if poolSize < 1 {
+		panic("disk: pool size must be positive")
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &BPlusTree[K, V]{
+		file:           file,
+		layout:         &layout[K, V]{keys: config.Keys, values: config.Values, pageSize: pageSize},
+		compare:        config.Compare,
+		checkpointSize: uint64(cmp.Or(config.CheckpointSize, defaultCheckpointSize)),
+		changed:        make(map[uint64]bool),
+	}
+	if err := t.open(path + journalSuffix); err != nil {
+		if t.journal != nil {
+			t.journal.close()
+		}
+		file.Close()
+		return nil, err
+	}
+
+	t.pool = newBufferPool(file, t.layout, poolSize)
+	return t, nil
+}
+
+// open initializes a new file, replays the journal and reads the meta page.
+func (t *BPlusTree[K, V]) open(journalPath string) error {
+	pageSize := t.layout.pageSize
+	// Check the page size before anything is written, so a wrong config cannot damage the file or the journal
+	if m, err := t.readMeta(); err == nil && m.pageSize != uint64(pageSize) {
+		return fmt.Errorf("disk: the file has %d-byte pages, not %d", m.pageSize, pageSize)
+	}
+
+	info, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+	// The meta page is written first, so a file that was cut short while it was being created is too small
+	if info.Size() < 2*int64(pageSize) {
+		if err := t.create(); err != nil {
+			return err
+		}
+	}
+
+	if t.journal, err = openJournal(journalPath); err != nil {
+		return err
+	}
+	// Either file may have just been created, and is only durable once the directory holds it
+	if err := log.SyncDir(filepath.Dir(journalPath)); err != nil {
+		return err
+	}
+	committed, ok, err := t.journal.replay(pageSize, func(id uint64, page []byte) error {
+		_, err := t.file.WriteAt(page, int64(id)*int64(pageSize))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := t.writeMeta(committed); err != nil {
+			return err
+		}
+	}
+	if t.journal.size > 0 {
+		if err := t.file.Sync(); err != nil {
+			return err
+		}
+		if err := t.journal.reset(); err != nil {
+			return err
+		}
+	}
+
+	if t.meta, err = t.readMeta(); err != nil {
+		return err
+	}
+	if t.meta.pageSize != uint64(pageSize) {
+		return fmt.Errorf("disk: the file has %d-byte pages, not %d", t.meta.pageSize, pageSize)
+	}
+	return nil
+}
+
+func (t *BPlusTree[K, V]) readMeta() (meta, error) {
+	page := make([]byte, metaSize)
+	n, err := t.file.ReadAt(page, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return meta{}, err
+	}
+	return decodeMeta(page[:n])
+}
+
+// create writes an empty tree: the meta page and an empty root leaf.
+func (t *BPlusTree[K, V]) create() error {
+	t.meta = meta{pageSize: uint64(t.layout.pageSize), root: 1, pages: 2}
+	if err := t.writeMeta(t.meta); err != nil {
+		return err
+	}
+
+	root := &node[K, V]{id: t.meta.root, kind: leafPage}
+	if _, err := t.file.WriteAt(t.layout.encode(root), int64(t.layout.pageSize)); err != nil {
+		return err
+	}
+	return t.file.Sync()
+}
+
+func (t *BPlusTree[K, V]) writeMeta(m meta) error {
+	page := make([]byte, t.layout.pageSize)
+	copy(page, m.encode())
+	_, err := t.file.WriteAt(page, 0)
+	return err
+}
+
+// Checkpoint writes every cached change back to the file and empties the journal.
+func (t *BPlusTree[K, V]) Checkpoint() error {
+	if err := t.usable(); err != nil {
+		return err
+	}
+	if err := t.checkpoint(); err != nil {
+		return t.fail(err)
+	}
+	return nil
+}
+
+func (t *BPlusTree[K, V]) checkpoint() error {
+	if t.journal.size == 0 {
+		// Nothing was committed since the last checkpoint
+		return nil
+	}
+	if err := t.pool.flush(); err != nil {
+		return err
+	}
+	if err := t.writeMeta(t.meta); err != nil {
+		return err
+	}
+	if err := t.file.Sync(); err != nil {
+		return err
+	}
+	return t.journal.reset()
+}
+
+// Close checkpoints the tree and closes its files. A tree that failed is closed without a checkpoint,
+// and its journal is replayed when it is reopened.
+func (t *BPlusTree[K, V]) Close() error {
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+
+	var err error
+	if t.failed == nil {
+		err = t.checkpoint()
+	}
+	return errors.Join(err, t.journal.close(), t.file.Close())
+}
+
+func (t *BPlusTree[K, V]) Size() int {
+	return int(t.meta.size)
+}
+
+func (t *BPlusTree[K, V]) IsEmpty() bool {
+	return t.Size() == 0
+}
+
+// Search returns the value stored for key.
+func (t *BPlusTree[K, V]) Search(key K) (*V, bool, error) {
+	var value *V
+	err := t.view(func() error {
+		n, i, err := t.locate(key, false)
+		if err == nil && i < len(n.keys) && t.compare(n.keys[i], key) == 0 {
+			_, value = n.pair(i)
+		}
+		return err
+	})
+	return value, value != nil, err
+}
+
+// Insert inserts a key-value pair into the tree (only if the key does not already exist).
+// True is returned if the key is inserted.
+// False is returned if the key already exists.
+func (t *BPlusTree[K, V]) Insert(key K, value V) (bool, error) {
+	if size, limit := t.layout.entrySize(key, value), t.layout.maxEntry(); size > limit {
+		return false, fmt.Errorf("%w: it takes %d bytes, but at most %d fit", ErrTooLarge, size, limit)
+	}
+
+	return t.update(func() (bool, error) {
+		root, err := t.fetch(t.meta.root)
+		if err != nil {
+			return false, err
+		}
+
+		inserted, right, separator, err := t.insert(root, key, value)
+		if err != nil || !inserted {
+			return false, err
+		}
+		t.meta.size++
+		return true, t.grow(root, right, separator)
+	})
+}
+
+// insert inserts the entry into the subtree rooted at n.
+// If n overflows and splits, its new right sibling is returned with the key that separates them.
+func (t *BPlusTree[K, V]) insert(n *node[K, V], key K, value V) (inserted bool, right *node[K, V], separator K, err error) {
+	if n.leaf() {
+		i := t.search(n.keys, key, false)
+		if i < len(n.keys) && t.compare(n.keys[i], key) == 0 {
+			// Duplicate key, ignore it
+			return false, nil, separator, nil
+		}
+
+		t.modify(n)
+		n.keys = slices.Insert(n.keys, i, key)
+		n.values = slices.Insert(n.values, i, value)
+	} else {
+		i := t.search(n.keys, key, true)
+		child, err := t.fetch(n.children[i])
+		if err != nil {
+			return false, nil, separator, err
+		}
+
+		inserted, right, separator, err := t.insert(child, key, value)
+		if err != nil || right == nil {
+			return inserted, nil, separator, err
+		}
+		t.adopt(n, i, right, separator)
+	}
+
+	right, separator, err = t.overflow(n)
+	return true, right, separator, err
+}
+
+// adopt adds the new right sibling of child i of n, with the key that separates them.
+func (t *BPlusTree[K, V]) adopt(n *node[K, V], i int, right *node[K, V], separator K) {
+	t.modify(n)
+	n.keys = slices.Insert(n.keys, i, separator)
+	n.children = slices.Insert(n.children, i+1, right.id)
+}
+
+// grow adds a level to the tree above the old root if it split.
+func (t *BPlusTree[K, V]) grow(root *node[K, V], right *node[K, V], separator K) error {
+	if right == nil {
+		return nil
+	}
+
+	newRoot, err := t.allocate(internalPage)
+	if err != nil {
+		return err
+	}
+	newRoot.keys = []K{separator}
+	newRoot.children = []uint64{root.id, right.id}
+	t.meta.root = newRoot.id
+	return nil
+}
+
+// overflow splits n if it no longer fits in a page and returns its new right sibling, or nil if it fits.
+func (t *BPlusTree[K, V]) overflow(n *node[K, V]) (*node[K, V], K, error) {
+	if t.layout.fits(n) {
+		var separator K
+		return nil, separator, nil
+	}
+	return t.split(n)
+}
+
+// split moves the upper half of the overfull node n into a new right sibling.
+func (t *BPlusTree[K, V]) split(n *node[K, V]) (*node[K, V], K, error) {
+	var separator K
+	right, err := t.allocate(n.kind)
+	if err != nil {
+		return nil, separator, err
+	}
+
+	if n.leaf() {
+		// Link the new leaf in after n
+		if n.next != 0 {
+			next, err := t.fetch(n.next)
+			if err != nil {
+				return nil, separator, err
+			}
+			t.modify(next)
+			next.prev = right.id
+		}
+		right.prev, right.next = n.id, n.next
+		n.next = right.id
+	}
+
+	return right, t.divide(n, right), nil
+}
+
+// divide moves the entries of left past its byte midpoint to the empty node right and returns the key that
+// separates them. A leaf's separator is copied from the first key of right, while an internal node's moves up
+// to the parent.
+func (t *BPlusTree[K, V]) divide(left, right *node[K, V]) K {
+	sizes := t.layout.entrySizes(left)
+	total := 0
+	for _, s := range sizes {
+		total += s
+	}
+
+	// Keep at least one key on each side
+	last := len(sizes) - 1
+	if !left.leaf() {
+		last--
+	}
+	m, size := 0, 0
+	for m < last && (m == 0 || size+sizes[m] <= total/2) {
+		size += sizes[m]
+		m++
+	}
+
+	separator := left.keys[m]
+	if left.leaf() {
+		right.keys = slices.Clone(left.keys[m:])
+		right.values = slices.Clone(left.values[m:])
+		clear(left.values[m:])
+		left.values = left.values[:m]
+	} else {
+		right.keys = slices.Clone(left.keys[m+1:])
+		right.children = slices.Clone(left.children[m+1:])
+		left.children = left.children[:m+1]
+	}
+	clear(left.keys[m:])
+	left.keys = left.keys[:m]
+
+	return separator
+}
+
+// Delete removes a key from the tree (if it exists).
+// True is returned if the key is deleted.
+// False is returned if the key does not exist.
+func (t *BPlusTree[K, V]) Delete(key K) (bool, error) {
+	return t.update(func() (bool, error) {
+		return t.remove(key)
+	})
+}
+
+// DeleteMin removes the key-value pair with the minimum key and returns it.
+// Nils are returned if the tree is empty.
+func (t *BPlusTree[K, V]) DeleteMin() (*K, *V, error) {
+	return t.deleteEdge(false)
+}
+
+// DeleteMax removes the key-value pair with the maximum key and returns it.
+// Nils are returned if the tree is empty.
+func (t *BPlusTree[K, V]) DeleteMax() (*K, *V, error) {
+	return t.deleteEdge(true)
+}
+
+// deleteEdge removes the entry with the minimum key, or the maximum key if last is set, in a single operation.
+func (t *BPlusTree[K, V]) deleteEdge(last bool) (*K, *V, error) {
+	var key *K
+	var value *V
+	_, err := t.update(func() (bool, error) {
+		n, i, err := t.edge(last)
+		if err != nil || n == nil {
+			return false, err
+		}
+
+		key, value = n.pair(i)
+		return t.remove(*key)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, value, nil
+}
+
+// remove deletes key from the tree as part of the operation in progress.
+func (t *BPlusTree[K, V]) remove(key K) (bool, error) {
+	root, err := t.fetch(t.meta.root)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, right, separator, err := t.delete(root, key)
+	if err != nil || !deleted {
+		return false, err
+	}
+	t.meta.size--
+
+	if !root.leaf() && len(root.keys) == 0 {
+		// The root's last two children merged, so the tree shrinks a level
+		t.meta.root = root.children[0]
+		t.free(root)
+	}
+	return true, t.grow(root, right, separator)
+}
+
+// delete removes key from the subtree rooted at n and rebalances the child it was removed from if it becomes underfull.
+// Rebalancing can replace a separator with a longer key, so like insert it may split n and return its new right sibling.
+func (t *BPlusTree[K, V]) delete(n *node[K, V], key K) (deleted bool, right *node[K, V], separator K, err error) {
+	if n.leaf() {
+		i := t.search(n.keys, key, false)
+		if i == len(n.keys) || t.compare(n.keys[i], key) != 0 {
+			// Not found
+			return false, nil, separator, nil
+		}
+
+		t.modify(n)
+		n.keys = slices.Delete(n.keys, i, i+1)
+		n.values = slices.Delete(n.values, i, i+1)
+		return true, nil, separator, nil
+	}
+
+	i := t.search(n.keys, key, true)
+	child, err := t.fetch(n.children[i])
+	if err != nil {
+		return false, nil, separator, err
+	}
+
+	deleted, right, separator, err = t.delete(child, key)
+	if err != nil || !deleted {
+		return deleted, nil, separator, err
+	}
+	if right != nil {
+		t.adopt(n, i, right, separator)
+	} else if t.layout.underfull(child) {
+		if err := t.rebalance(n, i, child); err != nil {
+			return false, nil, separator, err
+		}
+	}
+
+	right, separator, err = t.overflow(n)
+	return true, right, separator, err
+}
+
+// rebalance merges the underfull child i of parent with a sibling, or shares their entries out evenly
+// if they do not fit in one page.
+func (t *BPlusTree[K, V]) rebalance(parent *node[K, V], i int, child *node[K, V]) error {
+	var left, right *node[K, V]
+	if i+1 < len(parent.children) {
+		sibling, err := t.fetch(parent.children[i+1])
+		if err != nil {
+			return err
+		}
+		left, right = child, sibling
+	} else {
+		// The last child borrows from its left sibling instead
+		i--
+		sibling, err := t.fetch(parent.children[i])
+		if err != nil {
+			return err
+		}
+		left, right = sibling, child
+	}
+
+	// Move everything into left, then split it again if it overflows; parent.keys[i] separates the two
+	t.modify(parent)
+	t.modify(left)
+	t.modify(right)
+	if left.leaf() {
+		left.keys = append(left.keys, right.keys...)
+		left.values = append(left.values, right.values...)
+	} else {
+		left.keys = append(append(left.keys, parent.keys[i]), right.keys...)
+		left.children = append(left.children, right.children...)
+	}
+
+	if !t.layout.fits(left) {
+		right.keys, right.values, right.children = nil, nil, nil
+		parent.keys[i] = t.divide(left, right)
+		return nil
+	}
+
+	if left.leaf() {
+		// Unlink right from the leaf level
+		if right.next != 0 {
+			next, err := t.fetch(right.next)
+			if err != nil {
+				return err
+			}
+			t.modify(next)
+			next.prev = left.id
+		}
+		left.next = right.next
+	}
+	parent.keys = slices.Delete(parent.keys, i, i+1)
+	parent.children = slices.Delete(parent.children, i+1, i+2)
+	t.free(right)
+	return nil
+}
+
+// Minimum returns the key-value pair with the minimum key.
+func (t *BPlusTree[K, V]) Minimum() (*K, *V, error) {
+	return t.find(func() (*node[K, V], int, error) {
+		return t.edge(false)
+	})
+}
+
+// Maximum returns the key-value pair with the maximum key.
+func (t *BPlusTree[K, V]) Maximum() (*K, *V, error) {
+	return t.find(func() (*node[K, V], int, error) {
+		return t.edge(true)
+	})
+}
+
+// Floor returns the key-value pair with the greatest key less than or equal to key.
+func (t *BPlusTree[K, V]) Floor(key K) (*K, *V, error) {
+	return t.find(func() (*node[K, V], int, error) {
+		return t.position(key, true, true)
+	})
+}
+
+// Ceiling returns the key-value pair with the least key greater than or equal to key.
+func (t *BPlusTree[K, V]) Ceiling(key K) (*K, *V, error) {
+	return t.find(func() (*node[K, V], int, error) {
+		return t.position(key, false, false)
+	})
+}
+
+// Predecessor returns the key-value pair with the greatest key strictly less than key.
+func (t *BPlusTree[K, V]) Predecessor(key K) (*K, *V, error) {
+	return t.find(func() (*node[K, V], int, error) {
+		return t.position(key, false, true)
+	})
+}
+
+// Successor returns the key-value pair with the least key strictly greater than key.
+func (t *BPlusTree[K, V]) Successor(key K) (*K, *V, error) {
+	return t.find(func() (*node[K, V], int, error) {
+		return t.position(key, true, false)
+	})
+}
+
+// find returns the entry that position finds, or nils if there is none.
+func (t *BPlusTree[K, V]) find(position func() (*node[K, V], int, error)) (*K, *V, error) {
+	var key *K
+	var value *V
+	err := t.view(func() error {
+		n, i, err := position()
+		if n != nil {
+			key, value = n.pair(i)
+		}
+		return err
+	})
+	return key, value, err
+}
+
+// pair returns copies of the key and value of entry i of a leaf.
+func (n *node[K, V]) pair(i int) (*K, *V) {
+	key, value := n.keys[i], n.values[i]
+	return &key, &value
+}
+
+// Range returns all the key-value pairs whose keys are in the range [low, high].
+func (t *BPlusTree[K, V]) Range(low K, high K) ([]collections.Pair[K, V], error) {
+	var result []collections.Pair[K, V]
+	for key, value := range t.Ascend(low) {
+		if t.compare(key, high) > 0 {
+			break
+		}
+		result = append(result, collections.Pair[K, V]{Key: key, Value: value})
+	}
+	return result, t.Err()
+}
+
+func (t *BPlusTree[K, V]) Entries() ([]collections.Pair[K, V], error) {
+	var result []collections.Pair[K, V]
+	for key, value := range t.All() {
+		result = append(result, collections.Pair[K, V]{Key: key, Value: value})
+	}
+	return result, t.Err()
+}
+
+// All returns an iterator over the key-value pairs in ascending order of key.
+// The iteration stops early if a page cannot be read or the tree is modified; Err reports why.
+func (t *BPlusTree[K, V]) All() iter.Seq2[K, V] {
+	return t.iterate(false, func() (*node[K, V], int, error) {
+		return t.edge(false)
+	})
+}
+
+// Backward returns an iterator over the key-value pairs in descending order of key.
+// The iteration stops early if a page cannot be read or the tree is modified; Err reports why.
+func (t *BPlusTree[K, V]) Backward() iter.Seq2[K, V] {
+	return t.iterate(true, func() (*node[K, V], int, error) {
+		return t.edge(true)
+	})
+}
+
+// Ascend returns an iterator over the key-value pairs with keys greater than or equal to from, in ascending order.
+// The iteration stops early if a page cannot be read or the tree is modified; Err reports why.
+func (t *BPlusTree[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return t.iterate(false, func() (*node[K, V], int, error) {
+		return t.position(from, false, false)
+	})
+}
+
+// Descend returns an iterator over the key-value pairs with keys less than or equal to from, in descending order.
+// The iteration stops early if a page cannot be read or the tree is modified; Err reports why.
+func (t *BPlusTree[K, V]) Descend(from K) iter.Seq2[K, V] {
+	return t.iterate(true, func() (*node[K, V], int, error) {
+		return t.position(from, true, true)
+	})
+}
+
+// Err returns the error that stopped the last iteration over the tree early: tree.ErrConcurrentModification
+// if the tree was modified during it, or the error that prevented a page from being read.
+// It returns nil if the iteration ran to completion or was stopped by its caller.
+func (t *BPlusTree[K, V]) Err() error {
+	return t.err
+}
+
+// iterate yields the entries from the one that start finds along the leaf level.
+// Pages are unpinned before every yield, so a scan does not hold the leaves it has passed in the pool,
+// and the loop body can use the tree.
+func (t *BPlusTree[K, V]) iterate(backward bool, start func() (*node[K, V], int, error)) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if t.err = t.usable(); t.err != nil {
+			return
+		}
+
+		version := t.version
+		n, i, err := start()
+		for {
+			err = t.end(err)
+			if err != nil || n == nil {
+				break
+			}
+			if !yield(n.keys[i], n.values[i]) {
+				return
+			}
+			if t.version != version {
+				err = tree.ErrConcurrentModification
+			} else {
+				err = t.usable()
+			}
+			if err != nil {
+				break
+			}
+
+			if backward {
+				n, i, err = t.backward(n, i-1)
+			} else {
+				n, i, err = t.forward(n, i+1)
+			}
+		}
+		t.err = err
+	}
+}
+
+// locate descends to the leaf where key belongs and returns it with the index of the first key
+// greater than or equal to key, or strictly greater if strict is set.
+func (t *BPlusTree[K, V]) locate(key K, strict bool) (*node[K, V], int, error) {
+	n, err := t.fetch(t.meta.root)
+	for err == nil && !n.leaf() {
+		n, err = t.fetch(n.children[t.search(n.keys, key, true)])
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return n, t.search(n.keys, key, strict), nil
+}
+
+// search returns the index of the first key greater than or equal to key, or strictly greater if strict is set.
+// In an internal node, the strict index is that of the child that covers key.
+func (t *BPlusTree[K, V]) search(keys []K, key K, strict bool) int {
+	return sort.Search(len(keys), func(i int) bool {
+		c := t.compare(keys[i], key)
+		return c > 0 || !strict && c == 0
+	})
+}
+
+// position finds the first entry with a key greater than or equal to key, or strictly greater if strict is set.
+// If backward is set, it finds the last entry before that one instead. A nil leaf means there is no such entry.
+func (t *BPlusTree[K, V]) position(key K, strict, backward bool) (*node[K, V], int, error) {
+	n, i, err := t.locate(key, strict)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if backward {
+		return t.backward(n, i-1)
+	}
+	return t.forward(n, i)
+}
+
+// edge finds the entry with the minimum key, or the maximum key if last is set.
+func (t *BPlusTree[K, V]) edge(last bool) (*node[K, V], int, error) {
+	n, err := t.fetch(t.meta.root)
+	for err == nil && !n.leaf() {
+		child := n.children[0]
+		if last {
+			child = n.children[len(n.children)-1]
+		}
+		n, err = t.fetch(child)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if last {
+		return t.backward(n, len(n.keys)-1)
+	}
+	return t.forward(n, 0)
+}
+
+// forward returns entry i of leaf n, following the sibling links to later leaves if n has fewer entries.
+func (t *BPlusTree[K, V]) forward(n *node[K, V], i int) (*node[K, V], int, error) {
+	for i >= len(n.keys) {
+		if n.next == 0 {
+			return nil, 0, nil
+		}
+
+		var err error
+		if n, err = t.fetch(n.next); err != nil {
+			return nil, 0, err
+		}
+		i = 0
+	}
+	return n, i, nil
+}
+
+// backward returns entry i of leaf n, following the sibling links to earlier leaves if i is negative.
+func (t *BPlusTree[K, V]) backward(n *node[K, V], i int) (*node[K, V], int, error) {
+	for i < 0 {
+		if n.prev == 0 {
+			return nil, 0, nil
+		}
+
+		var err error
+		if n, err = t.fetch(n.prev); err != nil {
+			return nil, 0, err
+		}
+		i = len(n.keys) - 1
+	}
+	return n, i, nil
+}
+
+// usable returns the error that prevents the tree from being used, if any.
+func (t *BPlusTree[K, V]) usable() error {
+	if t.closed {
+		return ErrClosed
+	}
+	if t.failed != nil {
+		return fmt.Errorf("%w: %w", ErrFailed, t.failed)
+	}
+	return nil
+}
+
+// fail marks the tree unusable after err left its cached pages out of step with the file and journal.
+func (t *BPlusTree[K, V]) fail(err error) error {
+	t.failed = err
+	return err
+}
+
+// view runs an operation that reads the tree.
+func (t *BPlusTree[K, V]) view(op func() error) error {
+	if err := t.usable(); err != nil {
+		return err
+	}
+	return t.end(op())
+}
+
+// update runs an operation that may change the tree and commits its changes.
+// If it fails after changing a page, the cached pages no longer match the journal, so the tree fails.
+func (t *BPlusTree[K, V]) update(op func() (bool, error)) (bool, error) {
+	if err := t.usable(); err != nil {
+		return false, err
+	}
+
+	ok, err := op()
+	if err != nil {
+		if len(t.dirty) > 0 {
+			return false, t.fail(err)
+		}
+		return false, t.end(err)
+	}
+
+	if err := t.commit(); err != nil {
+		return false, t.fail(err)
+	}
+	return ok, nil
+}
+
+// end unpins the pages of an operation that changed nothing and returns err.
+func (t *BPlusTree[K, V]) end(err error) error {
+	if releaseErr := t.pool.release(); releaseErr != nil {
+		return t.fail(releaseErr)
+	}
+	return err
+}
+
+// commit journals the pages changed by the operation in progress and the new meta, and makes them durable.
+// Only then are the pages unpinned, which lets the buffer pool write them back to the file.
+func (t *BPlusTree[K, V]) commit() error {
+	if len(t.dirty) == 0 {
+		return t.pool.release()
+	}
+
+	for _, n := range t.dirty {
+		if err := t.journal.appendPage(n.id, t.layout.encode(n)); err != nil {
+			return err
+		}
+	}
+	if err := t.journal.commit(t.meta); err != nil {
+		return err
+	}
+
+	for _, n := range t.dirty {
+		t.pool.markDirty(n.id)
+	}
+	clear(t.dirty)
+	t.dirty = t.dirty[:0]
+	clear(t.changed)
+	t.version++
+
+	if err := t.pool.release(); err != nil {
+		return err
+	}
+	if t.journal.size >= t.checkpointSize {
+		return t.checkpoint()
+	}
+	return nil
+}
+
+// fetch returns page id, pinned until the operation ends.
+func (t *BPlusTree[K, V]) fetch(id uint64) (*node[K, V], error) {
+	return t.pool.get(id)
+}
+
+// modify records that the operation in progress changes n. It must be called before n is changed.
+func (t *BPlusTree[K, V]) modify(n *node[K, V]) {
+	if !t.changed[n.id] {
+		t.changed[n.id] = true
+		t.dirty = append(t.dirty, n)
+	}
+}
+
+// allocate returns an empty node of the given kind, reusing a page from the free list if there is one.
+func (t *BPlusTree[K, V]) allocate(kind pageKind) (*node[K, V], error) {
+	if t.meta.free == 0 {
+		n := &node[K, V]{id: t.meta.pages, kind: kind}
+		t.meta.pages++
+		t.pool.add(n)
+		t.modify(n)
+		return n, nil
+	}
+
+	n, err := t.fetch(t.meta.free)
+	if err != nil {
+		return nil, err
+	}
+	if n.kind != freePage {
+		return nil, fmt.Errorf("disk: page %d is on the free list but in use", n.id)
+	}
+
+	t.modify(n)
+	t.meta.free = n.next
+	*n = node[K, V]{id: n.id, kind: kind}
+	return n, nil
+}
+
+// free puts n on the free list.
+func (t *BPlusTree[K, V]) free(n *node[K, V]) {
+	t.modify(n)
+	*n = node[K, V]{id: n.id, kind: freePage, next: t.meta.free}
+	t.meta.free = n.id
+}
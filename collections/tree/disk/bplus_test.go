@@ -0,0 +1,632 @@
+package disk
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"practice/collections/tree"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// testConfig uses the smallest pages, so a few hundred entries already make a tree several levels deep.
+func testConfig() Config[int, string] {
+	return Config[int, string]{
+		Keys:     IntCodec{},
+		Values:   StringCodec{},
+		Compare:  cmp.Compare[int],
+		PageSize: minPageSize,
+		PoolSize: 8,
+	}
+}
+
+func openTree(t *testing.T, path string, config Config[int, string]) *BPlusTree[int, string] {
+	b, err := Open(path, config)
+	if err != nil {
+		t.Fatalf("cannot open tree: %v", err)
+	}
+	return b
+}
+
+func insertAll(t *testing.T, b *BPlusTree[int, string], keys ...int) {
+	for _, key := range keys {
+		if _, err := b.Insert(key, fmt.Sprint("v", key)); err != nil {
+			t.Fatalf("cannot insert %v: %v", key, err)
+		}
+	}
+}
+
+func keysOf(t *testing.T, b *BPlusTree[int, string]) []int {
+	entries, err := b.Entries()
+	if err != nil {
+		t.Fatalf("cannot read entries: %v", err)
+	}
+
+	var keys []int
+	for _, e := range entries {
+		if e.Value != fmt.Sprint("v", e.Key) {
+			t.Fatalf("key %v has value %q", e.Key, e.Value)
+		}
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
+
+// copyFiles copies the tree's file and journal as they are on disk, which is what a crash would leave behind.
+func copyFiles(t *testing.T, from, to string) {
+	for _, suffix := range []string{"", journalSuffix} {
+		data, err := os.ReadFile(from + suffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(to+suffix, data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// valid checks the structure of the tree: keys are in order and within the bounds set by the separators above them,
+// every leaf is at the same depth and linked to its neighbours, no page but the root is underfull,
+// and every page is either in the tree or on the free list.
+func (b *BPlusTree[K, V]) valid() error {
+	defer b.end(nil)
+
+	var leaves []*node[K, V]
+	var leafDepth int
+	var walk func(id uint64, low, high *K, depth int) (int, error)
+	walk = func(id uint64, low, high *K, depth int) (int, error) {
+		n, err := b.fetch(id)
+		if err != nil {
+			return 0, err
+		}
+		if id != b.meta.root && b.layout.underfull(n) {
+			return 0, fmt.Errorf("page %d is underfull", id)
+		}
+		if !b.layout.fits(n) {
+			return 0, fmt.Errorf("page %d overflows", id)
+		}
+		for i, key := range n.keys {
+			if i > 0 && b.compare(n.keys[i-1], key) >= 0 {
+				return 0, fmt.Errorf("page %d is out of order", id)
+			}
+			if low != nil && b.compare(key, *low) < 0 || high != nil && b.compare(key, *high) >= 0 {
+				return 0, fmt.Errorf("page %d has a key outside its bounds", id)
+			}
+		}
+
+		if n.leaf() {
+			if len(leaves) > 0 && depth != leafDepth {
+				return 0, fmt.Errorf("leaf %d is at depth %d, but leaf %d is at depth %d", id, depth, leaves[0].id, leafDepth)
+			}
+			leaves, leafDepth = append(leaves, n), depth
+			return 1, nil
+		}
+		if len(n.children) != len(n.keys)+1 || len(n.keys) == 0 {
+			return 0, fmt.Errorf("page %d has %d keys and %d children", id, len(n.keys), len(n.children))
+		}
+		pages := 1
+		for i, child := range n.children {
+			childLow, childHigh := low, high
+			if i > 0 {
+				childLow = &n.keys[i-1]
+			}
+			if i < len(n.keys) {
+				childHigh = &n.keys[i]
+			}
+			count, err := walk(child, childLow, childHigh, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			pages += count
+		}
+		return pages, nil
+	}
+
+	pages, err := walk(b.meta.root, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+
+	size := 0
+	for i, leaf := range leaves {
+		size += len(leaf.keys)
+		var prev, next uint64
+		if i > 0 {
+			prev = leaves[i-1].id
+		}
+		if i+1 < len(leaves) {
+			next = leaves[i+1].id
+		}
+		if leaf.prev != prev || leaf.next != next {
+			return fmt.Errorf("leaf %d is linked to %d and %d, want %d and %d", leaf.id, leaf.prev, leaf.next, prev, next)
+		}
+	}
+	if size != b.Size() {
+		return fmt.Errorf("the leaves hold %d entries, but the size is %d", size, b.Size())
+	}
+
+	for id := b.meta.free; id != 0; pages++ {
+		n, err := b.fetch(id)
+		if err != nil {
+			return err
+		}
+		if n.kind != freePage {
+			return fmt.Errorf("page %d is on the free list but in use", id)
+		}
+		id = n.next
+	}
+	if uint64(pages+1) != b.meta.pages {
+		return fmt.Errorf("found %d pages, want %d", pages+1, b.meta.pages)
+	}
+	return nil
+}
+
+func Example() {
+	dir, err := os.MkdirTemp("", "bplus")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := Config[string, int]{Keys: StringCodec{}, Values: IntCodec{}, Compare: cmp.Compare[string]}
+	b, err := Open(filepath.Join(dir, "ages"), config)
+	if err != nil {
+		panic(err)
+	}
+	b.Insert("carol", 41)
+	b.Insert("alice", 34)
+	b.Insert("bob", 27)
+	b.Close()
+
+	b, err = Open(filepath.Join(dir, "ages"), config)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for name, age := range b.All() {
+		fmt.Println(name, age)
+	}
+	// Output:
+	// alice 34
+	// bob 27
+	// carol 41
+}
+
+func TestBPlusTree_Basics(t *testing.T) {
+	t.Parallel()
+	b := openTree(t, filepath.Join(t.TempDir(), "tree"), testConfig())
+	defer b.Close()
+
+	if key, value, err := b.Minimum(); key != nil || value != nil || err != nil {
+		t.Errorf("Minimum() of an empty tree = %v, %v, %v", key, value, err)
+	}
+	if key, value, err := b.DeleteMin(); key != nil || value != nil || err != nil {
+		t.Errorf("DeleteMin() of an empty tree = %v, %v, %v", key, value, err)
+	}
+
+	for i := 0; i < 100; i++ {
+		insertAll(t, b, 2*i)
+	}
+	if ok, err := b.Insert(10, "other"); ok || err != nil {
+		t.Errorf("Insert() of a duplicate = %v, %v, want false", ok, err)
+	}
+	if v, ok, err := b.Search(10); !ok || err != nil || *v != "v10" {
+		t.Errorf("Search(10) = %v, %v, %v", v, ok, err)
+	}
+	if _, ok, _ := b.Search(11); ok {
+		t.Errorf("Search(11) found a missing key")
+	}
+
+	tests := []struct {
+		name string
+		find func(key int) (*int, *string, error)
+		key  int
+		want int
+	}{
+		{"Floor", b.Floor, 11, 10},
+		{"Floor", b.Floor, 10, 10},
+		{"Ceiling", b.Ceiling, 11, 12},
+		{"Ceiling", b.Ceiling, 10, 10},
+		{"Predecessor", b.Predecessor, 10, 8},
+		{"Successor", b.Successor, 10, 12},
+		{"Successor", b.Successor, 198, -1},
+		{"Predecessor", b.Predecessor, 0, -1},
+	}
+	for _, test := range tests {
+		key, _, err := test.find(test.key)
+		if err != nil {
+			t.Fatalf("%s(%v) failed: %v", test.name, test.key, err)
+		}
+		if test.want < 0 && key != nil || test.want >= 0 && (key == nil || *key != test.want) {
+			t.Errorf("%s(%v) = %v, want %v", test.name, test.key, key, test.want)
+		}
+	}
+
+	if key, _, _ := b.Maximum(); key == nil || *key != 198 {
+		t.Errorf("Maximum() = %v, want %v", key, 198)
+	}
+	if ok, err := b.Delete(10); !ok || err != nil {
+		t.Errorf("Delete(10) = %v, %v", ok, err)
+	}
+	if ok, _ := b.Delete(10); ok {
+		t.Errorf("Delete(10) deleted a missing key")
+	}
+	if key, value, err := b.DeleteMin(); key == nil || *key != 0 || *value != "v0" || err != nil {
+		t.Errorf("DeleteMin() = %v, %v, %v, want 0", key, value, err)
+	}
+	if key, value, err := b.DeleteMax(); key == nil || *key != 198 || *value != "v198" || err != nil {
+		t.Errorf("DeleteMax() = %v, %v, %v, want 198", key, value, err)
+	}
+
+	entries, err := b.Range(7, 15)
+	if err != nil || len(entries) != 3 || entries[0].Key != 8 || entries[1].Key != 12 || entries[2].Key != 14 {
+		t.Errorf("Range(7, 15) = %v, %v, want 8, 12 and 14", entries, err)
+	}
+	if b.Size() != 97 {
+		t.Errorf("Size() = %v, want %v", b.Size(), 97)
+	}
+	if err := b.valid(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Property: a BPlusTree behaves like a RedBlackTree, and reopening it restores the same entries.
+func TestBPlusTree_MatchesRedBlackTree(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	count := 0
+
+	f := func(fill uint16, ops []uint16) bool {
+		count++
+		path := filepath.Join(dir, fmt.Sprint(count))
+		b := openTree(t, path, testConfig())
+		model := tree.NewRedBlackTree[int, string]()
+
+		// Start from a tree several levels deep, so the operations split and merge pages at every level
+		for i := range int(fill % 600) {
+			insertAll(t, b, 3*i)
+			model.Insert(3*i, fmt.Sprint("v", 3*i))
+		}
+		for _, op := range ops {
+			key := int(op>>2) % 1800
+			switch op % 5 {
+			case 0:
+				ok, err := b.Insert(key, fmt.Sprint("v", key))
+				if err != nil || ok != model.Insert(key, fmt.Sprint("v", key)) {
+					return false
+				}
+			case 1, 2:
+				ok, err := b.Delete(key)
+				if err != nil || ok != model.Delete(key) {
+					return false
+				}
+			case 3:
+				got, _, err := b.Floor(key)
+				want, _ := model.Floor(key)
+				if err != nil || (got == nil) != (want == nil) || got != nil && *got != *want {
+					return false
+				}
+			case 4:
+				deleteEdge, modelEdge := b.DeleteMin, model.DeleteMin
+				if key%2 == 1 {
+					deleteEdge, modelEdge = b.DeleteMax, model.DeleteMax
+				}
+				got, _, err := deleteEdge()
+				want, _ := modelEdge()
+				if err != nil || (got == nil) != (want == nil) || got != nil && *got != *want {
+					return false
+				}
+			}
+		}
+
+		if err := b.valid(); err != nil {
+			t.Log(err)
+			return false
+		}
+		if err := b.Close(); err != nil {
+			t.Log(err)
+			return false
+		}
+
+		b = openTree(t, path, testConfig())
+		defer b.Close()
+		got := keysOf(t, b)
+		if len(got) != model.Size() || b.Size() != model.Size() {
+			return false
+		}
+		for i, e := range model.Entries() {
+			if got[i] != e.Key {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 30}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBPlusTree_Scans(t *testing.T) {
+	t.Parallel()
+	b := openTree(t, filepath.Join(t.TempDir(), "tree"), testConfig())
+	defer b.Close()
+	for _, i := range rand.New(rand.NewSource(1)).Perm(1000) {
+		insertAll(t, b, i)
+	}
+
+	i := 999
+	for key := range b.Backward() {
+		if key != i {
+			t.Fatalf("Backward() yielded %v, want %v", key, i)
+		}
+		i--
+	}
+	if i != -1 || b.Err() != nil {
+		t.Errorf("Backward() stopped before %v: %v", i, b.Err())
+	}
+
+	i = 500
+	for key := range b.Descend(500) {
+		if key != i {
+			t.Fatalf("Descend(500) yielded %v, want %v", key, i)
+		}
+		i--
+	}
+
+	entries, err := b.Range(100, 899)
+	if err != nil || len(entries) != 800 || entries[0].Key != 100 || entries[799].Key != 899 {
+		t.Errorf("Range(100, 899) returned %v entries: %v", len(entries), err)
+	}
+
+	for key := range b.Ascend(10) {
+		if key == 20 {
+			b.Insert(2000, "v2000")
+		}
+	}
+	if !errors.Is(b.Err(), tree.ErrConcurrentModification) {
+		t.Errorf("Err() after modifying the tree during iteration = %v, want %v", b.Err(), tree.ErrConcurrentModification)
+	}
+}
+
+func TestBPlusTree_SmallPoolEvictsPages(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "tree")
+	config := testConfig()
+	config.PoolSize = 3
+	b := openTree(t, path, config)
+
+	keys := rand.New(rand.NewSource(2)).Perm(2000)
+	for _, key := range keys {
+		insertAll(t, b, key)
+		if len(b.pool.frames) > config.PoolSize {
+			t.Fatalf("the pool holds %v pages, want at most %v", len(b.pool.frames), config.PoolSize)
+		}
+	}
+	for _, key := range keys[:1000] {
+		if ok, err := b.Delete(key); !ok || err != nil {
+			t.Fatalf("Delete(%v) = %v, %v", key, ok, err)
+		}
+	}
+	if err := b.valid(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("cannot close tree: %v", err)
+	}
+
+	b = openTree(t, path, config)
+	defer b.Close()
+	for _, key := range keys[1000:] {
+		if v, ok, err := b.Search(key); !ok || err != nil || *v != fmt.Sprint("v", key) {
+			t.Fatalf("Search(%v) = %v, %v, %v after reopening", key, v, ok, err)
+		}
+	}
+}
+
+// Rebalancing after a delete can replace a separator with a longer key, which has to split the parent
+func TestBPlusTree_VariableLengthKeys(t *testing.T) {
+	t.Parallel()
+	config := Config[string, string]{
+		Keys:     StringCodec{},
+		Values:   StringCodec{},
+		Compare:  cmp.Compare[string],
+		PageSize: minPageSize,
+	}
+	b, err := Open(filepath.Join(t.TempDir(), "tree"), config)
+	if err != nil {
+		t.Fatalf("cannot open tree: %v", err)
+	}
+	defer b.Close()
+
+	r := rand.New(rand.NewSource(3))
+	model := tree.NewRedBlackTree[string, string]()
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprint(r.Intn(1000)) + strings.Repeat("k", r.Intn(110))
+		if i < 1000 || r.Intn(2) == 0 {
+			ok, err := b.Insert(key, strings.Repeat("v", r.Intn(5)))
+			if err != nil || ok != model.Insert(key, "") {
+				t.Fatalf("Insert(%q) = %v, %v", key, ok, err)
+			}
+		} else if e := model.Entries(); len(e) > 0 {
+			key = e[r.Intn(len(e))].Key
+			model.Delete(key)
+			if ok, err := b.Delete(key); !ok || err != nil {
+				t.Fatalf("Delete(%q) = %v, %v", key, ok, err)
+			}
+		}
+	}
+
+	if err := b.valid(); err != nil {
+		t.Fatal(err)
+	}
+	if b.Size() != model.Size() {
+		t.Errorf("Size() = %v, want %v", b.Size(), model.Size())
+	}
+}
+
+func TestBPlusTree_ReusesFreedPages(t *testing.T) {
+	t.Parallel()
+	b := openTree(t, filepath.Join(t.TempDir(), "tree"), testConfig())
+	defer b.Close()
+
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 500; i++ {
+			insertAll(t, b, i)
+		}
+		pages := b.meta.pages
+		for i := 0; i < 500; i++ {
+			if _, err := b.Delete(i); err != nil {
+				t.Fatalf("cannot delete %v: %v", i, err)
+			}
+		}
+		if err := b.valid(); err != nil {
+			t.Fatal(err)
+		}
+		if round > 0 && b.meta.pages != pages {
+			t.Errorf("the file grew to %v pages in round %v, from %v", b.meta.pages, round, pages)
+		}
+	}
+}
+
+func TestBPlusTree_RecoversCommittedOperations(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path, crashed := filepath.Join(dir, "tree"), filepath.Join(dir, "crashed")
+	b := openTree(t, path, testConfig())
+	defer b.Close()
+
+	insertAll(t, b, 0, 1, 2)
+	if err := b.Checkpoint(); err != nil {
+		t.Fatalf("cannot checkpoint: %v", err)
+	}
+	for i := 3; i < 300; i++ {
+		insertAll(t, b, i)
+	}
+	for i := 0; i < 300; i += 3 {
+		if _, err := b.Delete(i); err != nil {
+			t.Fatalf("cannot delete %v: %v", i, err)
+		}
+	}
+	copyFiles(t, path, crashed)
+
+	// Simulate a crash in the middle of committing another operation: an image of the root that was never
+	// committed, which would empty the tree if it were replayed, and a torn record after it.
+	j, err := openJournal(crashed + journalSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := &layout[int, string]{keys: IntCodec{}, values: StringCodec{}, pageSize: minPageSize}
+	if err := j.appendPage(b.meta.root, layout.encode(&node[int, string]{id: b.meta.root, kind: leafPage})); err != nil {
+		t.Fatal(err)
+	}
+	j.close()
+	f, err := os.OpenFile(crashed+journalSuffix, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 0, 0, 0, 0, 9, commitRecord})
+	f.Close()
+
+	recovered := openTree(t, crashed, testConfig())
+	defer recovered.Close()
+	want := keysOf(t, b)
+	got := keysOf(t, recovered)
+	if len(got) != len(want) || len(got) != 200 {
+		t.Fatalf("recovered %v keys, want %v", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("recovered %v, want %v", got, want)
+		}
+	}
+	if err := recovered.valid(); err != nil {
+		t.Error(err)
+	}
+	if info, err := os.Stat(crashed + journalSuffix); err != nil || info.Size() != 0 {
+		t.Errorf("the journal was not emptied after recovery")
+	}
+}
+
+func TestBPlusTree_RecoversOperationsAfterCheckpoint(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path, crashed := filepath.Join(dir, "tree"), filepath.Join(dir, "crashed")
+	b := openTree(t, path, testConfig())
+	defer b.Close()
+
+	before, err := os.Stat(path + journalSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertAll(t, b, 0, 1, 2)
+	if err := b.Checkpoint(); err != nil {
+		t.Fatalf("cannot checkpoint: %v", err)
+	}
+	insertAll(t, b, 3, 4, 5)
+
+	// The checkpoint empties the journal in place, so the operations committed after it are in the same file
+	after, err := os.Stat(path + journalSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(before, after) || after.Size() == 0 {
+		t.Errorf("the journal was replaced or left empty by the checkpoint")
+	}
+
+	copyFiles(t, path, crashed)
+	recovered := openTree(t, crashed, testConfig())
+	defer recovered.Close()
+	if got := keysOf(t, recovered); len(got) != 6 {
+		t.Errorf("recovered %v, want the keys 0 to 5", got)
+	}
+}
+
+func TestBPlusTree_Errors(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "tree")
+	b := openTree(t, path, testConfig())
+
+	if _, err := b.Insert(1, strings.Repeat("x", minPageSize/4)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Insert() of a large entry = %v, want %v", err, ErrTooLarge)
+	}
+	for i := 0; i < 100; i++ {
+		insertAll(t, b, i)
+	}
+	leaf, _, err := b.locate(99, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.end(nil)
+	b.Close()
+	if _, _, err := b.Search(1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Search() after Close = %v, want %v", err, ErrClosed)
+	}
+
+	config := testConfig()
+	config.PageSize = 1024
+	if _, err := Open(path, config); err == nil {
+		t.Errorf("Open() with another page size succeeded")
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteAt([]byte{0xff}, int64(leaf.id)*minPageSize+headerSize)
+	f.Close()
+
+	b = openTree(t, path, testConfig())
+	defer b.Close()
+	if _, _, err := b.Search(99); err == nil {
+		t.Errorf("Search() in a corrupt page succeeded")
+	}
+	if v, ok, err := b.Search(0); !ok || err != nil || *v != "v0" {
+		t.Errorf("Search() in an intact page = %v, %v, %v", v, ok, err)
+	}
+}
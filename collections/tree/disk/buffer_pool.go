@@ -0,0 +1,134 @@
+package disk
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+
+	"practice/collections/list"
+)
+
+// bufferPool caches decoded pages of the data file and evicts the least recently used ones once it holds more than
+// its capacity.
+//
+// Every page fetched during an operation is pinned until the operation releases the pool, so the operation
+// can hold on to the nodes it works with, and its changes cannot reach the data file before they are committed
+// to the journal. Only unpinned pages are evicted, so the pool may briefly grow past its capacity.
+// Dirty pages hold committed changes that are not in the data file yet: they are written back when they are
+// evicted or flushed.
+type bufferPool[K, V any] struct {
+	file     *os.File
+	layout   *layout[K, V]
+	capacity int
+	frames   map[uint64]*list.Node[*frame[K, V]]
+	// lru orders the frames from the most recently used at the front to the least recently used at the back.
+	lru    *list.List[*frame[K, V]]
+	pinned []*frame[K, V]
+}
+
+type frame[K, V any] struct {
+	node   *node[K, V]
+	pinned bool
+	dirty  bool
+}
+
+func newBufferPool[K, V any](file *os.File, layout *layout[K, V], capacity int) *bufferPool[K, V] {
+	return &bufferPool[K, V]{
+		file:     file,
+		layout:   layout,
+		capacity: capacity,
+		frames:   make(map[uint64]*list.Node[*frame[K, V]]),
+		lru:      list.New[*frame[K, V]](),
+	}
+}
+
+// get returns page id, reading it from the data file if it is not cached, and pins it.
+func (p *bufferPool[K, V]) get(id uint64) (*node[K, V], error) {
+	if e, ok := p.frames[id]; ok {
+		p.lru.MoveToFront(e)
+		p.pin(e.Value)
+		return e.Value.node, nil
+	}
+
+	page := make([]byte, p.layout.pageSize)
+	if _, err := p.file.ReadAt(page, int64(id)*int64(p.layout.pageSize)); err != nil {
+		return nil, fmt.Errorf("disk: cannot read page %d: %w", id, err)
+	}
+	n, err := p.layout.decode(id, page)
+	if err != nil {
+		return nil, err
+	}
+
+	p.add(n)
+	return n, nil
+}
+
+// add caches a page that is not in the data file yet and pins it.
+func (p *bufferPool[K, V]) add(n *node[K, V]) {
+	f := &frame[K, V]{node: n}
+	p.frames[n.id] = p.lru.PushFront(f)
+	p.pin(f)
+}
+
+func (p *bufferPool[K, V]) pin(f *frame[K, V]) {
+	if !f.pinned {
+		f.pinned = true
+		p.pinned = append(p.pinned, f)
+	}
+}
+
+// markDirty records that page id holds committed changes that must be written back.
+func (p *bufferPool[K, V]) markDirty(id uint64) {
+	p.frames[id].Value.dirty = true
+}
+
+// release unpins every page and evicts pages until the pool is back within its capacity.
+func (p *bufferPool[K, V]) release() error {
+	for _, f := range p.pinned {
+		f.pinned = false
+	}
+	clear(p.pinned)
+	p.pinned = p.pinned[:0]
+
+	for len(p.frames) > p.capacity {
+		e := p.lru.Back
+		if e.Value.dirty {
+			if err := p.write(e.Value); err != nil {
+				return err
+			}
+		}
+
+		p.lru.Remove(e)
+		delete(p.frames, e.Value.node.id)
+	}
+	return nil
+}
+
+// flush writes every dirty page back to the data file, in page order.
+func (p *bufferPool[K, V]) flush() error {
+	var dirty []*frame[K, V]
+	for _, e := range p.frames {
+		if e.Value.dirty {
+			dirty = append(dirty, e.Value)
+		}
+	}
+	slices.SortFunc(dirty, func(a, b *frame[K, V]) int {
+		return cmp.Compare(a.node.id, b.node.id)
+	})
+
+	for _, f := range dirty {
+		if err := p.write(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *bufferPool[K, V]) write(f *frame[K, V]) error {
+	if _, err := p.file.WriteAt(p.layout.encode(f.node), int64(f.node.id)*int64(p.layout.pageSize)); err != nil {
+		return fmt.Errorf("disk: cannot write page %d: %w", f.node.id, err)
+	}
+	f.dirty = false
+	return nil
+}
@@ -0,0 +1,51 @@
+package disk
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Codec converts keys or values to and from the bytes stored in a page.
+type Codec[T any] interface {
+	// Append appends the encoding of v to buf and returns the extended buffer.
+	Append(buf []byte, v T) []byte
+	// Decode decodes a value from exactly the bytes that Append produced for it.
+	Decode(data []byte) (T, error)
+}
+
+// StringCodec stores strings as their bytes.
+type StringCodec struct{}
+
+func (StringCodec) Append(buf []byte, v string) []byte {
+	return append(buf, v...)
+}
+
+func (StringCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+// BytesCodec stores byte slices as they are. Decoded slices do not alias the page they were read from.
+type BytesCodec struct{}
+
+func (BytesCodec) Append(buf []byte, v []byte) []byte {
+	return append(buf, v...)
+}
+
+func (BytesCodec) Decode(data []byte) ([]byte, error) {
+	return append([]byte(nil), data...), nil
+}
+
+// IntCodec stores ints as zig-zag varints, so small magnitudes take few bytes.
+type IntCodec struct{}
+
+func (IntCodec) Append(buf []byte, v int) []byte {
+	return binary.AppendVarint(buf, int64(v))
+}
+
+func (IntCodec) Decode(data []byte) (int, error) {
+	v, n := binary.Varint(data)
+	if n <= 0 || n != len(data) {
+		return 0, errors.New("disk: malformed int")
+	}
+	return int(v), nil
+}
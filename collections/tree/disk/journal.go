@@ -0,0 +1,128 @@
+package disk
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+
+	"practice/collections/log"
+)
+
+// The journal is a log.FileLog of physical redo records. An operation appends the image of every page it changed,
+// then a commit record holding the new meta, and syncs the log before any of those pages may reach the data file.
+// Page records that are not followed by a commit record belong to an operation that never committed and are ignored.
+const (
+	pageRecord byte = iota + 1
+	commitRecord
+)
+
+type journal struct {
+	path string
+	log  *log.FileLog
+	// size is the length of the log file, which tells when a checkpoint is due.
+	size uint64
+}
+
+func openJournal(path string) (*journal, error) {
+	info, err := os.Stat(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	l, err := log.NewFileLog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &journal{path: path, log: l}
+	if info != nil {
+		j.size = uint64(info.Size())
+	}
+	return j, nil
+}
+
+func (j *journal) appendPage(id uint64, page []byte) error {
+	record := binary.BigEndian.AppendUint64([]byte{pageRecord}, id)
+	return j.append(append(record, page...))
+}
+
+// commit appends a commit record and syncs the log, which makes the pages appended before it durable.
+func (j *journal) commit(m meta) error {
+	if err := j.append(append([]byte{commitRecord}, m.encode()...)); err != nil {
+		return err
+	}
+	return j.log.Sync()
+}
+
+func (j *journal) append(record []byte) error {
+	offset, err := j.log.Append(record)
+	if err != nil {
+		return err
+	}
+	// Records are framed by an 8-byte length and a 4-byte checksum
+	j.size = offset + 8 + uint64(len(record)) + 4
+	return nil
+}
+
+// replay calls apply on the page images of every committed operation, in order, and returns the meta of the last one.
+// False is returned if nothing was committed. Replay stops at the first record that cannot be read,
+// which is where a crash interrupted an append.
+func (j *journal) replay(pageSize int, apply func(id uint64, page []byte) error) (meta, bool, error) {
+	type image struct {
+		id   uint64
+		page []byte
+	}
+
+	var (
+		last      meta
+		committed bool
+		pending   []image
+		offset    uint64
+	)
+	for offset < j.size {
+		record, next, err := j.log.Read(offset)
+		if err != nil || len(record) == 0 {
+			break
+		}
+		offset = next
+
+		if record[0] == pageRecord && len(record) == 1+8+pageSize {
+			pending = append(pending, image{binary.BigEndian.Uint64(record[1:]), record[9:]})
+			continue
+		}
+		if record[0] != commitRecord {
+			break
+		}
+		m, err := decodeMeta(record[1:])
+		if err != nil {
+			break
+		}
+
+		for _, p := range pending {
+			if err := apply(p.id, p.page); err != nil {
+				return meta{}, false, err
+			}
+		}
+		last, committed, pending = m, true, nil
+	}
+
+	return last, committed, nil
+}
+
+// reset empties the journal once everything it holds has reached the data file.
+// The file is truncated and synced in place rather than replaced, so the directory never points at a stale
+// journal, or at none, after a crash.
+func (j *journal) reset() error {
+	if err := os.Truncate(j.path, 0); err != nil {
+		return err
+	}
+	if err := j.log.Sync(); err != nil {
+		return err
+	}
+	j.size = 0
+	return nil
+}
+
+func (j *journal) close() error {
+	return j.log.Close()
+}
@@ -0,0 +1,269 @@
+package disk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Page 0 of the data file holds the meta record and every other page holds one node.
+// A node page starts with a header, followed by the entries and padding, and ends with a CRC32 of everything before it:
+//
+//	kind (1) | count (2) | prev (8) | next (8) | entries ... | checksum (4)
+//
+// Leaf entries are a uvarint length and the encoded key, then a uvarint length and the encoded value.
+// Internal nodes store their first child and then, for every key, the length, the encoded key and the child that follows it.
+// Free pages use next to link the free list.
+const (
+	headerSize   = 1 + 2 + 8 + 8
+	checksumSize = 4
+	childSize    = 8
+
+	minPageSize = 512
+	maxPageSize = 1 << 16
+)
+
+type pageKind byte
+
+const (
+	freePage pageKind = iota + 1
+	leafPage
+	internalPage
+)
+
+// node is a decoded page. Pages are identified by their index in the data file, so 0 doubles as "no page".
+type node[K, V any] struct {
+	id   uint64
+	kind pageKind
+	keys []K
+	// values holds the values of a leaf.
+	values []V
+	// children holds the pages of an internal node, one more than it has keys.
+	// The keys in children[i] are at least keys[i-1] and less than keys[i].
+	children []uint64
+	// prev and next link the leaves in key order; next also links free pages.
+	prev, next uint64
+}
+
+func (n *node[K, V]) leaf() bool {
+	return n.kind == leafPage
+}
+
+// layout encodes and decodes the pages of a tree.
+type layout[K, V any] struct {
+	keys     Codec[K]
+	values   Codec[V]
+	pageSize int
+}
+
+// maxEntry is the largest encoded leaf entry allowed. Capping entries at a quarter of the usable space
+// guarantees that splitting an overfull page by bytes leaves two halves that both fit,
+// and that redistributing two pages that cannot merge leaves neither of them underfull.
+func (l *layout[K, V]) maxEntry() int {
+	return (l.pageSize - headerSize - checksumSize) / 4
+}
+
+// underfull reports whether n uses less than a quarter of its page and should be merged with or refilled from a sibling.
+func (l *layout[K, V]) underfull(n *node[K, V]) bool {
+	return l.size(n) < l.pageSize/4
+}
+
+// fits reports whether n can be encoded in a single page.
+func (l *layout[K, V]) fits(n *node[K, V]) bool {
+	return l.size(n) <= l.pageSize
+}
+
+// size returns the number of bytes n takes in a page, including the header and checksum but not the padding.
+func (l *layout[K, V]) size(n *node[K, V]) int {
+	size := headerSize + checksumSize
+	if !n.leaf() {
+		size += childSize
+	}
+	for _, s := range l.entrySizes(n) {
+		size += s
+	}
+	return size
+}
+
+// entrySizes returns the encoded size of each entry of n: a key and value for a leaf,
+// or a key and the child after it for an internal node.
+func (l *layout[K, V]) entrySizes(n *node[K, V]) []int {
+	sizes := make([]int, len(n.keys))
+	var buf []byte
+	for i, key := range n.keys {
+		buf = l.keys.Append(buf[:0], key)
+		sizes[i] = uvarintSize(len(buf)) + len(buf)
+		if n.leaf() {
+			buf = l.values.Append(buf[:0], n.values[i])
+			sizes[i] += uvarintSize(len(buf)) + len(buf)
+		} else {
+			sizes[i] += childSize
+		}
+	}
+	return sizes
+}
+
+// entrySize returns the encoded size of a leaf entry.
+func (l *layout[K, V]) entrySize(key K, value V) int {
+	k := l.keys.Append(nil, key)
+	v := l.values.Append(nil, value)
+	return uvarintSize(len(k)) + len(k) + uvarintSize(len(v)) + len(v)
+}
+
+// encode returns the page image of n. It panics if n does not fit in a page, which the tree never lets happen.
+func (l *layout[K, V]) encode(n *node[K, V]) []byte {
+	page := make([]byte, headerSize, l.pageSize)
+	page[0] = byte(n.kind)
+	binary.BigEndian.PutUint16(page[1:], uint16(len(n.keys)))
+	binary.BigEndian.PutUint64(page[3:], n.prev)
+	binary.BigEndian.PutUint64(page[11:], n.next)
+
+	if n.kind == internalPage {
+		page = binary.BigEndian.AppendUint64(page, n.children[0])
+	}
+	var buf []byte
+	for i, key := range n.keys {
+		buf = l.keys.Append(buf[:0], key)
+		page = binary.AppendUvarint(page, uint64(len(buf)))
+		page = append(page, buf...)
+		if n.leaf() {
+			buf = l.values.Append(buf[:0], n.values[i])
+			page = binary.AppendUvarint(page, uint64(len(buf)))
+			page = append(page, buf...)
+		} else {
+			page = binary.BigEndian.AppendUint64(page, n.children[i+1])
+		}
+	}
+
+	if len(page) > l.pageSize-checksumSize {
+		panic(fmt.Sprintf("disk: page %d overflows", n.id))
+	}
+	// The capacity was zeroed by make, so the padding is already clear
+	page = page[:l.pageSize]
+	binary.BigEndian.PutUint32(page[l.pageSize-checksumSize:], crc32.ChecksumIEEE(page[:l.pageSize-checksumSize]))
+	return page
+}
+
+// decode decodes the page image of page id.
+func (l *layout[K, V]) decode(id uint64, page []byte) (*node[K, V], error) {
+	body := page[:l.pageSize-checksumSize]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(page[l.pageSize-checksumSize:]) {
+		return nil, fmt.Errorf("disk: page %d is corrupt", id)
+	}
+
+	n := &node[K, V]{
+		id:   id,
+		kind: pageKind(page[0]),
+		prev: binary.BigEndian.Uint64(page[3:]),
+		next: binary.BigEndian.Uint64(page[11:]),
+	}
+	count := int(binary.BigEndian.Uint16(page[1:]))
+	r := reader{data: body[headerSize:]}
+
+	switch n.kind {
+	case freePage:
+		return n, nil
+	case leafPage:
+		n.keys, n.values = make([]K, count), make([]V, count)
+	case internalPage:
+		n.keys, n.children = make([]K, count), make([]uint64, count+1)
+		n.children[0] = r.uint64()
+	default:
+		return nil, fmt.Errorf("disk: page %d has unknown kind %d", id, n.kind)
+	}
+
+	for i := range count {
+		key, err := l.keys.Decode(r.bytes())
+		if err != nil || r.err != nil {
+			return nil, fmt.Errorf("disk: page %d: key %d: %w", id, i, errors.Join(r.err, err))
+		}
+		n.keys[i] = key
+
+		if n.leaf() {
+			value, err := l.values.Decode(r.bytes())
+			if err != nil || r.err != nil {
+				return nil, fmt.Errorf("disk: page %d: value %d: %w", id, i, errors.Join(r.err, err))
+			}
+			n.values[i] = value
+		} else {
+			n.children[i+1] = r.uint64()
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("disk: page %d: %w", id, r.err)
+	}
+
+	return n, nil
+}
+
+// reader decodes the fields of a page body, remembering the first error.
+type reader struct {
+	data []byte
+	err  error
+}
+
+var errTruncated = errors.New("truncated page")
+
+func (r *reader) uint64() uint64 {
+	if len(r.data) < 8 {
+		r.err = errTruncated
+		return 0
+	}
+	v := binary.BigEndian.Uint64(r.data)
+	r.data = r.data[8:]
+	return v
+}
+
+func (r *reader) bytes() []byte {
+	length, n := binary.Uvarint(r.data)
+	if n <= 0 || uint64(len(r.data)-n) < length {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[n : n+int(length)]
+	r.data = r.data[n+int(length):]
+	return b
+}
+
+func uvarintSize(v int) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], uint64(v))
+}
+
+// meta describes the tree. It is stored at the start of page 0 and in every commit record of the journal.
+type meta struct {
+	pageSize uint64
+	root     uint64
+	// size is the number of entries.
+	size uint64
+	// pages is the number of pages in use or on the free list, including page 0.
+	pages uint64
+	// free is the first page of the free list, or 0 if it is empty.
+	free uint64
+}
+
+const (
+	metaMagic = 0x42504c53 // "BPLS"
+	metaSize  = 4 + 5*8 + checksumSize
+)
+
+func (m meta) encode() []byte {
+	buf := binary.BigEndian.AppendUint32(make([]byte, 0, metaSize), metaMagic)
+	for _, v := range []uint64{m.pageSize, m.root, m.size, m.pages, m.free} {
+		buf = binary.BigEndian.AppendUint64(buf, v)
+	}
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+}
+
+func decodeMeta(data []byte) (meta, error) {
+	if len(data) < metaSize || binary.BigEndian.Uint32(data) != metaMagic {
+		return meta{}, errors.New("disk: not a B+ tree file")
+	}
+	if crc32.ChecksumIEEE(data[:metaSize-checksumSize]) != binary.BigEndian.Uint32(data[metaSize-checksumSize:]) {
+		return meta{}, errors.New("disk: meta page is corrupt")
+	}
+
+	r := reader{data: data[4:]}
+	return meta{pageSize: r.uint64(), root: r.uint64(), size: r.uint64(), pages: r.uint64(), free: r.uint64()}, nil
+}
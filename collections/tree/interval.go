@@ -0,0 +1,162 @@
+package tree
+
+import (
+	"cmp"
+	"practice/collections"
+	"slices"
+)
+
+// Interval is the closed interval [Start, End].
+type Interval[T cmp.Ordered] struct {
+	Start, End T
+}
+
+// Overlaps reports whether the intervals share at least one point.
+func (i Interval[T]) Overlaps(other Interval[T]) bool {
+	return i.Start <= other.End && other.Start <= i.End
+}
+
+// IntervalTree stores values keyed by intervals and finds every interval overlapping a query interval or point.
+// It is a RedBlackTree keyed by interval start in which every node also holds the greatest end of any interval
+// in its subtree, as described in 'Introduction to Algorithms' by Cormen et al.
+// The augmentation lets searches skip every subtree that ends before the query starts, and a search stops
+// going right once intervals start after the query ends. A query that finds k intervals visits O(log n) nodes
+// for each of them, and for a path that finds none, so it takes O(min(n, (k+1) log n)) time, plus the time to scan
+// the intervals that share a start with a result but do not overlap the query.
+// It stores duplicate intervals.
+type IntervalTree[T cmp.Ordered, V any] struct {
+	tree *RedBlackTree[T, *intervalNode[T, V]]
+	size int
+}
+
+// intervalNode holds every interval with the same start.
+type intervalNode[T cmp.Ordered, V any] struct {
+	intervals []collections.Pair[Interval[T], V]
+	// maxEnd is the greatest end of any interval in this node's subtree.
+	maxEnd T
+}
+
+func NewIntervalTree[T cmp.Ordered, V any]() *IntervalTree[T, V] {
+	tree := NewRedBlackTree[T, *intervalNode[T, V]]()
+	tree.augment = func(x *RedBlackNode[T, *intervalNode[T, V]]) {
+		n := x.value
+		n.maxEnd = n.intervals[0].Key.End
+		for _, p := range n.intervals[1:] {
+			n.maxEnd = max(n.maxEnd, p.Key.End)
+		}
+		if x.left != tree.nil {
+			n.maxEnd = max(n.maxEnd, x.left.value.maxEnd)
+		}
+		if x.right != tree.nil {
+			n.maxEnd = max(n.maxEnd, x.right.value.maxEnd)
+		}
+	}
+
+	return &IntervalTree[T, V]{tree: tree}
+}
+
+func (t *IntervalTree[T, V]) Size() int {
+	return t.size
+}
+
+func (t *IntervalTree[T, V]) IsEmpty() bool {
+	return t.Size() == 0
+}
+
+// InsertInterval adds interval with value to the tree.
+// It panics if the interval starts after it ends.
+func (t *IntervalTree[T, V]) InsertInterval(interval Interval[T], value V) {
+	if interval.Start > interval.End {
+		panic("tree: interval starts after it ends")
+	}
+
+	entry := collections.Pair[Interval[T], V]{Key: interval, Value: value}
+	if x := t.tree.lookup(interval.Start); x != nil {
+		x.value.intervals = append(x.value.intervals, entry)
+		t.tree.augmentPath(x)
+	} else {
+		t.tree.Insert(interval.Start, &intervalNode[T, V]{
+			intervals: []collections.Pair[Interval[T], V]{entry},
+		})
+	}
+
+	t.size++
+}
+
+// DeleteInterval removes one occurrence of interval from the tree (if it exists).
+// True is returned if an interval is deleted.
+func (t *IntervalTree[T, V]) DeleteInterval(interval Interval[T]) bool {
+	x := t.tree.lookup(interval.Start)
+	if x == nil {
+		return false
+	}
+
+	i := slices.IndexFunc(x.value.intervals, func(p collections.Pair[Interval[T], V]) bool {
+		return p.Key == interval
+	})
+	if i == -1 {
+		return false
+	}
+
+	if len(x.value.intervals) == 1 {
+		t.tree.Delete(interval.Start)
+	} else {
+		x.value.intervals = slices.Delete(x.value.intervals, i, i+1)
+		t.tree.augmentPath(x)
+	}
+
+	t.size--
+	return true
+}
+
+// Overlapping returns every interval that overlaps query, with its value, in ascending order of start.
+func (t *IntervalTree[T, V]) Overlapping(query Interval[T]) []collections.Pair[Interval[T], V] {
+	var result []collections.Pair[Interval[T], V]
+	t.overlapping(t.tree.root, query, func(p collections.Pair[Interval[T], V]) bool {
+		result = append(result, p)
+		return true
+	})
+	return result
+}
+
+// Stabbing returns every interval that contains point, with its value, in ascending order of start.
+func (t *IntervalTree[T, V]) Stabbing(point T) []collections.Pair[Interval[T], V] {
+	return t.Overlapping(Interval[T]{Start: point, End: point})
+}
+
+// AnyOverlap reports whether any interval in the tree overlaps query.
+// It stops at the first overlap it finds, so it takes O(log n) time.
+func (t *IntervalTree[T, V]) AnyOverlap(query Interval[T]) bool {
+	found := false
+	t.overlapping(t.tree.root, query, func(collections.Pair[Interval[T], V]) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// overlapping calls fn on every interval in the subtree rooted at x that overlaps query, until fn returns false.
+// False is returned if fn stopped the search.
+func (t *IntervalTree[T, V]) overlapping(x *RedBlackNode[T, *intervalNode[T, V]], query Interval[T], fn func(p collections.Pair[Interval[T], V]) bool) bool {
+	// Nothing in the subtree reaches the query
+	if x == t.tree.nil || x.value.maxEnd < query.Start {
+		return true
+	}
+
+	if !t.overlapping(x.left, query, fn) {
+		return false
+	}
+
+	// This node and everything to its right start after the query ends
+	if x.key > query.End {
+		return true
+	}
+
+	for _, p := range x.value.intervals {
+		if p.Key.End >= query.Start && !fn(p) {
+			return false
+		}
+	}
+
+	return t.overlapping(x.right, query, fn)
+}
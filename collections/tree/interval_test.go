@@ -0,0 +1,148 @@
+package tree
+
+import (
+	"fmt"
+	"practice/collections"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func ExampleIntervalTree() {
+	bookings := NewIntervalTree[int, string]()
+	bookings.InsertInterval(Interval[int]{Start: 9, End: 11}, "standup")
+	bookings.InsertInterval(Interval[int]{Start: 13, End: 14}, "lunch")
+	bookings.InsertInterval(Interval[int]{Start: 10, End: 16}, "workshop")
+
+	for _, p := range bookings.Stabbing(10) {
+		fmt.Println(p.Value)
+	}
+	fmt.Println(bookings.AnyOverlap(Interval[int]{Start: 17, End: 18}))
+	// Output:
+	// standup
+	// workshop
+	// false
+}
+
+// maxEndsHold reports whether every node in the subtree rooted at x holds the greatest end in its subtree,
+// returning that end.
+func (t *IntervalTree[T, V]) maxEndsHold(x *RedBlackNode[T, *intervalNode[T, V]]) (T, bool) {
+	end := x.value.intervals[0].Key.End
+	for _, p := range x.value.intervals {
+		end = max(end, p.Key.End)
+	}
+	for _, child := range []*RedBlackNode[T, *intervalNode[T, V]]{x.left, x.right} {
+		if child == t.tree.nil {
+			continue
+		}
+		childEnd, ok := t.maxEndsHold(child)
+		if !ok {
+			return end, false
+		}
+		end = max(end, childEnd)
+	}
+	return end, end == x.value.maxEnd
+}
+
+func TestIntervalTree_Basics(t *testing.T) {
+	t.Parallel()
+	tree := NewIntervalTree[int, string]()
+	a := Interval[int]{Start: 1, End: 5}
+	b := Interval[int]{Start: 3, End: 3}
+	tree.InsertInterval(a, "a")
+	tree.InsertInterval(a, "a'")
+	tree.InsertInterval(b, "b")
+
+	if tree.Size() != 3 {
+		t.Errorf("Size() = %v, want %v", tree.Size(), 3)
+	}
+	if got := tree.Stabbing(3); len(got) != 3 {
+		t.Errorf("Stabbing(3) = %v, want 3 intervals", got)
+	}
+	if got := tree.Stabbing(6); len(got) != 0 {
+		t.Errorf("Stabbing(6) = %v, want none", got)
+	}
+	if tree.DeleteInterval(Interval[int]{Start: 1, End: 4}) {
+		t.Errorf("DeleteInterval() removed an interval that was never inserted")
+	}
+	if !tree.DeleteInterval(a) || !tree.DeleteInterval(a) || tree.DeleteInterval(a) {
+		t.Errorf("DeleteInterval() did not remove each copy of a duplicate interval exactly once")
+	}
+	if got := tree.Overlapping(Interval[int]{Start: 0, End: 10}); len(got) != 1 || got[0].Value != "b" {
+		t.Errorf("Overlapping() = %v, want only b", got)
+	}
+}
+
+func TestIntervalTree_InsertPanicsOnReversedInterval(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("InsertInterval() of a reversed interval did not panic")
+		}
+	}()
+	NewIntervalTree[int, int]().InsertInterval(Interval[int]{Start: 2, End: 1}, 0)
+}
+
+// Property: queries match a brute force scan over a slice and the max ends stay correct
+// through any sequence of inserts and deletes
+func TestIntervalTree_MatchesModel(t *testing.T) {
+	t.Parallel()
+	type entry = collections.Pair[Interval[int], int]
+	f := func(ops []uint16) bool {
+		tree := NewIntervalTree[int, int]()
+		var model []entry
+
+		for i, op := range ops {
+			// Small coordinates make shared starts and duplicates common
+			start := int(op % 32)
+			interval := Interval[int]{Start: start, End: start + int(op>>5%8)}
+
+			if op>>8%3 == 0 {
+				at := slices.IndexFunc(model, func(e entry) bool { return e.Key == interval })
+				if tree.DeleteInterval(interval) != (at != -1) {
+					return false
+				}
+				if at != -1 {
+					model = slices.Delete(model, at, at+1)
+				}
+			} else {
+				tree.InsertInterval(interval, i)
+				model = append(model, entry{Key: interval, Value: i})
+			}
+
+			if tree.Size() != len(model) {
+				return false
+			}
+			if !tree.IsEmpty() {
+				if _, ok := tree.maxEndsHold(tree.tree.root); !ok {
+					return false
+				}
+			}
+
+			query := Interval[int]{Start: int(op % 40), End: int(op%40) + int(op>>11%6)}
+			var want []entry
+			for _, e := range model {
+				if e.Key.Overlaps(query) {
+					want = append(want, e)
+				}
+			}
+			got := tree.Overlapping(query)
+			if len(got) != len(want) || tree.AnyOverlap(query) != (len(want) > 0) {
+				return false
+			}
+			for _, e := range got {
+				if !slices.Contains(want, e) {
+					return false
+				}
+			}
+			if !slices.IsSortedFunc(got, func(a, b entry) int { return a.Key.Start - b.Key.Start }) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,260 @@
+package tree
+
+import (
+	"cmp"
+	"practice/collections"
+	"sync/atomic"
+)
+
+// LockFreeSkipList is a SkipList that is safe for concurrent use without locks.
+// It follows the lock-free skip list of Herlihy and Shavit's 'The Art of Multiprocessor Programming':
+// a node is deleted by first marking its links, top layer first, and then physically unlinked
+// by whichever goroutine next traverses past it. A key is in the list once it is linked on the
+// bottom layer and until its bottom link is marked, so Search never blocks and never retries.
+// Size, Range and Entries are weakly consistent when the list is modified concurrently.
+type LockFreeSkipList[K cmp.Ordered, V any] struct {
+	head *lockFreeNode[K, V]
+	size atomic.Int64
+}
+
+type lockFreeNode[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+	next  []atomic.Pointer[markedLink[K, V]]
+}
+
+// markedLink is an immutable pointer to the next node together with a mark that is set once
+// the node owning the link is being deleted. Swapping a whole link updates both atomically.
+type markedLink[K cmp.Ordered, V any] struct {
+	node   *lockFreeNode[K, V]
+	marked bool
+}
+
+func NewLockFreeSkipList[K cmp.Ordered, V any]() *LockFreeSkipList[K, V] {
+	return &LockFreeSkipList[K, V]{
+		head: newLockFreeNode[K, V](*new(K), *new(V), maxLevel),
+	}
+}
+
+func newLockFreeNode[K cmp.Ordered, V any](key K, value V, level int) *lockFreeNode[K, V] {
+	n := &lockFreeNode[K, V]{
+		key:   key,
+		value: value,
+		next:  make([]atomic.Pointer[markedLink[K, V]], level),
+	}
+	for i := range n.next {
+		n.next[i].Store(&markedLink[K, V]{})
+	}
+	return n
+}
+
+// link returns the link of n on layer i.
+func (n *lockFreeNode[K, V]) link(i int) *markedLink[K, V] {
+	return n.next[i].Load()
+}
+
+// swap replaces the link of n on layer i if it still points to expected with the expected mark.
+func (n *lockFreeNode[K, V]) swap(i int, expected *lockFreeNode[K, V], expectedMark bool, node *lockFreeNode[K, V], marked bool) bool {
+	current := n.next[i].Load()
+	if current.node != expected || current.marked != expectedMark {
+		return false
+	}
+
+	return n.next[i].CompareAndSwap(current, &markedLink[K, V]{node: node, marked: marked})
+}
+
+func (s *LockFreeSkipList[K, V]) Size() int {
+	return int(s.size.Load())
+}
+
+func (s *LockFreeSkipList[K, V]) IsEmpty() bool {
+	return s.Size() == 0
+}
+
+// Minimum returns the key-value pair with the minimum key.
+func (s *LockFreeSkipList[K, V]) Minimum() (*K, *V) {
+	for x := s.head.link(0).node; x != nil; x = x.link(0).node {
+		if !x.link(0).marked {
+			return &x.key, &x.value
+		}
+	}
+
+	return nil, nil
+}
+
+// Maximum returns the key-value pair with the maximum key.
+func (s *LockFreeSkipList[K, V]) Maximum() (*K, *V) {
+	last := s.head
+	for i := maxLevel - 1; i >= 0; i-- {
+		for x := last.link(i).node; x != nil; {
+			next := x.link(i)
+			if !next.marked {
+				last = x
+			}
+			x = next.node
+		}
+	}
+
+	if last == s.head {
+		return nil, nil
+	}
+
+	return &last.key, &last.value
+}
+
+func (s *LockFreeSkipList[K, V]) Search(key K) (*V, bool) {
+	x := s.ceiling(key)
+	if x == nil || x.key != key {
+		return nil, false
+	}
+
+	return &x.value, true
+}
+
+// Insert inserts a key-value pair into the list (only if the key does not already exist).
+// True is returned if the key is inserted.
+// False is returned if the key already exists.
+func (s *LockFreeSkipList[K, V]) Insert(key K, value V) bool {
+	var preds, succs [maxLevel]*lockFreeNode[K, V]
+	n := newLockFreeNode(key, value, randomLevel())
+
+	for {
+		if s.find(key, &preds, &succs) {
+			return false
+		}
+
+		for i := range n.next {
+			n.next[i].Store(&markedLink[K, V]{node: succs[i]})
+		}
+		// The key is in the list as soon as it is linked on the bottom layer.
+		if preds[0].swap(0, succs[0], false, n, false) {
+			break
+		}
+	}
+
+	for i := 1; i < len(n.next); i++ {
+		for {
+			current := n.link(i)
+			if current.marked {
+				// The node is already being deleted, so there is no point linking it higher.
+				s.size.Add(1)
+				return true
+			}
+			if current.node != succs[i] && !n.swap(i, current.node, false, succs[i], false) {
+				continue
+			}
+			if preds[i].swap(i, succs[i], false, n, false) {
+				break
+			}
+			s.find(key, &preds, &succs)
+		}
+	}
+
+	s.size.Add(1)
+	return true
+}
+
+// Delete removes a key from the list (if it exists).
+// True is returned if the key is deleted.
+// False is returned if the key does not exist.
+func (s *LockFreeSkipList[K, V]) Delete(key K) bool {
+	var preds, succs [maxLevel]*lockFreeNode[K, V]
+	if !s.find(key, &preds, &succs) {
+		return false
+	}
+
+	n := succs[0]
+	for i := len(n.next) - 1; i >= 1; i-- {
+		for {
+			current := n.link(i)
+			if current.marked || n.swap(i, current.node, false, current.node, true) {
+				break
+			}
+		}
+	}
+
+	for {
+		current := n.link(0)
+		if current.marked {
+			// Another goroutine deleted the key first.
+			return false
+		}
+		if n.swap(0, current.node, false, current.node, true) {
+			// Unlink the node now rather than leaving it to later traversals.
+			s.find(key, &preds, &succs)
+			s.size.Add(-1)
+			return true
+		}
+	}
+}
+
+// Range returns all the key-value pairs whose keys are in the range [low, high].
+func (s *LockFreeSkipList[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	for x := s.ceiling(low); x != nil && x.key <= high; x = x.link(0).node {
+		if !x.link(0).marked {
+			result = append(result, collections.Pair[K, V]{Key: x.key, Value: x.value})
+		}
+	}
+	return result
+}
+
+func (s *LockFreeSkipList[K, V]) Entries() []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	for x := s.head.link(0).node; x != nil; x = x.link(0).node {
+		if !x.link(0).marked {
+			result = append(result, collections.Pair[K, V]{Key: x.key, Value: x.value})
+		}
+	}
+	return result
+}
+
+// ceiling returns the first unmarked node on the bottom layer whose key is at least key, without modifying the list.
+func (s *LockFreeSkipList[K, V]) ceiling(key K) *lockFreeNode[K, V] {
+	pred := s.head
+	var curr *lockFreeNode[K, V]
+	for i := maxLevel - 1; i >= 0; i-- {
+		curr = pred.link(i).node
+		for curr != nil {
+			next := curr.link(i)
+			if next.marked {
+				curr = next.node
+				continue
+			}
+			if curr.key >= key {
+				break
+			}
+			pred, curr = curr, next.node
+		}
+	}
+	return curr
+}
+
+// find fills preds and succs with the nodes on either side of key on every layer,
+// unlinking marked nodes along the way. It reports whether key is in the list, in which case
+// succs holds its node on the layers where it is linked.
+func (s *LockFreeSkipList[K, V]) find(key K, preds, succs *[maxLevel]*lockFreeNode[K, V]) bool {
+retry:
+	pred := s.head
+	var curr *lockFreeNode[K, V]
+	for i := maxLevel - 1; i >= 0; i-- {
+		curr = pred.link(i).node
+		for curr != nil {
+			next := curr.link(i)
+			if next.marked {
+				if !pred.swap(i, curr, false, next.node, false) {
+					// pred changed or is being deleted itself, so start over.
+					goto retry
+				}
+				curr = next.node
+				continue
+			}
+			if curr.key >= key {
+				break
+			}
+			pred, curr = curr, next.node
+		}
+		preds[i], succs[i] = pred, curr
+	}
+	return curr != nil && curr.key == key
+}
@@ -0,0 +1,105 @@
+package tree
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLockFreeSkipList_ConcurrentInsertAndDelete(t *testing.T) {
+	t.Parallel()
+	s := NewLockFreeSkipList[int, int]()
+	const goroutines, keys = 8, 1000
+
+	// Every goroutine races to insert the same keys, so each key must be inserted exactly once.
+	var inserted atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < keys; k++ {
+				if s.Insert(k, k) {
+					inserted.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if inserted.Load() != keys || s.Size() != keys {
+		t.Fatalf("inserted %v keys with Size() %v, want %v", inserted.Load(), s.Size(), keys)
+	}
+
+	// Then they race to delete the even keys while searching for the odd ones.
+	var deleted atomic.Int64
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < keys; k++ {
+				if k%2 == 0 {
+					if s.Delete(k) {
+						deleted.Add(1)
+					}
+				} else if v, ok := s.Search(k); !ok || *v != k {
+					t.Errorf("Search(%v) = %v, %v while deleting other keys", k, v, ok)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if deleted.Load() != keys/2 || s.Size() != keys/2 {
+		t.Fatalf("deleted %v keys with Size() %v, want %v", deleted.Load(), s.Size(), keys/2)
+	}
+	for i, p := range s.Entries() {
+		if p.Key != 2*i+1 {
+			t.Fatalf("Entries()[%v] = %v, want key %v", i, p, 2*i+1)
+		}
+	}
+}
+
+func TestLockFreeSkipList_ConcurrentChurn(t *testing.T) {
+	t.Parallel()
+	s := NewLockFreeSkipList[int, int]()
+
+	// Each goroutine owns the keys congruent to its index, so the final contents are known.
+	const goroutines = 8
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for round := 0; round < 200; round++ {
+				for k := g; k < 200; k += goroutines {
+					s.Insert(k, round)
+				}
+				for k := g; k < 200; k += 2 * goroutines {
+					s.Delete(k)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	entries := s.Entries()
+	if len(entries) != s.Size() {
+		t.Errorf("len(Entries()) = %v, Size() = %v", len(entries), s.Size())
+	}
+	var want []int
+	for k := 0; k < 200; k++ {
+		if k%(2*goroutines) >= goroutines {
+			want = append(want, k)
+		}
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("len(Entries()) = %v, want %v", len(entries), len(want))
+	}
+	for i, p := range entries {
+		// Insert never overwrites, so surviving keys keep the value from the first round.
+		if p.Key != want[i] || p.Value != 0 {
+			t.Fatalf("Entries()[%v] = %v, want key %v from the first round", i, p, want[i])
+		}
+	}
+}
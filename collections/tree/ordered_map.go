@@ -0,0 +1,55 @@
+package tree
+
+import (
+	"cmp"
+	"iter"
+	"practice/collections"
+)
+
+// OrderedMap is a map that keeps its keys in sorted order.
+// Insert never adds a duplicate key, but a RedBlackTree becomes a multimap once RedBlackTree.InsertMulti is used,
+// and then holds several pairs per key. The methods then work on pairs: Search returns the first value stored
+// under a key and Delete removes all of them, Rank counts the pairs with a smaller key, and Select, Range, Entries,
+// All and Size count and visit every pair, with the pairs of a key in insertion order.
+// Pointers returned by an OrderedMap are only guaranteed to stay valid until the map is next modified.
+type OrderedMap[K cmp.Ordered, V any] interface {
+	// Insert inserts a key-value pair into the map (only if the key does not already exist).
+	// True is returned if the key is inserted.
+	Insert(key K, value V) bool
+	// Search returns the value stored for key.
+	Search(key K) (*V, bool)
+	// Delete removes a key from the map (if it exists).
+	// True is returned if the key is deleted.
+	Delete(key K) bool
+
+	// Minimum returns the key-value pair with the minimum key, or nils if the map is empty.
+	Minimum() (*K, *V)
+	// Maximum returns the key-value pair with the maximum key, or nils if the map is empty.
+	Maximum() (*K, *V)
+	// Floor returns the key-value pair with the greatest key less than or equal to key, or nils if there is none.
+	Floor(key K) (*K, *V)
+	// Ceiling returns the key-value pair with the least key greater than or equal to key, or nils if there is none.
+	Ceiling(key K) (*K, *V)
+
+	// Rank returns the number of keys less than key (of pairs, in a multimap).
+	Rank(key K) int
+	// Select returns the key-value pair with the given rank, or nils if i is out of range.
+	Select(i int) (*K, *V)
+
+	// Range returns all the key-value pairs whose keys are in the range [low, high].
+	Range(low K, high K) []collections.Pair[K, V]
+	// Entries returns all the key-value pairs in ascending order of key.
+	Entries() []collections.Pair[K, V]
+	// All returns an iterator over the key-value pairs in ascending order of key.
+	All() iter.Seq2[K, V]
+
+	Size() int
+	IsEmpty() bool
+}
+
+var (
+	_ OrderedMap[int, int] = (*RedBlackTree[int, int])(nil)
+	_ OrderedMap[int, int] = (*AVLTree[int, int])(nil)
+	_ OrderedMap[int, int] = (*BTree[int, int])(nil)
+	_ OrderedMap[int, int] = (*Treap[int, int])(nil)
+)
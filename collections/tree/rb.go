@@ -3,24 +3,64 @@ package tree
 import (
 	"cmp"
 	"practice/collections"
+	"sync"
 )
 
 // RedBlackTree is a red-black tree data structure.
 // Its implementation is taken from 'Introduction to Algorithms' by Cormen et al.
-// It does not allow duplicate keys.
-type RedBlackTree[K cmp.Ordered, V any] struct {
+// Insert does not allow duplicate keys, but InsertMulti turns the tree into a multimap that stores any number of values per key.
+// Every node is augmented with the size of its subtree, which makes it an order-statistic tree:
+// Rank and Select run in O(log n).
+type RedBlackTree[K any, V any] struct {
 	root *RedBlackNode[K, V]
 	nil  *RedBlackNode[K, V]
 	size int
+	// version changes whenever a node is added or removed, so that cursors can detect modification
+	version uint64
+	// augment, if set, recomputes extra data kept in a node from the node and its children.
+	// It is called bottom-up on every node whose subtree changes, including during rotations.
+	augment func(x *RedBlackNode[K, V])
+	// find and locate search t for key. Every search and update compares keys through one of them,
+	// so cmp.Ordered keys are compared directly and only trees built by NewRedBlackTreeFunc pay for calling compare.
+	// Search and Insert use find, which stops at the first match and tracks nothing else on the way down,
+	// so they cost one indirect call over a plain loop.
+	// They take the tree as an argument rather than capturing it so that snapshots can share them.
+	find   func(t *RedBlackTree[K, V], key K) (x, parent *RedBlackNode[K, V], left bool)
+	locate func(t *RedBlackTree[K, V], key K, strict bool) position[K, V]
+	// compare orders the keys; locate does not use it for cmp.Ordered keys.
+	compare func(a, b K) int
+	// multi is set once InsertMulti has been used, after which a key may be held by several nodes.
+	multi bool
+	// epoch advances with every snapshot. Nodes from an earlier epoch may be shared with a snapshot,
+	// so they are copied by own before they are modified.
+	epoch uint64
 }
 
-type RedBlackNode[K cmp.Ordered, V any] struct {
+type RedBlackNode[K any, V any] struct {
 	key    K
 	value  V
 	parent *RedBlackNode[K, V]
 	left   *RedBlackNode[K, V]
 	right  *RedBlackNode[K, V]
 	color  bool
+	// size is the number of nodes in the subtree rooted at this node; it is always 0 for the nil sentinel.
+	size int
+	// epoch is the tree's epoch when the node was created.
+	epoch uint64
+}
+
+// position is where a key falls among the nodes of a tree, found by a single descent from the root.
+type position[K any, V any] struct {
+	// next is the first node whose key is greater than or equal to the key (strictly greater, for a strict search)
+	// and prev is the node before it. Either is nil if there is no such node.
+	next, prev *RedBlackNode[K, V]
+	// equal reports whether next holds the key.
+	equal bool
+	// rank is the number of nodes before next.
+	rank int
+	// parent is the last node visited, under which a new node for the key belongs: as its left child if left is set.
+	parent *RedBlackNode[K, V]
+	left   bool
 }
 
 const (
@@ -29,15 +69,132 @@ const (
 )
 
 func NewRedBlackTree[K cmp.Ordered, V any]() *RedBlackTree[K, V] {
+	t := newRedBlackTree[K, V](cmp.Compare[K])
+	t.find = findOrdered[K, V]
+	t.locate = locateOrdered[K, V]
+	return t
+}
+
+// NewRedBlackTreeFunc returns an empty tree ordered by compare, which must return a negative number, zero or a positive number
+// when a is less than, equal to or greater than b, like cmp.Compare.
+// It accepts any key type, so keys can be structs, composite keys or ordered in reverse.
+// Every comparison is an indirect call, so NewRedBlackTree is faster for cmp.Ordered keys.
+func NewRedBlackTreeFunc[K any, V any](compare func(a, b K) int) *RedBlackTree[K, V] {
+	t := newRedBlackTree[K, V](compare)
+	t.find = (*RedBlackTree[K, V]).findFunc
+	t.locate = (*RedBlackTree[K, V]).locateFunc
+	return t
+}
+
+func newRedBlackTree[K any, V any](compare func(a, b K) int) *RedBlackTree[K, V] {
+	nilNode := sentinel[K, V]()
+	return &RedBlackTree[K, V]{
+		nil:     nilNode,
+		root:    nilNode,
+		compare: compare,
+	}
+}
+
+// sentinels holds the nil sentinel of each instantiation of RedBlackNode, keyed by a nil pointer of that type.
+// Every tree with the same key and value types shares one, so Join can link the nodes of any two trees together.
+// Trees never modify the sentinel, which lets trees on different goroutines share it.
+var sentinels sync.Map
+
+func sentinel[K any, V any]() *RedBlackNode[K, V] {
+	typ := any((*RedBlackNode[K, V])(nil))
+	if nilNode, ok := sentinels.Load(typ); ok {
+		return nilNode.(*RedBlackNode[K, V])
+	}
+
 	nilNode := &RedBlackNode[K, V]{color: BLACK}
 	nilNode.left = nilNode
 	nilNode.right = nilNode
 	nilNode.parent = nilNode
 
-	return &RedBlackTree[K, V]{
-		nil:  nilNode,
-		root: nilNode,
+	actual, _ := sentinels.LoadOrStore(typ, nilNode)
+	return actual.(*RedBlackNode[K, V])
+}
+
+// findOrdered returns the node holding key, stopping at the first match.
+// If there is none, it returns nil and the node under which key belongs: as its left child if left is set.
+func findOrdered[K cmp.Ordered, V any](t *RedBlackTree[K, V], key K) (x, parent *RedBlackNode[K, V], left bool) {
+	parent = t.nil
+	x = t.root
+	for x != t.nil {
+		if key == x.key {
+			return x, parent, left
+		}
+
+		parent, left = x, key < x.key
+		if left {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+
+	return nil, parent, left
+}
+
+// locateOrdered descends from the root to a leaf to find the position of key.
+func locateOrdered[K cmp.Ordered, V any](t *RedBlackTree[K, V], key K, strict bool) (p position[K, V]) {
+	p.parent = t.nil
+	x := t.root
+	for x != t.nil {
+		p.parent = x
+		if key < x.key || !strict && key == x.key {
+			p.next, p.left = x, true
+			x = x.left
+		} else {
+			p.prev, p.left = x, false
+			p.rank += x.left.size + 1
+			x = x.right
+		}
 	}
+
+	p.equal = p.next != nil && key == p.next.key
+	return p
+}
+
+// findFunc is findOrdered for trees ordered by compare.
+func (t *RedBlackTree[K, V]) findFunc(key K) (x, parent *RedBlackNode[K, V], left bool) {
+	parent = t.nil
+	x = t.root
+	for x != t.nil {
+		c := t.compare(key, x.key)
+		if c == 0 {
+			return x, parent, left
+		}
+
+		parent, left = x, c < 0
+		if left {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+
+	return nil, parent, left
+}
+
+// locateFunc is locateOrdered for trees ordered by compare.
+func (t *RedBlackTree[K, V]) locateFunc(key K, strict bool) (p position[K, V]) {
+	p.parent = t.nil
+	x := t.root
+	for x != t.nil {
+		p.parent = x
+		if c := t.compare(key, x.key); c < 0 || !strict && c == 0 {
+			p.next, p.left = x, true
+			x = x.left
+		} else {
+			p.prev, p.left = x, false
+			p.rank += x.left.size + 1
+			x = x.right
+		}
+	}
+
+	p.equal = p.next != nil && t.compare(key, p.next.key) == 0
+	return p
 }
 
 func (t *RedBlackTree[K, V]) Size() int {
@@ -96,6 +253,60 @@ func (n *RedBlackNode[K, V]) maximum(nilNode *RedBlackNode[K, V]) *RedBlackNode[
 	return x
 }
 
+// Floor returns the key-value pair with the greatest key less than or equal to key.
+func (t *RedBlackTree[K, V]) Floor(key K) (*K, *V) {
+	return t.pair(t.locate(t, key, true).prev)
+}
+
+// Ceiling returns the key-value pair with the least key greater than or equal to key.
+func (t *RedBlackTree[K, V]) Ceiling(key K) (*K, *V) {
+	return t.pair(t.locate(t, key, false).next)
+}
+
+// Predecessor returns the key-value pair with the greatest key strictly less than key.
+func (t *RedBlackTree[K, V]) Predecessor(key K) (*K, *V) {
+	return t.pair(t.locate(t, key, false).prev)
+}
+
+// Successor returns the key-value pair with the least key strictly greater than key.
+func (t *RedBlackTree[K, V]) Successor(key K) (*K, *V) {
+	return t.pair(t.locate(t, key, true).next)
+}
+
+// pair returns the key and value of x, or nils if x is nil.
+func (t *RedBlackTree[K, V]) pair(x *RedBlackNode[K, V]) (*K, *V) {
+	if x == nil {
+		return nil, nil
+	}
+
+	return &x.key, &x.value
+}
+
+// Rank returns the number of keys less than key.
+func (t *RedBlackTree[K, V]) Rank(key K) int {
+	return t.locate(t, key, false).rank
+}
+
+// Select returns the key-value pair whose key has rank i, that is, the (i+1)-th smallest key.
+// Nils are returned if i is out of range.
+func (t *RedBlackTree[K, V]) Select(i int) (*K, *V) {
+	if i < 0 || i >= t.size {
+		return nil, nil
+	}
+
+	x := t.root
+	for {
+		if i < x.left.size {
+			x = x.left
+		} else if i > x.left.size {
+			i -= x.left.size + 1
+			x = x.right
+		} else {
+			return &x.key, &x.value
+		}
+	}
+}
+
 // Height returns the height of the tree.
 func (t *RedBlackTree[K, V]) Height() int {
 	return t.root.height(t.nil)
@@ -122,63 +333,62 @@ func (t *RedBlackTree[K, V]) Search(key K) (*V, bool) {
 		return nil, false
 	}
 
-	// Traverse the tree
-	x := t.root
-	for x != t.nil {
-		if key == x.key {
-			// Match is found
-			return &x.value, true
-		} else if key < x.key {
-			// Search left subtree
-			x = x.left
-		} else {
-			// Search right subtree
-			x = x.right
-		}
+	x := t.lookup(key)
+	if x == nil {
+		// Not found
+		return nil, false
 	}
 
-	// Not found
-	return nil, false
+	return &x.value, true
 }
 
 // Insert inserts a key-value pair into the tree (only if the key does not already exist).
+// Use InsertMulti to add another value for an existing key.
 // True is returned if the key is inserted.
 // False is returned if the key already exists.
 func (t *RedBlackTree[K, V]) Insert(key K, value V) bool {
-	z := &RedBlackNode[K, V]{
-		key:   key,
-		value: value,
-		left:  t.nil,
-		right: t.nil,
-		color: RED,
+	x, parent, left := t.find(t, key)
+	if x != nil {
+		// Duplicate key, ignore it
+		return false
 	}
-	y := t.nil
-	x := t.root
 
-	for x != t.nil {
-		y = x
-		if z.key == x.key {
-			// Duplicate key, ignore it
-			return false
-		} else if z.key < x.key {
-			x = x.left
-		} else {
-			x = x.right
-		}
+	t.insertAt(position[K, V]{parent: parent, left: left}, key, value)
+	return true
+}
+
+// insertAt adds a node for key under p.parent, on the side p.left gives, as found by locate or find.
+func (t *RedBlackTree[K, V]) insertAt(p position[K, V], key K, value V) {
+	y := t.own(p.parent)
+	z := &RedBlackNode[K, V]{
+		key:    key,
+		value:  value,
+		parent: y,
+		left:   t.nil,
+		right:  t.nil,
+		color:  RED,
+		size:   1,
+		epoch:  t.epoch,
 	}
 
-	z.parent = y
 	if y == t.nil {
 		t.root = z
-	} else if z.key < y.key {
+	} else if p.left {
 		y.left = z
 	} else {
 		y.right = z
 	}
 
+	// Every ancestor of z gained a node
+	for y != t.nil {
+		y.size++
+		y = y.parent
+	}
+	t.augmentPath(z)
+
 	t.insertFixup(z)
 	t.size++
-	return true
+	t.version++
 }
 
 func (t *RedBlackTree[K, V]) insertFixup(z *RedBlackNode[K, V]) {
@@ -188,7 +398,7 @@ func (t *RedBlackTree[K, V]) insertFixup(z *RedBlackNode[K, V]) {
 			y = z.parent.parent.right
 			if y.color == RED {
 				z.parent.color = BLACK
-				y.color = BLACK
+				t.own(y).color = BLACK
 				z.parent.parent.color = RED
 				z = z.parent.parent
 			} else {
@@ -204,7 +414,7 @@ func (t *RedBlackTree[K, V]) insertFixup(z *RedBlackNode[K, V]) {
 			y = z.parent.parent.left
 			if y.color == RED {
 				z.parent.color = BLACK
-				y.color = BLACK
+				t.own(y).color = BLACK
 				z.parent.parent.color = RED
 				z = z.parent.parent
 			} else {
@@ -238,6 +448,14 @@ func (t *RedBlackTree[K, V]) rotateLeft(x *RedBlackNode[K, V]) {
 	}
 	y.left = x
 	x.parent = y
+
+	y.size = x.size
+	x.size = x.left.size + x.right.size + 1
+	if t.augment != nil {
+		// x is now a child of y, so it must be recomputed first
+		t.augment(x)
+		t.augment(y)
+	}
 }
 
 func (t *RedBlackTree[K, V]) rotateRight(x *RedBlackNode[K, V]) {
@@ -256,54 +474,93 @@ func (t *RedBlackTree[K, V]) rotateRight(x *RedBlackNode[K, V]) {
 	}
 	y.right = x
 	x.parent = y
+
+	y.size = x.size
+	x.size = x.left.size + x.right.size + 1
+	if t.augment != nil {
+		// x is now a child of y, so it must be recomputed first
+		t.augment(x)
+		t.augment(y)
+	}
 }
 
-// Delete removes a key from the tree (if it exists).
+// Delete removes a key from the tree (if it exists), along with every value stored under it in a multimap.
 // True is returned if the key is deleted.
 // False is returned if the key does not exist.
 func (t *RedBlackTree[K, V]) Delete(key K) bool {
-	// Find the node to delete
-	z := t.root
-	for z != t.nil {
-		if key == z.key {
-			break
-		} else if key < z.key {
-			z = z.left
-		} else {
-			z = z.right
-		}
-	}
-
-	if z == t.nil {
+	if !t.DeleteOne(key) {
 		// Not found
 		return false
 	}
 
-	// Delete the node
-	t.deleteNode(z)
-	t.size--
+	// A multimap may hold more values for the key
+	for t.multi && t.DeleteOne(key) {
+	}
+
 	return true
 }
 
+// DeleteMin removes the key-value pair with the minimum key and returns it.
+// Nils are returned if the tree is empty.
+func (t *RedBlackTree[K, V]) DeleteMin() (*K, *V) {
+	minimum := t.root.minimum(t.nil)
+	if minimum == nil {
+		return nil, nil
+	}
+
+	t.deleteNode(minimum)
+	t.size--
+	return &minimum.key, &minimum.value
+}
+
+// DeleteMax removes the key-value pair with the maximum key and returns it.
+// Nils are returned if the tree is empty.
+func (t *RedBlackTree[K, V]) DeleteMax() (*K, *V) {
+	maximum := t.root.maximum(t.nil)
+	if maximum == nil {
+		return nil, nil
+	}
+
+	t.deleteNode(maximum)
+	t.size--
+	return &maximum.key, &maximum.value
+}
+
 func (t *RedBlackTree[K, V]) deleteNode(z *RedBlackNode[K, V]) {
+	// Every ancestor of the node that is spliced out loses a node:
+	// z itself if it has at most one child, or otherwise its successor, which takes z's place.
+	// Owning it also owns its ancestors, which covers every node modified below apart from the fixup.
+	z = t.own(z)
+	removed := z
+	if z.left != t.nil && z.right != t.nil {
+		removed = t.own(z.right.minimum(t.nil))
+	}
+	for p := removed.parent; p != t.nil; p = p.parent {
+		p.size--
+	}
+	t.version++
+
 	y := z
 	yOriginalColor := y.color
-	var x *RedBlackNode[K, V]
+	// x moves into the place of the node that is spliced out. It may be the sentinel, which is never modified,
+	// so its new parent is tracked separately
+	var x, xParent *RedBlackNode[K, V]
 
 	if z.left == t.nil {
-		x = z.right
+		x, xParent = z.right, z.parent
 		t.transplant(z, z.right)
 	} else if z.right == t.nil {
-		x = z.left
+		x, xParent = z.left, z.parent
 		t.transplant(z, z.left)
 	} else {
-		y = z.right.minimum(t.nil)
+		y = removed
 		yOriginalColor = y.color
 		x = y.right
 
 		if y.parent == z {
-			x.parent = y
+			xParent = y
 		} else {
+			xParent = y.parent
 			t.transplant(y, y.right)
 			y.right = z.right
 			y.right.parent = y
@@ -313,70 +570,138 @@ func (t *RedBlackTree[K, V]) deleteNode(z *RedBlackNode[K, V]) {
 		y.left = z.left
 		y.left.parent = y
 		y.color = z.color
+		y.size = z.size
 	}
 
+	// xParent is the lowest node whose subtree changed
+	t.augmentPath(xParent)
+
 	if yOriginalColor == BLACK {
-		t.deleteFixup(x)
+		t.deleteFixup(x, xParent)
 	}
 }
 
-func (t *RedBlackTree[K, V]) deleteFixup(x *RedBlackNode[K, V]) {
+// deleteFixup restores the red-black properties after a black node is spliced out from above x.
+// parent is x's parent, which is passed in because x may be the sentinel.
+func (t *RedBlackTree[K, V]) deleteFixup(x, parent *RedBlackNode[K, V]) {
 	for x != t.root && x.color == BLACK {
-		if x == x.parent.left {
-			w := x.parent.right
+		if x == parent.left {
+			w := t.own(parent.right)
 			if w.color == RED {
 				w.color = BLACK
-				x.parent.color = RED
-				t.rotateLeft(x.parent)
-				w = x.parent.right
+				parent.color = RED
+				t.rotateLeft(parent)
+				w = t.own(parent.right)
 			}
 
 			if w.left.color == BLACK && w.right.color == BLACK {
 				w.color = RED
-				x = x.parent
+				x, parent = parent, parent.parent
 			} else {
 				if w.right.color == BLACK {
-					w.left.color = BLACK
+					t.own(w.left).color = BLACK
 					w.color = RED
 					t.rotateRight(w)
-					w = x.parent.right
+					w = parent.right
 				}
 
-				w.color = x.parent.color
-				x.parent.color = BLACK
-				w.right.color = BLACK
-				t.rotateLeft(x.parent)
+				w.color = parent.color
+				parent.color = BLACK
+				t.own(w.right).color = BLACK
+				t.rotateLeft(parent)
 				x = t.root
 			}
 		} else {
-			w := x.parent.left
+			w := t.own(parent.left)
 			if w.color == RED {
 				w.color = BLACK
-				x.parent.color = RED
-				t.rotateRight(x.parent)
-				w = x.parent.left
+				parent.color = RED
+				t.rotateRight(parent)
+				w = t.own(parent.left)
 			}
 
 			if w.right.color == BLACK && w.left.color == BLACK {
 				w.color = RED
-				x = x.parent
+				x, parent = parent, parent.parent
 			} else {
 				if w.left.color == BLACK {
-					w.right.color = BLACK
+					t.own(w.right).color = BLACK
 					w.color = RED
 					t.rotateLeft(w)
-					w = x.parent.left
+					w = parent.left
 				}
-				w.color = x.parent.color
-				x.parent.color = BLACK
-				w.left.color = BLACK
-				t.rotateRight(x.parent)
+				w.color = parent.color
+				parent.color = BLACK
+				t.own(w.left).color = BLACK
+				t.rotateRight(parent)
 				x = t.root
 			}
 		}
 	}
 
-	x.color = BLACK
+	if x != t.nil {
+		t.own(x).color = BLACK
+	}
+}
+
+// own returns x, or if x may be shared with a snapshot, a copy of it that takes its place in the tree,
+// so that the result can be modified without changing any snapshot. Copying x first makes its ancestors owned,
+// so copying a node copies the path to it from the root, and only the first write after a snapshot pays for it.
+// Parent pointers are only used by the tree itself, never by snapshots, so they are updated in place even in shared nodes.
+func (t *RedBlackTree[K, V]) own(x *RedBlackNode[K, V]) *RedBlackNode[K, V] {
+	if x == t.nil || x.epoch == t.epoch {
+		return x
+	}
+
+	parent := t.own(x.parent)
+	c := *x
+	c.parent = parent
+	c.epoch = t.epoch
+	owned := &c
+
+	if parent == t.nil {
+		t.root = owned
+	} else if x == parent.left {
+		parent.left = owned
+	} else {
+		parent.right = owned
+	}
+	if owned.left != t.nil {
+		owned.left.parent = owned
+	}
+	if owned.right != t.nil {
+		owned.right.parent = owned
+	}
+
+	return owned
+}
+
+// augmentPath recomputes the augmentation of x and each of its ancestors.
+func (t *RedBlackTree[K, V]) augmentPath(x *RedBlackNode[K, V]) {
+	if t.augment == nil {
+		return
+	}
+
+	for ; x != t.nil; x = x.parent {
+		t.augment(x)
+	}
+}
+
+// lookup returns the node holding key, or nil if there is none.
+// In a multimap it is the first node holding key.
+func (t *RedBlackTree[K, V]) lookup(key K) *RedBlackNode[K, V] {
+	if !t.multi {
+		// Keys are unique, so the search can stop at the first match
+		x, _, _ := t.find(t, key)
+		return x
+	}
+
+	p := t.locate(t, key, false)
+	if !p.equal {
+		return nil
+	}
+
+	return p.next
 }
 
 func (t *RedBlackTree[K, V]) transplant(u, v *RedBlackNode[K, V]) {
@@ -388,35 +713,40 @@ func (t *RedBlackTree[K, V]) transplant(u, v *RedBlackNode[K, V]) {
 		u.parent.right = v
 	}
 
-	v.parent = u.parent
+	if v != t.nil {
+		v.parent = u.parent
+	}
 }
 
 // Range returns all the key-value pairs whose keys are in the range [low, high].
 func (t *RedBlackTree[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	from, to := t.locate(t, low, false), t.locate(t, high, true)
+
 	var result []collections.Pair[K, V]
-	t.root.rangeHelper(low, high, t.nil, &result)
+	t.visit(t.root, from.rank, to.rank, func(x *RedBlackNode[K, V]) bool {
+		result = append(result, collections.Pair[K, V]{Key: x.key, Value: x.value})
+		return true
+	})
 	return result
 }
 
-func (x *RedBlackNode[K, V]) rangeHelper(low K, high K, nilNode *RedBlackNode[K, V], result *[]collections.Pair[K, V]) {
-	if x == nilNode {
-		return
+// visit calls fn on the nodes of the subtree rooted at x whose in-order index within it is in [from, to), in order,
+// until fn returns false. False is returned if fn stopped the walk.
+// It only follows child links, so unlike step it also works on snapshots.
+func (t *RedBlackTree[K, V]) visit(x *RedBlackNode[K, V], from, to int, fn func(x *RedBlackNode[K, V]) bool) bool {
+	if x == t.nil || from >= to || from >= x.size || to <= 0 {
+		return true
 	}
 
-	if x.key >= low {
-		// Check the left subtree
-		x.left.rangeHelper(low, high, nilNode, result)
+	i := x.left.size
+	if !t.visit(x.left, from, to, fn) {
+		return false
 	}
-
-	if x.key >= low && x.key <= high {
-		// Add the current node to the result since it's within the range
-		*result = append(*result, collections.Pair[K, V]{Key: x.key, Value: x.value})
+	if from <= i && i < to && !fn(x) {
+		return false
 	}
 
-	if x.key <= high {
-		// Check the right subtree
-		x.right.rangeHelper(low, high, nilNode, result)
-	}
+	return t.visit(x.right, from-i-1, to-i-1, fn)
 }
 
 func (t *RedBlackTree[K, V]) Entries() []collections.Pair[K, V] {
@@ -0,0 +1,237 @@
+package tree
+
+import (
+	"errors"
+	"iter"
+)
+
+// ErrConcurrentModification is reported when a RedBlackTree is modified while a cursor or iterator is walking it.
+var ErrConcurrentModification = errors.New("tree: modified during iteration")
+
+// Cursor walks the entries of a RedBlackTree lazily by following parent pointers,
+// so it allocates nothing however many entries it visits.
+// A forward cursor visits keys in ascending order and a reverse cursor in descending order.
+//
+// A new cursor is positioned before its first entry, so Next moves it onto the first entry:
+//
+//	for c := tree.Cursor(); c.Next(); {
+//		fmt.Println(c.Key(), c.Value())
+//	}
+//
+// Inserting into or deleting from the tree invalidates every cursor on it: their next move fails
+// and Err returns ErrConcurrentModification, and Key and Value panic with it, as the iterators do.
+// Seek repositions a cursor and clears the error.
+type Cursor[K any, V any] struct {
+	tree    *RedBlackTree[K, V]
+	reverse bool
+	node    *RedBlackNode[K, V]
+	// position is where the cursor is relative to the entries; node is only set when it is on one.
+	position cursorPosition
+	version  uint64
+	err      error
+}
+
+type cursorPosition int
+
+const (
+	beforeFirst cursorPosition = iota
+	onEntry
+	afterLast
+)
+
+// Cursor returns a cursor that visits the tree's keys in ascending order.
+func (t *RedBlackTree[K, V]) Cursor() *Cursor[K, V] {
+	return &Cursor[K, V]{tree: t, version: t.version}
+}
+
+// ReverseCursor returns a cursor that visits the tree's keys in descending order.
+func (t *RedBlackTree[K, V]) ReverseCursor() *Cursor[K, V] {
+	return &Cursor[K, V]{tree: t, reverse: true, version: t.version}
+}
+
+// Seek moves the cursor to the first entry at or after key in the cursor's direction:
+// the least key greater than or equal to key for a forward cursor, and the greatest key less than or equal to key for a reverse one.
+// False is returned, and the cursor is left after its last entry, if there is no such entry.
+func (c *Cursor[K, V]) Seek(key K) bool {
+	c.version = c.tree.version
+	c.err = nil
+
+	found := c.tree.locate(c.tree, key, false).next
+	if c.reverse {
+		found = c.tree.locate(c.tree, key, true).prev
+	}
+
+	return c.moveTo(found, afterLast)
+}
+
+// Next moves the cursor to the following entry in its direction.
+// False is returned if there is none or the tree has been modified.
+func (c *Cursor[K, V]) Next() bool {
+	if !c.check() {
+		return false
+	}
+
+	switch c.position {
+	case beforeFirst:
+		return c.moveTo(c.tree.edge(c.reverse), afterLast)
+	case onEntry:
+		return c.moveTo(c.tree.step(c.node, c.reverse), afterLast)
+	default:
+		return false
+	}
+}
+
+// Prev moves the cursor to the preceding entry in its direction.
+// False is returned if there is none or the tree has been modified.
+func (c *Cursor[K, V]) Prev() bool {
+	if !c.check() {
+		return false
+	}
+
+	switch c.position {
+	case afterLast:
+		return c.moveTo(c.tree.edge(!c.reverse), beforeFirst)
+	case onEntry:
+		return c.moveTo(c.tree.step(c.node, !c.reverse), beforeFirst)
+	default:
+		return false
+	}
+}
+
+// Valid reports whether the cursor is positioned on an entry of the unmodified tree.
+func (c *Cursor[K, V]) Valid() bool {
+	return c.check() && c.position == onEntry
+}
+
+// Key returns the key of the entry under the cursor.
+// It panics if the cursor is not positioned on an entry,
+// and with ErrConcurrentModification if the tree has been modified.
+func (c *Cursor[K, V]) Key() K {
+	return c.entry().key
+}
+
+// Value returns the value of the entry under the cursor.
+// It panics if the cursor is not positioned on an entry,
+// and with ErrConcurrentModification if the tree has been modified.
+func (c *Cursor[K, V]) Value() V {
+	return c.entry().value
+}
+
+// Err returns ErrConcurrentModification if a move failed because the tree was modified, and nil otherwise.
+func (c *Cursor[K, V]) Err() error {
+	return c.err
+}
+
+func (c *Cursor[K, V]) entry() *RedBlackNode[K, V] {
+	if !c.check() {
+		panic(c.err)
+	}
+	if c.position != onEntry {
+		panic("tree: cursor is not positioned on an entry")
+	}
+
+	return c.node
+}
+
+// moveTo positions the cursor on x, or at the given end if x is nil.
+func (c *Cursor[K, V]) moveTo(x *RedBlackNode[K, V], end cursorPosition) bool {
+	if x == nil {
+		c.node, c.position = nil, end
+		return false
+	}
+
+	c.node, c.position = x, onEntry
+	return true
+}
+
+// check invalidates the cursor if the tree has been modified since it was positioned.
+func (c *Cursor[K, V]) check() bool {
+	if c.err == nil && c.version != c.tree.version {
+		c.err = ErrConcurrentModification
+		c.node, c.position = nil, beforeFirst
+	}
+
+	return c.err == nil
+}
+
+// edge returns the node with the minimum key, or the maximum key if last is set, or nil if the tree is empty.
+func (t *RedBlackTree[K, V]) edge(last bool) *RedBlackNode[K, V] {
+	if last {
+		return t.root.maximum(t.nil)
+	}
+
+	return t.root.minimum(t.nil)
+}
+
+// step returns the in-order successor of x, or its predecessor if backward is set, or nil if there is none.
+func (t *RedBlackTree[K, V]) step(x *RedBlackNode[K, V], backward bool) *RedBlackNode[K, V] {
+	if backward {
+		if x.left != t.nil {
+			return x.left.maximum(t.nil)
+		}
+
+		// Climb until we arrive from a right subtree
+		for x.parent != t.nil && x == x.parent.left {
+			x = x.parent
+		}
+	} else {
+		if x.right != t.nil {
+			return x.right.minimum(t.nil)
+		}
+
+		// Climb until we arrive from a left subtree
+		for x.parent != t.nil && x == x.parent.right {
+			x = x.parent
+		}
+	}
+
+	if x.parent == t.nil {
+		return nil
+	}
+
+	return x.parent
+}
+
+// All returns an iterator over the key-value pairs in ascending order of key.
+// It panics with ErrConcurrentModification if the tree is modified during iteration.
+func (t *RedBlackTree[K, V]) All() iter.Seq2[K, V] {
+	return t.iterate(false, (*Cursor[K, V]).Next)
+}
+
+// Backward returns an iterator over the key-value pairs in descending order of key.
+// It panics with ErrConcurrentModification if the tree is modified during iteration.
+func (t *RedBlackTree[K, V]) Backward() iter.Seq2[K, V] {
+	return t.iterate(true, (*Cursor[K, V]).Next)
+}
+
+// Ascend returns an iterator over the key-value pairs with keys greater than or equal to from, in ascending order.
+// It panics with ErrConcurrentModification if the tree is modified during iteration.
+func (t *RedBlackTree[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return t.iterate(false, func(c *Cursor[K, V]) bool {
+		return c.Seek(from)
+	})
+}
+
+// Descend returns an iterator over the key-value pairs with keys less than or equal to from, in descending order.
+// It panics with ErrConcurrentModification if the tree is modified during iteration.
+func (t *RedBlackTree[K, V]) Descend(from K) iter.Seq2[K, V] {
+	return t.iterate(true, func(c *Cursor[K, V]) bool {
+		return c.Seek(from)
+	})
+}
+
+// iterate yields the entries visited by a new cursor, positioned on its first entry by start.
+func (t *RedBlackTree[K, V]) iterate(reverse bool, start func(c *Cursor[K, V]) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c := &Cursor[K, V]{tree: t, reverse: reverse, version: t.version}
+		for ok := start(c); ok; ok = c.Next() {
+			if !yield(c.node.key, c.node.value) {
+				return
+			}
+		}
+
+		if c.err != nil {
+			panic(c.err)
+		}
+	}
+}
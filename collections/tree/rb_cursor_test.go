@@ -0,0 +1,237 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func ExampleCursor() {
+	tree := NewRedBlackTree[int, string]()
+	for i, name := range []string{"zero", "one", "two", "three", "four"} {
+		tree.Insert(i*10, name)
+	}
+
+	// Walk down from 25 without building a slice of the whole range
+	c := tree.ReverseCursor()
+	for ok := c.Seek(25); ok; ok = c.Next() {
+		fmt.Println(c.Key(), c.Value())
+	}
+	// Output:
+	// 20 two
+	// 10 one
+	// 0 zero
+}
+
+func TestCursor_NextAndPrev(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 1; i <= 3; i++ {
+		tree.Insert(i, i*100)
+	}
+
+	c := tree.Cursor()
+	if c.Valid() || c.Prev() {
+		t.Fatalf("a new cursor should be before the first entry")
+	}
+
+	var keys []int
+	for c.Next() {
+		keys = append(keys, c.Key())
+	}
+	if !slices.Equal(keys, []int{1, 2, 3}) {
+		t.Errorf("Next() visited %v, want %v", keys, []int{1, 2, 3})
+	}
+
+	// Stepping back from past the end lands on the last entry
+	if !c.Prev() || c.Key() != 3 || c.Value() != 300 {
+		t.Errorf("Prev() after the end did not move to the last entry")
+	}
+	if !c.Prev() || c.Key() != 2 || !c.Next() || c.Key() != 3 {
+		t.Errorf("Prev() then Next() did not return to the same entry")
+	}
+	if c.Next() || c.Valid() || c.Err() != nil {
+		t.Errorf("Next() past the last entry = true or reported an error")
+	}
+}
+
+func TestCursor_Seek(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 0; i < 100; i += 10 {
+		tree.Insert(i, i)
+	}
+
+	tests := []struct {
+		reverse bool
+		key     int
+		want    int
+		found   bool
+	}{
+		{false, 40, 40, true},
+		{false, 41, 50, true},
+		{false, -5, 0, true},
+		{false, 91, 0, false},
+		{true, 40, 40, true},
+		{true, 41, 40, true},
+		{true, 95, 90, true},
+		{true, -1, 0, false},
+	}
+
+	for _, test := range tests {
+		c := tree.Cursor()
+		if test.reverse {
+			c = tree.ReverseCursor()
+		}
+
+		found := c.Seek(test.key)
+		if found != test.found || found && c.Key() != test.want {
+			t.Errorf("Seek(%v) with reverse %v = %v, want %v at %v", test.key, test.reverse, found, test.found, test.want)
+		}
+	}
+}
+
+func TestCursor_DetectsModification(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 0; i < 10; i++ {
+		tree.Insert(i, i)
+	}
+
+	c := tree.Cursor()
+	c.Next()
+	tree.Delete(5)
+
+	if c.Next() || c.Valid() || !errors.Is(c.Err(), ErrConcurrentModification) {
+		t.Fatalf("Next() after Delete = true or Err() = %v, want ErrConcurrentModification", c.Err())
+	}
+	if c.Prev() {
+		t.Errorf("Prev() after an error = true, want false")
+	}
+
+	// Seek resynchronizes the cursor
+	if !c.Seek(4) || c.Err() != nil || !c.Next() || c.Key() != 6 {
+		t.Errorf("Seek() did not clear the error and reposition the cursor")
+	}
+
+	// A failed insert does not modify the tree
+	tree.Insert(6, 0)
+	if !c.Next() || c.Key() != 7 {
+		t.Errorf("Next() after inserting a duplicate key failed with %v", c.Err())
+	}
+}
+
+func TestCursor_KeyPanicsWhenNotOnAnEntry(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Key() on a new cursor did not panic")
+		}
+	}()
+	NewRedBlackTree[int, int]().Cursor().Key()
+}
+
+func TestCursor_KeyPanicsOnModification(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 0; i < 10; i++ {
+		tree.Insert(i, i)
+	}
+
+	c := tree.Cursor()
+	c.Next()
+	tree.Delete(5)
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrConcurrentModification) {
+			t.Errorf("recovered %v, want ErrConcurrentModification", err)
+		}
+	}()
+	c.Key()
+}
+
+// Property: cursors and iterators visit exactly the entries in key order, in both directions and from any key
+func TestRedBlackTree_IteratorsMatchEntries(t *testing.T) {
+	t.Parallel()
+	f := func(keys []int8, from int8) bool {
+		tree := NewRedBlackTree[int8, int]()
+		for i, k := range keys {
+			tree.Insert(k, i)
+		}
+		entries := tree.Entries()
+
+		var forward, backward, ascend, descend []int8
+		for k := range tree.All() {
+			forward = append(forward, k)
+		}
+		for k := range tree.Backward() {
+			backward = append(backward, k)
+		}
+		for k := range tree.Ascend(from) {
+			ascend = append(ascend, k)
+		}
+		for k := range tree.Descend(from) {
+			descend = append(descend, k)
+		}
+
+		var want, wantAscend, wantDescend []int8
+		for _, p := range entries {
+			want = append(want, p.Key)
+			if p.Key >= from {
+				wantAscend = append(wantAscend, p.Key)
+			} else {
+				wantDescend = append([]int8{p.Key}, wantDescend...)
+			}
+		}
+		if _, found := tree.Search(from); found {
+			wantDescend = append([]int8{from}, wantDescend...)
+		}
+
+		reversed := slices.Clone(want)
+		slices.Reverse(reversed)
+		return slices.Equal(forward, want) && slices.Equal(backward, reversed) &&
+			slices.Equal(ascend, wantAscend) && slices.Equal(descend, wantDescend)
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedBlackTree_IteratorPanicsOnModification(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 0; i < 10; i++ {
+		tree.Insert(i, i)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrConcurrentModification) {
+			t.Errorf("recovered %v, want ErrConcurrentModification", err)
+		}
+	}()
+	for k := range tree.All() {
+		tree.Delete(k)
+	}
+}
+
+func TestRedBlackTree_IteratorStopsEarly(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i, i)
+	}
+
+	var keys []int
+	for k := range tree.Ascend(500) {
+		if len(keys) == 3 {
+			break
+		}
+		keys = append(keys, k)
+	}
+	if !slices.Equal(keys, []int{500, 501, 502}) {
+		t.Errorf("Ascend(500) yielded %v, want %v", keys, []int{500, 501, 502})
+	}
+}
@@ -0,0 +1,419 @@
+package tree
+
+import (
+	"cmp"
+	"math/bits"
+	"practice/collections"
+)
+
+// The operations in this file follow 'Just Join for Parallel Ordered Sets' by Blelloch et al.:
+// Split and the set operations are built on join, which links two trees and a middle node in time proportional
+// to the difference between their black heights. The black height of a subtree is the number of black nodes
+// on every path from its root down to the sentinel, and is passed alongside it rather than stored in the nodes.
+
+// FromSorted builds a tree holding entries, whose keys must be in strictly ascending order, in O(n) time.
+// It panics if they are not.
+func FromSorted[K cmp.Ordered, V any](entries []collections.Pair[K, V]) *RedBlackTree[K, V] {
+	t := NewRedBlackTree[K, V]()
+	t.build(entries)
+	return t
+}
+
+// FromSortedFunc builds a tree ordered by compare holding entries, whose keys must be in strictly ascending order, in O(n) time.
+// It panics if they are not.
+func FromSortedFunc[K any, V any](entries []collections.Pair[K, V], compare func(a, b K) int) *RedBlackTree[K, V] {
+	t := NewRedBlackTreeFunc[K, V](compare)
+	t.build(entries)
+	return t
+}
+
+func (t *RedBlackTree[K, V]) build(entries []collections.Pair[K, V]) {
+	for i := 1; i < len(entries); i++ {
+		if t.compare(entries[i-1].Key, entries[i].Key) >= 0 {
+			panic("tree: entries are not in strictly ascending order of key")
+		}
+	}
+
+	// Splitting at the middle puts every sentinel on one of the two deepest levels,
+	// so coloring the deepest level of nodes red leaves every path with the same number of black nodes
+	t.setRoot(t.buildRange(entries, 0, bits.Len(uint(len(entries)))-1))
+}
+
+func (t *RedBlackTree[K, V]) buildRange(entries []collections.Pair[K, V], depth, redDepth int) *RedBlackNode[K, V] {
+	if len(entries) == 0 {
+		return t.nil
+	}
+
+	mid := len(entries) / 2
+	x := &RedBlackNode[K, V]{
+		key:   entries[mid].Key,
+		value: entries[mid].Value,
+		color: depth == redDepth,
+		epoch: t.epoch,
+	}
+
+	return t.link(x, t.buildRange(entries[:mid], depth+1, redDepth), t.buildRange(entries[mid+1:], depth+1, redDepth))
+}
+
+// Join returns a tree holding the entries of t1 followed by the entries of t2, in O(log n) time.
+// Every key in t2 must be greater than every key in t1, or equal to the greatest if either is a multimap,
+// and both trees must be ordered the same way. Join links the nodes of both trees together, so it leaves t1 and t2 empty.
+// It panics if the keys overlap.
+func Join[K any, V any](t1, t2 *RedBlackTree[K, V]) *RedBlackTree[K, V] {
+	t := combine(t1, t2)
+	if !t1.IsEmpty() && !t2.IsEmpty() {
+		maximum, minimum := t1.root.maximum(t1.nil), t2.root.minimum(t2.nil)
+		if c := t.compare(maximum.key, minimum.key); c > 0 || c == 0 && !t.multi {
+			panic("tree: joined trees overlap")
+		}
+	}
+
+	root, _ := t.join2(t1.root, t1.blackHeight(), t2.root, t2.blackHeight())
+	return t.finish(root, t1, t2)
+}
+
+// Split moves every entry with a key greater than or equal to key into a new tree, which it returns,
+// and leaves the entries with lesser keys in t. It takes O(log n) time.
+func (t *RedBlackTree[K, V]) Split(key K) *RedBlackTree[K, V] {
+	right := &RedBlackTree[K, V]{}
+	*right = *t
+	right.version = 0
+
+	l, _, r, _ := t.splitAt(t.root, t.blackHeight(), t.locate(t, key, false).rank)
+	t.setRoot(l)
+	right.setRoot(r)
+	return right
+}
+
+// Union returns a tree holding every entry in t1 or t2, keeping the value from t1 for keys in both.
+// For trees of sizes m <= n it takes O(m log(n/m + 1)) time. Like Join, it reuses the nodes of both trees and leaves them empty.
+// It panics if either tree is a multimap.
+func Union[K any, V any](t1, t2 *RedBlackTree[K, V]) *RedBlackTree[K, V] {
+	t := combineSets(t1, t2)
+	root, _ := t.union(t1.root, t1.blackHeight(), t2.root, t2.blackHeight())
+	return t.finish(root, t1, t2)
+}
+
+// Intersection returns a tree holding the entries of t1 whose keys are also in t2.
+// For trees of sizes m <= n it takes O(m log(n/m + 1)) time. Like Join, it reuses the nodes of both trees and leaves them empty.
+// It panics if either tree is a multimap.
+func Intersection[K any, V any](t1, t2 *RedBlackTree[K, V]) *RedBlackTree[K, V] {
+	t := combineSets(t1, t2)
+	root, _ := t.intersection(t1.root, t1.blackHeight(), t2.root, t2.blackHeight())
+	return t.finish(root, t1, t2)
+}
+
+// Difference returns a tree holding the entries of t1 whose keys are not in t2.
+// For trees of sizes m <= n it takes O(m log(n/m + 1)) time. Like Join, it reuses the nodes of both trees and leaves them empty.
+// It panics if either tree is a multimap.
+func Difference[K any, V any](t1, t2 *RedBlackTree[K, V]) *RedBlackTree[K, V] {
+	t := combineSets(t1, t2)
+	root, _ := t.difference(t1.root, t1.blackHeight(), t2.root, t2.blackHeight())
+	return t.finish(root, t1, t2)
+}
+
+// combine returns an empty tree ordered like t1 in which the nodes of t1 and t2 can be linked together.
+func combine[K any, V any](t1, t2 *RedBlackTree[K, V]) *RedBlackTree[K, V] {
+	if t1 == t2 {
+		panic("tree: cannot combine a tree with itself")
+	}
+
+	return &RedBlackTree[K, V]{
+		root:    t1.nil,
+		nil:     t1.nil,
+		augment: t1.augment,
+		find:    t1.find,
+		locate:  t1.locate,
+		compare: t1.compare,
+		multi:   t1.multi || t2.multi,
+		// Nodes from either tree may be shared with their snapshots, so all of them must be copied before they are modified
+		epoch: max(t1.epoch, t2.epoch) + 1,
+	}
+}
+
+func combineSets[K any, V any](t1, t2 *RedBlackTree[K, V]) *RedBlackTree[K, V] {
+	if t1.multi || t2.multi {
+		panic("tree: set operations do not support multimaps")
+	}
+
+	return combine(t1, t2)
+}
+
+// finish makes root the root of t and empties the trees whose nodes it was built from.
+func (t *RedBlackTree[K, V]) finish(root *RedBlackNode[K, V], consumed ...*RedBlackTree[K, V]) *RedBlackTree[K, V] {
+	t.setRoot(root)
+	for _, c := range consumed {
+		c.setRoot(c.nil)
+	}
+
+	return t
+}
+
+// setRoot makes x, which may be red, the root of the tree.
+func (t *RedBlackTree[K, V]) setRoot(x *RedBlackNode[K, V]) {
+	if x.color == RED {
+		x = t.mut(x)
+		x.color = BLACK
+	}
+	if x != t.nil {
+		x.parent = t.nil
+	}
+
+	t.root = x
+	t.size = x.size
+	t.version++
+}
+
+func (t *RedBlackTree[K, V]) blackHeight() int {
+	h := 0
+	for x := t.root; x != t.nil; x = x.left {
+		if x.color == BLACK {
+			h++
+		}
+	}
+
+	return h
+}
+
+// childHeight returns the black height of the children of x, given the black height h of x.
+func childHeight[K any, V any](x *RedBlackNode[K, V], h int) int {
+	if x.color == BLACK {
+		return h - 1
+	}
+
+	return h
+}
+
+// mut returns x, or if x may be shared with a snapshot, a copy of it whose children point back to it.
+// Unlike own, it does not link the copy into the tree: the caller links it in place of x.
+func (t *RedBlackTree[K, V]) mut(x *RedBlackNode[K, V]) *RedBlackNode[K, V] {
+	if x == t.nil || x.epoch == t.epoch {
+		return x
+	}
+
+	c := *x
+	c.epoch = t.epoch
+	return t.link(&c, c.left, c.right)
+}
+
+// link makes l and r the children of x, which must be owned, and recomputes its size and augmentation.
+func (t *RedBlackTree[K, V]) link(x, l, r *RedBlackNode[K, V]) *RedBlackNode[K, V] {
+	x.left, x.right = l, r
+	if l != t.nil {
+		l.parent = x
+	}
+	if r != t.nil {
+		r.parent = x
+	}
+
+	x.size = l.size + r.size + 1
+	if t.augment != nil {
+		t.augment(x)
+	}
+	return x
+}
+
+// join returns a subtree holding l, then k, then r, along with its black height,
+// given that the keys are in order and l and r have black heights hl and hr.
+// It takes O(|hl - hr| + 1) time and the root it returns may be red.
+func (t *RedBlackTree[K, V]) join(l *RedBlackNode[K, V], hl int, k, r *RedBlackNode[K, V], hr int) (*RedBlackNode[K, V], int) {
+	k = t.mut(k)
+	// Coloring a root black keeps the red-black properties and gives join black roots to work with
+	if l.color == RED {
+		l = t.mut(l)
+		l.color = BLACK
+		hl++
+	}
+	if r.color == RED {
+		r = t.mut(r)
+		r.color = BLACK
+		hr++
+	}
+
+	switch {
+	case hl > hr:
+		x := t.joinRight(l, hl, k, r, hr)
+		if x.color == RED && x.right.color == RED {
+			x.color = BLACK
+			hl++
+		}
+		return x, hl
+	case hl < hr:
+		x := t.joinLeft(l, hl, k, r, hr)
+		if x.color == RED && x.left.color == RED {
+			x.color = BLACK
+			hr++
+		}
+		return x, hr
+	default:
+		k.color = RED
+		return t.link(k, l, r), hl
+	}
+}
+
+// joinRight descends the right spine of l to the black node with the same black height as r, and puts k there with r as
+// its right child. It repairs any red node with a red child on the way back up, except possibly at the root it returns.
+func (t *RedBlackTree[K, V]) joinRight(l *RedBlackNode[K, V], hl int, k, r *RedBlackNode[K, V], hr int) *RedBlackNode[K, V] {
+	if l.color == BLACK && hl == hr {
+		k.color = RED
+		return t.link(k, l, r)
+	}
+
+	l = t.mut(l)
+	right := t.joinRight(l.right, childHeight(l, hl), k, r, hr)
+	t.link(l, l.left, right)
+
+	if l.color == BLACK && right.color == RED && right.right.color == RED {
+		grandchild := t.mut(right.right)
+		grandchild.color = BLACK
+		t.link(right, right.left, grandchild)
+		return t.rotatedLeft(l)
+	}
+	return l
+}
+
+// joinLeft mirrors joinRight.
+func (t *RedBlackTree[K, V]) joinLeft(l *RedBlackNode[K, V], hl int, k, r *RedBlackNode[K, V], hr int) *RedBlackNode[K, V] {
+	if r.color == BLACK && hl == hr {
+		k.color = RED
+		return t.link(k, l, r)
+	}
+
+	r = t.mut(r)
+	left := t.joinLeft(l, hl, k, r.left, childHeight(r, hr))
+	t.link(r, left, r.right)
+
+	if r.color == BLACK && left.color == RED && left.left.color == RED {
+		grandchild := t.mut(left.left)
+		grandchild.color = BLACK
+		t.link(left, grandchild, left.right)
+		return t.rotatedRight(r)
+	}
+	return r
+}
+
+// rotatedLeft rotates the subtree rooted at x, which must be owned along with its right child, and returns its new root.
+func (t *RedBlackTree[K, V]) rotatedLeft(x *RedBlackNode[K, V]) *RedBlackNode[K, V] {
+	y := x.right
+	t.link(x, x.left, y.left)
+	return t.link(y, x, y.right)
+}
+
+// rotatedRight mirrors rotatedLeft.
+func (t *RedBlackTree[K, V]) rotatedRight(x *RedBlackNode[K, V]) *RedBlackNode[K, V] {
+	y := x.left
+	t.link(x, y.right, x.right)
+	return t.link(y, y.left, x)
+}
+
+// join2 joins l and r without a middle node by taking the last node of l as the middle.
+func (t *RedBlackTree[K, V]) join2(l *RedBlackNode[K, V], hl int, r *RedBlackNode[K, V], hr int) (*RedBlackNode[K, V], int) {
+	if l == t.nil {
+		return r, hr
+	}
+
+	rest, h, last := t.splitLast(l, hl)
+	return t.join(rest, h, last, r, hr)
+}
+
+// splitLast removes the last node from the subtree rooted at x, whose black height is h,
+// and returns the rest of the subtree with its black height, and the node.
+func (t *RedBlackTree[K, V]) splitLast(x *RedBlackNode[K, V], h int) (*RedBlackNode[K, V], int, *RedBlackNode[K, V]) {
+	hc := childHeight(x, h)
+	if x.right == t.nil {
+		return x.left, hc, x
+	}
+
+	rest, hr, last := t.splitLast(x.right, hc)
+	joined, hj := t.join(x.left, hc, x, rest, hr)
+	return joined, hj, last
+}
+
+// splitAt splits the subtree rooted at x, whose black height is h, into its first i nodes and the rest,
+// returning both with their black heights.
+func (t *RedBlackTree[K, V]) splitAt(x *RedBlackNode[K, V], h, i int) (*RedBlackNode[K, V], int, *RedBlackNode[K, V], int) {
+	if x == t.nil {
+		return t.nil, 0, t.nil, 0
+	}
+
+	hc := childHeight(x, h)
+	left, right := x.left, x.right
+	if i <= left.size {
+		l, hl, r, hr := t.splitAt(left, hc, i)
+		joined, hj := t.join(r, hr, x, right, hc)
+		return l, hl, joined, hj
+	}
+
+	l, hl, r, hr := t.splitAt(right, hc, i-left.size-1)
+	joined, hj := t.join(left, hc, x, l, hl)
+	return joined, hj, r, hr
+}
+
+// split splits the subtree rooted at x, whose black height is h, into the nodes with keys less than key and
+// those with keys greater than it, returning both with their black heights, along with the node holding key if there is one.
+func (t *RedBlackTree[K, V]) split(x *RedBlackNode[K, V], h int, key K) (*RedBlackNode[K, V], int, *RedBlackNode[K, V], *RedBlackNode[K, V], int) {
+	if x == t.nil {
+		return t.nil, 0, nil, t.nil, 0
+	}
+
+	hc := childHeight(x, h)
+	left, right := x.left, x.right
+	c := t.compare(key, x.key)
+	switch {
+	case c < 0:
+		l, hl, found, r, hr := t.split(left, hc, key)
+		joined, hj := t.join(r, hr, x, right, hc)
+		return l, hl, found, joined, hj
+	case c > 0:
+		l, hl, found, r, hr := t.split(right, hc, key)
+		joined, hj := t.join(left, hc, x, l, hl)
+		return joined, hj, found, r, hr
+	default:
+		return left, hc, x, right, hc
+	}
+}
+
+func (t *RedBlackTree[K, V]) union(a *RedBlackNode[K, V], ha int, b *RedBlackNode[K, V], hb int) (*RedBlackNode[K, V], int) {
+	if a == t.nil {
+		return b, hb
+	}
+	if b == t.nil {
+		return a, ha
+	}
+
+	hc := childHeight(a, ha)
+	left, right := a.left, a.right
+	bl, hbl, _, br, hbr := t.split(b, hb, a.key)
+	l, hl := t.union(left, hc, bl, hbl)
+	r, hr := t.union(right, hc, br, hbr)
+	return t.join(l, hl, a, r, hr)
+}
+
+func (t *RedBlackTree[K, V]) intersection(a *RedBlackNode[K, V], ha int, b *RedBlackNode[K, V], hb int) (*RedBlackNode[K, V], int) {
+	if a == t.nil || b == t.nil {
+		return t.nil, 0
+	}
+
+	hc := childHeight(a, ha)
+	left, right := a.left, a.right
+	bl, hbl, found, br, hbr := t.split(b, hb, a.key)
+	l, hl := t.intersection(left, hc, bl, hbl)
+	r, hr := t.intersection(right, hc, br, hbr)
+	if found != nil {
+		return t.join(l, hl, a, r, hr)
+	}
+	return t.join2(l, hl, r, hr)
+}
+
+func (t *RedBlackTree[K, V]) difference(a *RedBlackNode[K, V], ha int, b *RedBlackNode[K, V], hb int) (*RedBlackNode[K, V], int) {
+	if a == t.nil || b == t.nil {
+		return a, ha
+	}
+
+	hc := childHeight(b, hb)
+	left, right := b.left, b.right
+	al, hal, _, ar, har := t.split(a, ha, b.key)
+	l, hl := t.difference(al, hal, left, hc)
+	r, hr := t.difference(ar, har, right, hc)
+	return t.join2(l, hl, r, hr)
+}
@@ -0,0 +1,232 @@
+package tree
+
+import (
+	"fmt"
+	"maps"
+	"practice/collections"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func ExampleRedBlackTree_Split() {
+	tree := NewRedBlackTree[int, string]()
+	for i, name := range []string{"zero", "one", "two", "three", "four"} {
+		tree.Insert(i, name)
+	}
+
+	high := tree.Split(2)
+	fmt.Println(tree.Entries(), high.Entries())
+
+	tree = Join(tree, high)
+	fmt.Println(tree.Size(), high.Size())
+	// Output:
+	// [{0 zero} {1 one}] [{2 two} {3 three} {4 four}]
+	// 5 0
+}
+
+func ExampleUnion() {
+	a := FromSorted([]collections.Pair[string, int]{{Key: "ant", Value: 1}, {Key: "bee", Value: 1}})
+	b := FromSorted([]collections.Pair[string, int]{{Key: "bee", Value: 2}, {Key: "cat", Value: 2}})
+
+	fmt.Println(Union(a, b).Entries())
+	// Output:
+	// [{ant 1} {bee 1} {cat 2}]
+}
+
+// valid reports whether the tree keeps the red-black properties, its sizes and its parent pointers.
+func (t *RedBlackTree[K, V]) valid() bool {
+	return t.root.color == BLACK && t.root.parent == t.nil && t.check(t.root) != -1 && t.root.size == t.size
+}
+
+// treeOf builds a tree of the given keys through Insert, with each value equal to its key plus offset.
+func treeOf(keys []uint8, offset int) (*RedBlackTree[int, int], map[int]int) {
+	tree := NewRedBlackTree[int, int]()
+	model := map[int]int{}
+	for _, k := range keys {
+		tree.Insert(int(k), int(k)+offset)
+		model[int(k)] = int(k) + offset
+	}
+	return tree, model
+}
+
+func entriesOf(model map[int]int) []collections.Pair[int, int] {
+	var entries []collections.Pair[int, int]
+	for _, k := range slices.Sorted(maps.Keys(model)) {
+		entries = append(entries, collections.Pair[int, int]{Key: k, Value: model[k]})
+	}
+	return entries
+}
+
+func TestFromSorted(t *testing.T) {
+	t.Parallel()
+	for n := range 300 {
+		entries := make([]collections.Pair[int, int], n)
+		for i := range entries {
+			entries[i] = collections.Pair[int, int]{Key: 2 * i, Value: i}
+		}
+
+		tree := FromSorted(entries)
+		if !tree.valid() || !slices.Equal(tree.Entries(), entries) {
+			t.Fatalf("FromSorted() of %v entries built an invalid tree", n)
+		}
+
+		// The tree must keep working after a bulk load
+		tree.Insert(1, 0)
+		tree.Delete(0)
+		if !tree.valid() {
+			t.Fatalf("updating a tree built by FromSorted() from %v entries broke it", n)
+		}
+	}
+}
+
+func TestFromSortedPanicsOnUnsortedEntries(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("FromSorted() of entries with a repeated key did not panic")
+		}
+	}()
+	FromSorted([]collections.Pair[int, int]{{Key: 1}, {Key: 1}})
+}
+
+func TestJoinPanicsOnOverlappingTrees(t *testing.T) {
+	t.Parallel()
+	t1, _ := treeOf([]uint8{1, 5}, 0)
+	t2, _ := treeOf([]uint8{3, 9}, 0)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Join() of overlapping trees did not panic")
+		}
+	}()
+	Join(t1, t2)
+}
+
+// Property: splitting a tree at any key and joining the halves back gives valid trees with the right entries
+func TestRedBlackTree_SplitAndJoin(t *testing.T) {
+	t.Parallel()
+	f := func(keys []uint8, at uint8) bool {
+		tree, model := treeOf(keys, 0)
+		entries := entriesOf(model)
+		i, _ := slices.BinarySearchFunc(entries, int(at), func(p collections.Pair[int, int], k int) int { return p.Key - k })
+
+		high := tree.Split(int(at))
+		if !tree.valid() || !high.valid() || !slices.Equal(tree.Entries(), entries[:i]) || !slices.Equal(high.Entries(), entries[i:]) {
+			return false
+		}
+
+		joined := Join(tree, high)
+		return joined.valid() && slices.Equal(joined.Entries(), entries) && tree.IsEmpty() && high.IsEmpty()
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+// Property: joining trees of very different sizes gives a valid tree
+func TestJoinUnbalancedTrees(t *testing.T) {
+	t.Parallel()
+	f := func(small []uint8, large uint16) bool {
+		left, right := NewRedBlackTree[int, int](), NewRedBlackTree[int, int]()
+		for _, k := range small {
+			left.Insert(int(k), 0)
+		}
+		for k := range int(large % 2048) {
+			right.Insert(256+k, 0)
+		}
+		n := left.Size() + right.Size()
+
+		if joined := Join(left, right); !joined.valid() || joined.Size() != n {
+			return false
+		}
+		// The other way round the larger tree is on the left
+		left, right = NewRedBlackTree[int, int](), NewRedBlackTree[int, int]()
+		for k := range int(large % 2048) {
+			left.Insert(-k, 0)
+		}
+		for _, k := range small {
+			right.Insert(int(k)+1, 0)
+		}
+		joined := Join(left, right)
+		return joined.valid() && joined.Size() == n
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 100}); err != nil {
+		t.Error(err)
+	}
+}
+
+// Property: the set operations match maps, keep the first tree's values, build valid trees and leave snapshots of their inputs alone
+func TestRedBlackTree_SetOperations(t *testing.T) {
+	t.Parallel()
+	operations := []struct {
+		name  string
+		apply func(t1, t2 *RedBlackTree[int, int]) *RedBlackTree[int, int]
+		model func(m1, m2 map[int]int) map[int]int
+	}{
+		{"Union", Union[int, int], func(m1, m2 map[int]int) map[int]int {
+			result := maps.Clone(m2)
+			maps.Copy(result, m1)
+			return result
+		}},
+		{"Intersection", Intersection[int, int], func(m1, m2 map[int]int) map[int]int {
+			result := maps.Clone(m1)
+			maps.DeleteFunc(result, func(k, _ int) bool { _, ok := m2[k]; return !ok })
+			return result
+		}},
+		{"Difference", Difference[int, int], func(m1, m2 map[int]int) map[int]int {
+			result := maps.Clone(m1)
+			maps.DeleteFunc(result, func(k, _ int) bool { _, ok := m2[k]; return ok })
+			return result
+		}},
+	}
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			t.Parallel()
+			f := func(keys1, keys2 []uint8) bool {
+				t1, m1 := treeOf(keys1, 1000)
+				t2, m2 := treeOf(keys2, 2000)
+				s1, s2 := t1.Snapshot(), t2.Snapshot()
+
+				result := op.apply(t1, t2)
+				if !result.valid() || !slices.Equal(result.Entries(), entriesOf(op.model(m1, m2))) {
+					return false
+				}
+
+				// Updating the result must not disturb the snapshots either
+				result.Insert(-1, 0)
+				result.DeleteMax()
+				return result.valid() && t1.IsEmpty() && t2.IsEmpty() &&
+					slices.Equal(s1.Entries(), entriesOf(m1)) && slices.Equal(s2.Entries(), entriesOf(m2))
+			}
+
+			if err := quick.Check(f, &quick.Config{MaxCount: 300}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func BenchmarkBulkLoad(b *testing.B) {
+	entries := make([]collections.Pair[int, int], 1<<20)
+	for i := range entries {
+		entries[i] = collections.Pair[int, int]{Key: i, Value: i}
+	}
+
+	b.Run("FromSorted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			FromSorted(entries)
+		}
+	})
+
+	b.Run("Insert", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree := NewRedBlackTree[int, int]()
+			for _, e := range entries {
+				tree.Insert(e.Key, e.Value)
+			}
+		}
+	})
+}
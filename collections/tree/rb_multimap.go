@@ -0,0 +1,35 @@
+package tree
+
+// InsertMulti inserts a key-value pair into the tree even if the key already exists, which makes the tree a multimap.
+// The pair is placed after every pair with an equal key, so values stored under a key keep their insertion order.
+// Once a tree is a multimap, Size, Rank, Select, Range and the iterators count and visit every pair,
+// Search returns the first value stored under a key and Delete removes all of them.
+func (t *RedBlackTree[K, V]) InsertMulti(key K, value V) {
+	t.multi = true
+	t.insertAt(t.locate(t, key, true), key, value)
+}
+
+// SearchAll returns every value stored under key, in insertion order.
+func (t *RedBlackTree[K, V]) SearchAll(key K) []V {
+	from, to := t.locate(t, key, false), t.locate(t, key, true)
+
+	var values []V
+	t.visit(t.root, from.rank, to.rank, func(x *RedBlackNode[K, V]) bool {
+		values = append(values, x.value)
+		return true
+	})
+	return values
+}
+
+// DeleteOne removes the first value stored under key (if it exists), leaving any others in place.
+// True is returned if a value is deleted.
+func (t *RedBlackTree[K, V]) DeleteOne(key K) bool {
+	x := t.lookup(key)
+	if x == nil {
+		return false
+	}
+
+	t.deleteNode(x)
+	t.size--
+	return true
+}
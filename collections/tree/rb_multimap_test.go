@@ -0,0 +1,143 @@
+package tree
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func ExampleNewRedBlackTreeFunc() {
+	type version struct{ major, minor int }
+
+	// Newest version first
+	releases := NewRedBlackTreeFunc[version, string](func(a, b version) int {
+		return cmp.Or(cmp.Compare(b.major, a.major), cmp.Compare(b.minor, a.minor))
+	})
+	releases.Insert(version{1, 2}, "stable")
+	releases.Insert(version{2, 0}, "beta")
+	releases.Insert(version{1, 10}, "lts")
+
+	for v, name := range releases.All() {
+		fmt.Printf("%d.%d %s\n", v.major, v.minor, name)
+	}
+	// Output:
+	// 2.0 beta
+	// 1.10 lts
+	// 1.2 stable
+}
+
+func ExampleRedBlackTree_InsertMulti() {
+	words := NewRedBlackTree[int, string]()
+	for _, w := range strings.Fields("go is a fun and tidy language") {
+		words.InsertMulti(len(w), w)
+	}
+
+	fmt.Println(words.SearchAll(3))
+	words.DeleteOne(3)
+	fmt.Println(words.SearchAll(3), words.Size())
+	// Output:
+	// [fun and]
+	// [and] 6
+}
+
+func TestRedBlackTreeFunc_CaseInsensitiveKeys(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTreeFunc[string, int](func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	tree.Insert("Go", 1)
+	if tree.Insert("GO", 2) {
+		t.Errorf("Insert(GO) succeeded for a key equal to Go")
+	}
+	if v, ok := tree.Search("go"); !ok || *v != 1 {
+		t.Errorf("Search(go) = %v, %v, want 1, true", v, ok)
+	}
+	if k, _ := tree.Ceiling("b"); k == nil || *k != "Go" {
+		t.Errorf("Ceiling(b) = %v, want Go", k)
+	}
+}
+
+// Property: a multimap matches a sorted slice of pairs in which equal keys keep their insertion order,
+// for trees of ordered keys and trees built with a comparison function alike
+func TestRedBlackTree_MultimapMatchesModel(t *testing.T) {
+	t.Parallel()
+	trees := map[string]func() *RedBlackTree[int, int]{
+		"Ordered": NewRedBlackTree[int, int],
+		"Func":    func() *RedBlackTree[int, int] { return NewRedBlackTreeFunc[int, int](cmp.Compare[int]) },
+	}
+
+	for name, newTree := range trees {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f := func(ops []uint8) bool {
+				tree := newTree()
+				type pair struct{ key, value int }
+				var model []pair
+
+				// first returns the index of the first pair with key and the number of pairs with it
+				first := func(key int) (int, int) {
+					i, _ := slices.BinarySearchFunc(model, key, func(p pair, key int) int { return cmp.Compare(p.key, key) })
+					n := 0
+					for i+n < len(model) && model[i+n].key == key {
+						n++
+					}
+					return i, n
+				}
+
+				for i, op := range ops {
+					key := int(op % 16)
+					at, n := first(key)
+
+					switch op / 16 % 4 {
+					case 0, 1:
+						tree.InsertMulti(key, i)
+						model = slices.Insert(model, at+n, pair{key, i})
+					case 2:
+						if tree.DeleteOne(key) != (n > 0) {
+							return false
+						}
+						if n > 0 {
+							model = slices.Delete(model, at, at+1)
+						}
+					case 3:
+						if tree.Delete(key) != (n > 0) {
+							return false
+						}
+						model = slices.Delete(model, at, at+n)
+					}
+
+					at, n = first(key)
+					var want []int
+					for _, p := range model[at : at+n] {
+						want = append(want, p.value)
+					}
+					if !slices.Equal(tree.SearchAll(key), want) || tree.Rank(key) != at {
+						return false
+					}
+					if v, ok := tree.Search(key); ok != (n > 0) || ok && *v != want[0] {
+						return false
+					}
+					if tree.Size() != len(model) || tree.check(tree.root) == -1 {
+						return false
+					}
+				}
+
+				i := 0
+				for k, v := range tree.All() {
+					if model[i] != (pair{k, v}) {
+						return false
+					}
+					i++
+				}
+				return i == len(model) && len(tree.Range(4, 11)) == tree.Rank(12)-tree.Rank(4)
+			}
+
+			if err := quick.Check(f, &quick.Config{MaxCount: 300}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package tree
+
+import (
+	"iter"
+	"practice/collections"
+)
+
+// Snapshot is a read-only view of a RedBlackTree as it was when Snapshot was called.
+// It never changes, however the tree is modified afterwards, and any number of goroutines may read it
+// while another goroutine keeps modifying the tree.
+type Snapshot[K any, V any] struct {
+	tree RedBlackTree[K, V]
+}
+
+// Snapshot returns a read-only view of the tree's current contents in O(1) time.
+// The snapshot shares its nodes with the tree; afterwards the tree copies a node, along with the path to it
+// from the root, the first time it modifies it. So each write after a snapshot copies O(log n) nodes and
+// the writes that follow it on the same paths copy nothing, which makes it cheap to snapshot after every batch of writes.
+//
+// Values are shared rather than copied: modifying a value through a pointer returned by Search changes it in every snapshot that holds it.
+func (t *RedBlackTree[K, V]) Snapshot() *Snapshot[K, V] {
+	// Every existing node now belongs to the snapshot as well
+	t.epoch++
+
+	return &Snapshot[K, V]{tree: RedBlackTree[K, V]{
+		root:    t.root,
+		nil:     t.nil,
+		size:    t.size,
+		find:    t.find,
+		locate:  t.locate,
+		compare: t.compare,
+		multi:   t.multi,
+	}}
+}
+
+func (s *Snapshot[K, V]) Size() int {
+	return s.tree.Size()
+}
+
+func (s *Snapshot[K, V]) IsEmpty() bool {
+	return s.tree.IsEmpty()
+}
+
+func (s *Snapshot[K, V]) Search(key K) (*V, bool) {
+	return s.tree.Search(key)
+}
+
+// SearchAll returns every value stored under key, in insertion order.
+func (s *Snapshot[K, V]) SearchAll(key K) []V {
+	return s.tree.SearchAll(key)
+}
+
+// Minimum returns the key-value pair with the minimum key.
+func (s *Snapshot[K, V]) Minimum() (*K, *V) {
+	return s.tree.Minimum()
+}
+
+// Maximum returns the key-value pair with the maximum key.
+func (s *Snapshot[K, V]) Maximum() (*K, *V) {
+	return s.tree.Maximum()
+}
+
+// Floor returns the key-value pair with the greatest key less than or equal to key.
+func (s *Snapshot[K, V]) Floor(key K) (*K, *V) {
+	return s.tree.Floor(key)
+}
+
+// Ceiling returns the key-value pair with the least key greater than or equal to key.
+func (s *Snapshot[K, V]) Ceiling(key K) (*K, *V) {
+	return s.tree.Ceiling(key)
+}
+
+// Predecessor returns the key-value pair with the greatest key strictly less than key.
+func (s *Snapshot[K, V]) Predecessor(key K) (*K, *V) {
+	return s.tree.Predecessor(key)
+}
+
+// Successor returns the key-value pair with the least key strictly greater than key.
+func (s *Snapshot[K, V]) Successor(key K) (*K, *V) {
+	return s.tree.Successor(key)
+}
+
+// Rank returns the number of keys less than key.
+func (s *Snapshot[K, V]) Rank(key K) int {
+	return s.tree.Rank(key)
+}
+
+// Select returns the key-value pair whose key has rank i.
+// Nils are returned if i is out of range.
+func (s *Snapshot[K, V]) Select(i int) (*K, *V) {
+	return s.tree.Select(i)
+}
+
+// Range returns all the key-value pairs whose keys are in the range [low, high].
+func (s *Snapshot[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	return s.tree.Range(low, high)
+}
+
+func (s *Snapshot[K, V]) Entries() []collections.Pair[K, V] {
+	return s.tree.Entries()
+}
+
+// All returns an iterator over the key-value pairs in ascending order of key.
+// Unlike the tree's iterators it follows child links rather than parent pointers, which the tree keeps modifying.
+func (s *Snapshot[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.tree.visit(s.tree.root, 0, s.tree.size, func(x *RedBlackNode[K, V]) bool {
+			return yield(x.key, x.value)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package tree
+
+import (
+	"fmt"
+	"maps"
+	"practice/collections"
+	"slices"
+	"sync"
+	"testing"
+	"testing/quick"
+)
+
+func ExampleRedBlackTree_Snapshot() {
+	prices := NewRedBlackTree[string, int]()
+	prices.Insert("apple", 3)
+	prices.Insert("pear", 4)
+
+	before := prices.Snapshot()
+	prices.Delete("apple")
+	prices.Insert("plum", 5)
+
+	fmt.Println(before.Entries())
+	fmt.Println(prices.Entries())
+	// Output:
+	// [{apple 3} {pear 4}]
+	// [{pear 4} {plum 5}]
+}
+
+// owned counts the nodes of the subtree rooted at x created or copied since the last snapshot.
+func (t *RedBlackTree[K, V]) owned(x *RedBlackNode[K, V]) int {
+	if x == t.nil {
+		return 0
+	}
+
+	n := t.owned(x.left) + t.owned(x.right)
+	if x.epoch == t.epoch {
+		n++
+	}
+	return n
+}
+
+func TestRedBlackTree_SnapshotCopiesOnlyChangedPaths(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := range 1 << 12 {
+		tree.Insert(i, i)
+	}
+
+	for i := range 100 {
+		tree.Snapshot()
+		if i%2 == 0 {
+			tree.Insert(1<<12+i, i)
+		} else {
+			tree.Delete(i * 37)
+		}
+
+		// Besides the path to the change, the fixup may recolor or rotate one sibling per level
+		if n := tree.owned(tree.root); n > 2*tree.Height()+2 {
+			t.Fatalf("a write after a snapshot copied %v nodes of a tree of height %v", n, tree.Height())
+		}
+	}
+}
+
+// Property: snapshots taken between batches of updates keep matching the tree's contents at the time they were taken,
+// while the tree itself keeps its invariants
+func TestRedBlackTree_SnapshotsNeverChange(t *testing.T) {
+	t.Parallel()
+	f := func(ops []int16) bool {
+		tree := NewRedBlackTree[int16, int]()
+		model := map[int16]int{}
+
+		type version struct {
+			snapshot *Snapshot[int16, int]
+			entries  []collections.Pair[int16, int]
+		}
+		var versions []version
+
+		for i, op := range ops {
+			switch {
+			case op%5 == 0:
+				tree.DeleteMin()
+			case op < 0:
+				tree.Delete(-op % 64)
+			default:
+				tree.Insert(op%64, i)
+			}
+
+			if i%3 == 0 {
+				versions = append(versions, version{tree.Snapshot(), tree.Entries()})
+			}
+			if tree.check(tree.root) == -1 {
+				return false
+			}
+		}
+
+		for _, v := range versions {
+			var all []collections.Pair[int16, int]
+			for k, value := range v.snapshot.All() {
+				all = append(all, collections.Pair[int16, int]{Key: k, Value: value})
+			}
+			if !slices.Equal(all, v.entries) || !slices.Equal(v.snapshot.Entries(), v.entries) || v.snapshot.Size() != len(v.entries) {
+				return false
+			}
+
+			clear(model)
+			for _, p := range v.entries {
+				model[p.Key] = p.Value
+			}
+			for key := range int16(64) {
+				value, ok := v.snapshot.Search(key)
+				if want, exists := model[key]; ok != exists || ok && *value != want {
+					return false
+				}
+			}
+			if len(v.entries) > 0 && len(v.snapshot.Range(16, 47)) != v.snapshot.Rank(48)-v.snapshot.Rank(16) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedBlackTree_SnapshotsReadWhileWriting(t *testing.T) {
+	t.Parallel()
+	const batches, batchSize = 200, 32
+	tree := NewRedBlackTree[int, int]()
+	snapshots := make(chan *Snapshot[int, int], batches)
+
+	// Batch b inserts its own keys and deletes those of batch b-2, so every snapshot holds exactly two batches
+	go func() {
+		defer close(snapshots)
+		for b := range batches {
+			for i := range batchSize {
+				tree.Insert(b*batchSize+i, b)
+				if b >= 2 {
+					tree.Delete((b-2)*batchSize + i)
+				}
+			}
+			snapshots <- tree.Snapshot()
+		}
+	}()
+
+	var readers sync.WaitGroup
+	errs := make(chan error, batches)
+	for s := range snapshots {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			last, _ := s.Maximum()
+			b := *last / batchSize
+			want := map[int]int{}
+			for i := max(b-1, 0) * batchSize; i <= *last; i++ {
+				want[i] = i / batchSize
+			}
+			if got := maps.Collect(s.All()); !maps.Equal(got, want) {
+				errs <- fmt.Errorf("snapshot after batch %v holds %v keys, want %v", b, len(got), len(want))
+			}
+		}()
+	}
+
+	readers.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -2,6 +2,7 @@ package tree
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 	"testing/quick"
 )
@@ -482,3 +483,194 @@ func TestRedBlackTree_ExtremeTest(t *testing.T) {
 		}
 	}
 }
+
+// check verifies the red-black properties, key order and subtree sizes of the subtree rooted at x,
+// returning its black height, or -1 if a property is violated. Equal keys are allowed for multimaps.
+func (t *RedBlackTree[K, V]) check(x *RedBlackNode[K, V]) int {
+	if x == t.nil {
+		if x.size != 0 {
+			return -1
+		}
+		return 1
+	}
+
+	if x.color == RED && (x.left.color == RED || x.right.color == RED) {
+		return -1
+	}
+	if x.size != x.left.size+x.right.size+1 {
+		return -1
+	}
+	if x.left != t.nil && (x.left.parent != x || t.compare(x.left.key, x.key) > 0) {
+		return -1
+	}
+	if x.right != t.nil && (x.right.parent != x || t.compare(x.right.key, x.key) < 0) {
+		return -1
+	}
+
+	left, right := t.check(x.left), t.check(x.right)
+	if left == -1 || left != right {
+		return -1
+	}
+	if x.color == BLACK {
+		return left + 1
+	}
+	return left
+}
+
+func TestRedBlackTree_InvariantsHoldAfterUpdates(t *testing.T) {
+	t.Parallel()
+	f := func(ops []int16) bool {
+		tree := NewRedBlackTree[int16, int16]()
+		for _, op := range ops {
+			switch {
+			case op%7 == 0:
+				tree.DeleteMin()
+			case op%11 == 0:
+				tree.DeleteMax()
+			case op < 0:
+				tree.Delete(-op % 128)
+			default:
+				tree.Insert(op%128, op)
+			}
+
+			if tree.root.color != BLACK || tree.check(tree.root) == -1 || tree.root.size != tree.Size() {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedBlackTree_FloorAndCeiling(t *testing.T) {
+	t.Parallel()
+	// Readings taken every ten seconds
+	tree := NewRedBlackTree[int, string]()
+	tree.Insert(0, "a")
+	tree.Insert(10, "b")
+	tree.Insert(20, "c")
+
+	if key, value := tree.Floor(15); *key != 10 || *value != "b" {
+		t.Errorf("Floor(15) = %v, %v, want %v, %v", *key, *value, 10, "b")
+	}
+	if key, _ := tree.Floor(20); *key != 20 {
+		t.Errorf("Floor(20) = %v, want %v", *key, 20)
+	}
+	if key, _ := tree.Floor(-1); key != nil {
+		t.Errorf("Floor(-1) = %v, want nil", *key)
+	}
+	if key, _ := tree.Ceiling(15); *key != 20 {
+		t.Errorf("Ceiling(15) = %v, want %v", *key, 20)
+	}
+	if key, _ := tree.Ceiling(21); key != nil {
+		t.Errorf("Ceiling(21) = %v, want nil", *key)
+	}
+}
+
+func TestRedBlackTree_PredecessorAndSuccessor(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 0; i < 10; i += 2 {
+		tree.Insert(i, i*100)
+	}
+
+	if key, value := tree.Predecessor(4); *key != 2 || *value != 200 {
+		t.Errorf("Predecessor(4) = %v, %v, want %v, %v", *key, *value, 2, 200)
+	}
+	if key, _ := tree.Predecessor(5); *key != 4 {
+		t.Errorf("Predecessor(5) = %v, want %v", *key, 4)
+	}
+	if key, _ := tree.Predecessor(0); key != nil {
+		t.Errorf("Predecessor(0) = %v, want nil", *key)
+	}
+	if key, value := tree.Successor(4); *key != 6 || *value != 600 {
+		t.Errorf("Successor(4) = %v, %v, want %v, %v", *key, *value, 6, 600)
+	}
+	if key, _ := tree.Successor(8); key != nil {
+		t.Errorf("Successor(8) = %v, want nil", *key)
+	}
+}
+
+func TestRedBlackTree_DeleteMinAndMax(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	if key, value := tree.DeleteMin(); key != nil || value != nil {
+		t.Errorf("DeleteMin() on an empty tree = %v, %v, want nil, nil", key, value)
+	}
+	if key, value := tree.DeleteMax(); key != nil || value != nil {
+		t.Errorf("DeleteMax() on an empty tree = %v, %v, want nil, nil", key, value)
+	}
+
+	for i := 1; i <= 5; i++ {
+		tree.Insert(i, i*100)
+	}
+
+	if key, value := tree.DeleteMin(); *key != 1 || *value != 100 {
+		t.Errorf("DeleteMin() = %v, %v, want %v, %v", *key, *value, 1, 100)
+	}
+	if key, value := tree.DeleteMax(); *key != 5 || *value != 500 {
+		t.Errorf("DeleteMax() = %v, %v, want %v, %v", *key, *value, 5, 500)
+	}
+	if tree.Size() != 3 {
+		t.Errorf("Size() = %v, want %v", tree.Size(), 3)
+	}
+	if key, _ := tree.Minimum(); *key != 2 {
+		t.Errorf("Minimum() = %v, want %v", *key, 2)
+	}
+}
+
+func TestRedBlackTree_RankAndSelect(t *testing.T) {
+	t.Parallel()
+	tree := NewRedBlackTree[int, int]()
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i*3, i)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if rank := tree.Rank(i * 3); rank != i {
+			t.Fatalf("Rank(%v) = %v, want %v", i*3, rank, i)
+		}
+		if rank := tree.Rank(i*3 + 1); rank != i+1 {
+			t.Fatalf("Rank(%v) = %v, want %v", i*3+1, rank, i+1)
+		}
+		if key, value := tree.Select(i); *key != i*3 || *value != i {
+			t.Fatalf("Select(%v) = %v, %v, want %v, %v", i, *key, *value, i*3, i)
+		}
+	}
+
+	if key, _ := tree.Select(1000); key != nil {
+		t.Errorf("Select(1000) = %v, want nil", *key)
+	}
+	if key, _ := tree.Select(-1); key != nil {
+		t.Errorf("Select(-1) = %v, want nil", *key)
+	}
+}
+
+// BenchmarkRedBlackTree_Ordered measures the cmp.Ordered fast path, which compares keys directly.
+func BenchmarkRedBlackTree_Ordered(b *testing.B) {
+	keys := rand.New(rand.NewSource(1)).Perm(1 << 16)
+
+	b.Run("Insert", func(b *testing.B) {
+		tree := NewRedBlackTree[int, int]()
+		for i := 0; i < b.N; i++ {
+			if i%len(keys) == 0 {
+				tree = NewRedBlackTree[int, int]()
+			}
+			tree.Insert(keys[i%len(keys)], i)
+		}
+	})
+
+	b.Run("Search", func(b *testing.B) {
+		tree := NewRedBlackTree[int, int]()
+		for _, k := range keys {
+			tree.Insert(k, k)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			tree.Search(keys[i%len(keys)])
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package tree
+
+import (
+	"cmp"
+	"math/rand"
+	"practice/collections"
+)
+
+// maxLevel bounds the height of skip list nodes. With a promotion probability of 1/4
+// it comfortably covers lists of 4^maxLevel elements.
+const maxLevel = 20
+
+// randomLevel returns the height of a new skip list node, which is k with probability (3/4)(1/4)^(k-1).
+func randomLevel() int {
+	level := 1
+	for level < maxLevel && rand.Uint32()&3 == 0 {
+		level++
+	}
+	return level
+}
+
+// SkipList is an ordered map built from layers of sorted linked lists.
+// Every key is stored on the bottom layer and each layer above skips over roughly three quarters
+// of the keys below it, giving expected O(log n) searches, inserts and deletes.
+// Like RedBlackTree, it does not allow duplicate keys.
+type SkipList[K cmp.Ordered, V any] struct {
+	head *skipNode[K, V]
+	// level is the number of layers currently in use.
+	level int
+	size  int
+}
+
+type skipNode[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+	// next holds the following node on each layer the node belongs to.
+	next []*skipNode[K, V]
+}
+
+func NewSkipList[K cmp.Ordered, V any]() *SkipList[K, V] {
+	return &SkipList[K, V]{
+		head:  &skipNode[K, V]{next: make([]*skipNode[K, V], maxLevel)},
+		level: 1,
+	}
+}
+
+func (s *SkipList[K, V]) Size() int {
+	return s.size
+}
+
+func (s *SkipList[K, V]) IsEmpty() bool {
+	return s.Size() == 0
+}
+
+// Minimum returns the key-value pair with the minimum key.
+func (s *SkipList[K, V]) Minimum() (*K, *V) {
+	first := s.head.next[0]
+	if first == nil {
+		return nil, nil
+	}
+
+	return &first.key, &first.value
+}
+
+// Maximum returns the key-value pair with the maximum key.
+func (s *SkipList[K, V]) Maximum() (*K, *V) {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil {
+			x = x.next[i]
+		}
+	}
+
+	if x == s.head {
+		return nil, nil
+	}
+
+	return &x.key, &x.value
+}
+
+func (s *SkipList[K, V]) Search(key K) (*V, bool) {
+	x := s.predecessor(key, nil).next[0]
+	if x == nil || x.key != key {
+		return nil, false
+	}
+
+	return &x.value, true
+}
+
+// Insert inserts a key-value pair into the list (only if the key does not already exist).
+// True is returned if the key is inserted.
+// False is returned if the key already exists.
+func (s *SkipList[K, V]) Insert(key K, value V) bool {
+	var update [maxLevel]*skipNode[K, V]
+	x := s.predecessor(key, &update).next[0]
+	if x != nil && x.key == key {
+		return false
+	}
+
+	level := randomLevel()
+	for i := s.level; i < level; i++ {
+		update[i] = s.head
+	}
+	s.level = max(s.level, level)
+
+	n := &skipNode[K, V]{
+		key:   key,
+		value: value,
+		next:  make([]*skipNode[K, V], level),
+	}
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+	}
+
+	s.size++
+	return true
+}
+
+// Delete removes a key from the list (if it exists).
+// True is returned if the key is deleted.
+// False is returned if the key does not exist.
+func (s *SkipList[K, V]) Delete(key K) bool {
+	var update [maxLevel]*skipNode[K, V]
+	x := s.predecessor(key, &update).next[0]
+	if x == nil || x.key != key {
+		return false
+	}
+
+	for i := range x.next {
+		update[i].next[i] = x.next[i]
+	}
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+
+	s.size--
+	return true
+}
+
+// Range returns all the key-value pairs whose keys are in the range [low, high].
+func (s *SkipList[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	for x := s.predecessor(low, nil).next[0]; x != nil && x.key <= high; x = x.next[0] {
+		result = append(result, collections.Pair[K, V]{Key: x.key, Value: x.value})
+	}
+	return result
+}
+
+func (s *SkipList[K, V]) Entries() []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	for x := s.head.next[0]; x != nil; x = x.next[0] {
+		result = append(result, collections.Pair[K, V]{Key: x.key, Value: x.value})
+	}
+	return result
+}
+
+// predecessor returns the last node whose key is less than key, or the head if there is none.
+// If update is not nil, it is filled with the last such node on every layer in use.
+func (s *SkipList[K, V]) predecessor(key K, update *[maxLevel]*skipNode[K, V]) *skipNode[K, V] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && x.next[i].key < key {
+			x = x.next[i]
+		}
+		if update != nil {
+			update[i] = x
+		}
+	}
+	return x
+}
@@ -0,0 +1,255 @@
+package tree
+
+import (
+	"cmp"
+	"iter"
+	"math/rand"
+	"practice/collections"
+)
+
+// Treap is an OrderedMap that is a binary search tree by key and a heap by a random priority drawn for every node.
+// The random priorities make its shape that of a tree built from a random insertion order,
+// so operations take O(log n) expected time whatever order keys arrive in.
+// Every node also records the size of its subtree, so Rank and Select run in O(log n) expected time.
+type Treap[K cmp.Ordered, V any] struct {
+	root *treapNode[K, V]
+}
+
+type treapNode[K cmp.Ordered, V any] struct {
+	key         K
+	value       V
+	priority    uint32
+	left, right *treapNode[K, V]
+	size        int
+}
+
+func NewTreap[K cmp.Ordered, V any]() *Treap[K, V] {
+	return &Treap[K, V]{}
+}
+
+func (t *Treap[K, V]) Size() int {
+	return t.root.count()
+}
+
+func (t *Treap[K, V]) IsEmpty() bool {
+	return t.root == nil
+}
+
+func (t *Treap[K, V]) Search(key K) (*V, bool) {
+	for x := t.root; x != nil; {
+		switch {
+		case key < x.key:
+			x = x.left
+		case key > x.key:
+			x = x.right
+		default:
+			return &x.value, true
+		}
+	}
+	return nil, false
+}
+
+func (t *Treap[K, V]) Insert(key K, value V) bool {
+	if _, found := t.Search(key); found {
+		return false
+	}
+
+	less, greater := t.root.split(key)
+	n := &treapNode[K, V]{key: key, value: value, priority: rand.Uint32(), size: 1}
+	t.root = mergeTreaps(mergeTreaps(less, n), greater)
+	return true
+}
+
+func (t *Treap[K, V]) Delete(key K) bool {
+	if _, found := t.Search(key); !found {
+		return false
+	}
+
+	t.root = t.root.delete(key)
+	return true
+}
+
+func (t *Treap[K, V]) Minimum() (*K, *V) {
+	x := t.root
+	for x != nil && x.left != nil {
+		x = x.left
+	}
+	return x.entry()
+}
+
+func (t *Treap[K, V]) Maximum() (*K, *V) {
+	x := t.root
+	for x != nil && x.right != nil {
+		x = x.right
+	}
+	return x.entry()
+}
+
+func (t *Treap[K, V]) Floor(key K) (*K, *V) {
+	var floor *treapNode[K, V]
+	for x := t.root; x != nil; {
+		switch {
+		case key < x.key:
+			x = x.left
+		case key > x.key:
+			floor, x = x, x.right
+		default:
+			return x.entry()
+		}
+	}
+	return floor.entry()
+}
+
+func (t *Treap[K, V]) Ceiling(key K) (*K, *V) {
+	var ceiling *treapNode[K, V]
+	for x := t.root; x != nil; {
+		switch {
+		case key < x.key:
+			ceiling, x = x, x.left
+		case key > x.key:
+			x = x.right
+		default:
+			return x.entry()
+		}
+	}
+	return ceiling.entry()
+}
+
+func (t *Treap[K, V]) Rank(key K) int {
+	rank := 0
+	for x := t.root; x != nil; {
+		if key <= x.key {
+			x = x.left
+		} else {
+			rank += x.left.count() + 1
+			x = x.right
+		}
+	}
+	return rank
+}
+
+func (t *Treap[K, V]) Select(i int) (*K, *V) {
+	if i < 0 || i >= t.Size() {
+		return nil, nil
+	}
+
+	x := t.root
+	for {
+		left := x.left.count()
+		switch {
+		case i < left:
+			x = x.left
+		case i > left:
+			i -= left + 1
+			x = x.right
+		default:
+			return x.entry()
+		}
+	}
+}
+
+func (t *Treap[K, V]) Range(low K, high K) []collections.Pair[K, V] {
+	var result []collections.Pair[K, V]
+	t.root.each(low, high, &result)
+	return result
+}
+
+func (t *Treap[K, V]) Entries() []collections.Pair[K, V] {
+	result := make([]collections.Pair[K, V], 0, t.Size())
+	for k, v := range t.All() {
+		result = append(result, collections.Pair[K, V]{Key: k, Value: v})
+	}
+	return result
+}
+
+func (t *Treap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.root.all(yield)
+	}
+}
+
+// split divides the treap rooted at n into the nodes with keys less than key and those with keys greater than it.
+func (n *treapNode[K, V]) split(key K) (less, greater *treapNode[K, V]) {
+	if n == nil {
+		return nil, nil
+	}
+
+	if n.key < key {
+		n.right, greater = n.right.split(key)
+		n.update()
+		return n, greater
+	}
+	less, n.left = n.left.split(key)
+	n.update()
+	return less, n
+}
+
+// mergeTreaps joins two treaps where every key in a is less than every key in b.
+func mergeTreaps[K cmp.Ordered, V any](a, b *treapNode[K, V]) *treapNode[K, V] {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.priority > b.priority:
+		a.right = mergeTreaps(a.right, b)
+		a.update()
+		return a
+	default:
+		b.left = mergeTreaps(a, b.left)
+		b.update()
+		return b
+	}
+}
+
+// delete removes key, which must be present, from the treap rooted at n and returns the new root.
+func (n *treapNode[K, V]) delete(key K) *treapNode[K, V] {
+	switch {
+	case key < n.key:
+		n.left = n.left.delete(key)
+	case key > n.key:
+		n.right = n.right.delete(key)
+	default:
+		return mergeTreaps(n.left, n.right)
+	}
+	n.update()
+	return n
+}
+
+func (n *treapNode[K, V]) update() {
+	n.size = n.left.count() + n.right.count() + 1
+}
+
+func (n *treapNode[K, V]) count() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *treapNode[K, V]) entry() (*K, *V) {
+	if n == nil {
+		return nil, nil
+	}
+	return &n.key, &n.value
+}
+
+func (n *treapNode[K, V]) each(low, high K, result *[]collections.Pair[K, V]) {
+	if n == nil {
+		return
+	}
+	if n.key > low {
+		n.left.each(low, high, result)
+	}
+	if n.key >= low && n.key <= high {
+		*result = append(*result, collections.Pair[K, V]{Key: n.key, Value: n.value})
+	}
+	if n.key < high {
+		n.right.each(low, high, result)
+	}
+}
+
+// all yields the entries of the subtree in ascending order, returning false once yield does.
+func (n *treapNode[K, V]) all(yield func(K, V) bool) bool {
+	return n == nil || n.left.all(yield) && yield(n.key, n.value) && n.right.all(yield)
+}
@@ -0,0 +1,40 @@
+package tree
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// heapOrdered reports whether no node of the subtree has a higher priority than its parent and sizes are correct.
+func (n *treapNode[K, V]) heapOrdered() bool {
+	if n == nil {
+		return true
+	}
+	if n.left != nil && n.left.priority > n.priority || n.right != nil && n.right.priority > n.priority {
+		return false
+	}
+	return n.size == n.left.count()+n.right.count()+1 && n.left.heapOrdered() && n.right.heapOrdered()
+}
+
+// Property: the treap stays heap-ordered by priority through any sequence of inserts and deletes
+func TestTreap_StaysHeapOrdered(t *testing.T) {
+	t.Parallel()
+	f := func(ops []int16) bool {
+		tree := NewTreap[int16, struct{}]()
+		for _, op := range ops {
+			if op < 0 {
+				tree.Delete(-op)
+			} else {
+				tree.Insert(op, struct{}{})
+			}
+			if !tree.root.heapOrdered() {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
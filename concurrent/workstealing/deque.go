@@ -0,0 +1,118 @@
+package workstealing
+
+import "sync/atomic"
+
+// Deque is a Chase-Lev work-stealing deque ("Dynamic Circular Work-Stealing Deque", 2005).
+// A single owner pushes and pops at the bottom like a stack, while any number of thieves
+// steal from the top. The owner only contends with thieves when one item is left.
+// Items are pointers so that a nil result can mean "nothing to take".
+type Deque[T any] struct {
+	top    atomic.Int64
+	bottom atomic.Int64
+	array  atomic.Pointer[ring[T]]
+}
+
+// ring is a circular array whose slots are atomic, because a thief may read a slot that
+// the owner is about to reuse (the thief's compare-and-swap then fails).
+type ring[T any] struct {
+	slots []atomic.Pointer[T]
+}
+
+func newRing[T any](size int64) *ring[T] {
+	return &ring[T]{slots: make([]atomic.Pointer[T], size)}
+}
+
+func (r *ring[T]) size() int64 {
+	return int64(len(r.slots))
+}
+
+func (r *ring[T]) get(i int64) *T {
+	return r.slots[i%r.size()].Load()
+}
+
+func (r *ring[T]) put(i int64, x *T) {
+	r.slots[i%r.size()].Store(x)
+}
+
+// grow returns a ring twice as large holding the items in [top, bottom).
+func (r *ring[T]) grow(top, bottom int64) *ring[T] {
+	bigger := newRing[T](2 * r.size())
+	for i := top; i < bottom; i++ {
+		bigger.put(i, r.get(i))
+	}
+	return bigger
+}
+
+// NewDeque returns an empty deque.
+func NewDeque[T any]() *Deque[T] {
+	d := &Deque[T]{}
+	d.array.Store(newRing[T](32))
+	return d
+}
+
+// Len returns the number of items in the deque. It is only a snapshot while thieves are active.
+func (d *Deque[T]) Len() int {
+	n := d.bottom.Load() - d.top.Load()
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+// Push adds x at the bottom. Only the owner may call it.
+func (d *Deque[T]) Push(x *T) {
+	b := d.bottom.Load()
+	t := d.top.Load()
+	a := d.array.Load()
+
+	if b-t >= a.size()-1 {
+		a = a.grow(t, b)
+		d.array.Store(a)
+	}
+
+	a.put(b, x)
+	d.bottom.Store(b + 1)
+}
+
+// Pop removes and returns the item at the bottom, or nil if the deque is empty.
+// Only the owner may call it.
+func (d *Deque[T]) Pop() *T {
+	b := d.bottom.Load() - 1
+	a := d.array.Load()
+	// Reserve the bottom item before looking at top, so thieves see the reservation.
+	d.bottom.Store(b)
+	t := d.top.Load()
+
+	if t > b {
+		// The deque was empty.
+		d.bottom.Store(b + 1)
+		return nil
+	}
+
+	x := a.get(b)
+	if t == b {
+		// Last item: race the thieves for it.
+		if !d.top.CompareAndSwap(t, t+1) {
+			x = nil
+		}
+		d.bottom.Store(b + 1)
+	}
+	return x
+}
+
+// Steal removes and returns the item at the top, or nil if the deque is empty or another
+// goroutine took the item first. Any goroutine may call it.
+func (d *Deque[T]) Steal() *T {
+	t := d.top.Load()
+	b := d.bottom.Load()
+	if t >= b {
+		return nil
+	}
+
+	a := d.array.Load()
+	x := a.get(t)
+	if !d.top.CompareAndSwap(t, t+1) {
+		return nil
+	}
+	return x
+}
@@ -0,0 +1,84 @@
+package workstealing
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDeque_OwnerIsLIFO(t *testing.T) {
+	t.Parallel()
+	d := NewDeque[int]()
+	for i := 0; i < 100; i++ {
+		v := i
+		d.Push(&v)
+	}
+
+	for want := 99; want >= 0; want-- {
+		if got := d.Pop(); got == nil || *got != want {
+			t.Fatalf("Pop() = %v, want %v", got, want)
+		}
+	}
+	if d.Pop() != nil {
+		t.Errorf("Pop() on an empty deque returned an item")
+	}
+}
+
+func TestDeque_ThievesAreFIFO(t *testing.T) {
+	t.Parallel()
+	d := NewDeque[int]()
+	for i := 0; i < 100; i++ {
+		v := i
+		d.Push(&v)
+	}
+
+	for want := 0; want < 100; want++ {
+		if got := d.Steal(); got == nil || *got != want {
+			t.Fatalf("Steal() = %v, want %v", got, want)
+		}
+	}
+	if d.Steal() != nil {
+		t.Errorf("Steal() on an empty deque returned an item")
+	}
+}
+
+// TestDeque_ConcurrentSteal checks that every item is taken exactly once while the owner
+// pushes and pops and thieves steal. Run it with -race.
+func TestDeque_ConcurrentSteal(t *testing.T) {
+	t.Parallel()
+	const items, thieves = 20000, 4
+	d := NewDeque[int]()
+	taken := make([]atomic.Int32, items)
+
+	var done atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < thieves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() || d.Len() > 0 {
+				if x := d.Steal(); x != nil {
+					taken[*x].Add(1)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < items; i++ {
+		v := i
+		d.Push(&v)
+		if i%3 == 0 {
+			if x := d.Pop(); x != nil {
+				taken[*x].Add(1)
+			}
+		}
+	}
+	done.Store(true)
+	wg.Wait()
+
+	for i := range taken {
+		if n := taken[i].Load(); n != 1 {
+			t.Fatalf("item %v was taken %v times, want 1", i, n)
+		}
+	}
+}
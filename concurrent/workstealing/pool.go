@@ -0,0 +1,222 @@
+package workstealing
+
+import (
+	"errors"
+	"math/rand"
+	"practice/collections/queue"
+	"runtime"
+	"sync"
+)
+
+// ErrClosed is returned by Submit once the pool is closed, and by Task.Err for tasks that Close dropped.
+var ErrClosed = errors.New("workstealing: pool is closed")
+
+// Pool runs tasks on a fixed set of workers. Every worker has its own Deque: tasks spawned by
+// a worker go to the bottom of its deque, and workers that run out of tasks steal from the top
+// of the others' deques. Tasks submitted from outside the pool go through a shared queue.
+type Pool struct {
+	workers []*Worker
+	// injected holds tasks submitted from outside the pool.
+	injected *queue.LinkedQueue[*Task]
+	// wake holds one token per worker that should look for work.
+	wake      chan struct{}
+	quit      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Worker runs tasks for a Pool. Tasks receive the worker that runs them, so they can spawn subtasks.
+type Worker struct {
+	pool  *Pool
+	deque *Deque[Task]
+	rand  *rand.Rand
+}
+
+// Task is a unit of work that can be waited on.
+type Task struct {
+	fn   func(w *Worker)
+	done chan struct{}
+	// err is set before done is closed if the task was dropped.
+	err error
+}
+
+// Done returns a channel that is closed once the task has run or been dropped by Close.
+func (t *Task) Done() <-chan struct{} {
+	return t.done
+}
+
+// Wait blocks until the task has run or been dropped by Close. Tasks waiting for subtasks should use Worker.Wait instead.
+func (t *Task) Wait() {
+	<-t.done
+}
+
+// Err returns ErrClosed if the pool was closed before the task could run, and nil once it has run.
+// It must only be called after Done is closed.
+func (t *Task) Err() error {
+	return t.err
+}
+
+func (t *Task) finished() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewPool starts a pool of n workers. If n is zero or less, it uses GOMAXPROCS workers.
+func NewPool(n int) *Pool {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+
+	p := &Pool{
+		injected: queue.NewLinkedQueue[*Task](),
+		wake:     make(chan struct{}, n),
+		quit:     make(chan struct{}),
+	}
+
+	for i := 0; i < n; i++ {
+		p.workers = append(p.workers, &Worker{
+			pool:  p,
+			deque: NewDeque[Task](),
+			rand:  rand.New(rand.NewSource(int64(i))),
+		})
+	}
+
+	p.wg.Add(n)
+	for _, w := range p.workers {
+		go w.loop()
+	}
+
+	return p
+}
+
+// Submit schedules fn to run on one of the workers.
+// ErrClosed is returned if the pool is closed.
+func (p *Pool) Submit(fn func(w *Worker)) (*Task, error) {
+	t := newTask(fn)
+	if !p.injected.TryPush(t) {
+		return nil, ErrClosed
+	}
+	p.notify()
+	return t, nil
+}
+
+// Close stops the workers once they finish the tasks they are running.
+// Tasks that have not started are dropped: their waiters are released and their Err is ErrClosed.
+// Closing a closed pool has no effect.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.quit)
+		p.injected.Close()
+		p.wg.Wait()
+
+		// The workers are gone, so nothing else touches the queues any more
+		for t, ok := p.injected.TryPop(); ok; t, ok = p.injected.TryPop() {
+			t.drop()
+		}
+		for _, w := range p.workers {
+			for t := w.deque.Pop(); t != nil; t = w.deque.Pop() {
+				t.drop()
+			}
+		}
+	})
+}
+
+// notify wakes up an idle worker, if there is one that is not already awake.
+func (p *Pool) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Spawn schedules fn as a subtask of the running task. Use Wait to join it.
+func (w *Worker) Spawn(fn func(w *Worker)) *Task {
+	t := newTask(fn)
+	w.deque.Push(t)
+	w.pool.notify()
+	return t
+}
+
+// Wait runs other tasks until t, which must have been spawned by w, has run, so a worker waiting on a subtask
+// keeps making progress. Once there is nothing left to run, it sleeps until t is done or more work is spawned.
+func (w *Worker) Wait(t *Task) {
+	for !t.finished() {
+		if next := w.find(); next != nil {
+			w.run(next)
+			continue
+		}
+
+		// w's deque is empty, so t was stolen and is running on another worker
+		select {
+		case <-t.done:
+		case <-w.pool.wake:
+		}
+	}
+}
+
+func (w *Worker) loop() {
+	defer w.pool.wg.Done()
+
+	for {
+		select {
+		case <-w.pool.quit:
+			return
+		default:
+		}
+
+		if t := w.find(); t != nil {
+			w.run(t)
+			continue
+		}
+
+		select {
+		case <-w.pool.quit:
+			return
+		case <-w.pool.wake:
+		}
+	}
+}
+
+// find looks for a task in the worker's own deque, then the shared queue, then other workers' deques.
+func (w *Worker) find() *Task {
+	if t := w.deque.Pop(); t != nil {
+		return t
+	}
+
+	if t, ok := w.pool.injected.TryPop(); ok {
+		return t
+	}
+
+	// Start stealing at a random victim so thieves spread out.
+	n := len(w.pool.workers)
+	start := w.rand.Intn(n)
+	for i := 0; i < n; i++ {
+		victim := w.pool.workers[(start+i)%n]
+		if victim == w {
+			continue
+		}
+		if t := victim.deque.Steal(); t != nil {
+			return t
+		}
+	}
+
+	return nil
+}
+
+func (w *Worker) run(t *Task) {
+	defer close(t.done)
+	t.fn(w)
+}
+
+func (t *Task) drop() {
+	t.err = ErrClosed
+	close(t.done)
+}
+
+func newTask(fn func(w *Worker)) *Task {
+	return &Task{fn: fn, done: make(chan struct{})}
+}
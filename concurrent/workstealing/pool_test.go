@@ -0,0 +1,111 @@
+package workstealing
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func submit(t *testing.T, p *Pool, fn func(w *Worker)) *Task {
+	task, err := p.Submit(fn)
+	if err != nil {
+		t.Fatalf("cannot submit: %v", err)
+	}
+	return task
+}
+
+func fib(w *Worker, n int) int {
+	if n < 2 {
+		return n
+	}
+
+	var left int
+	t := w.Spawn(func(w *Worker) { left = fib(w, n-1) })
+	right := fib(w, n-2)
+	w.Wait(t)
+	return left + right
+}
+
+func TestPool_SpawnAndWait(t *testing.T) {
+	t.Parallel()
+	p := NewPool(4)
+	defer p.Close()
+
+	var got int
+	submit(t, p, func(w *Worker) { got = fib(w, 20) }).Wait()
+
+	if got != 6765 {
+		t.Errorf("fib(20) = %v, want %v", got, 6765)
+	}
+}
+
+func TestPool_Submit(t *testing.T) {
+	t.Parallel()
+	p := NewPool(0)
+	defer p.Close()
+
+	var count atomic.Int64
+	var tasks []*Task
+	for i := 0; i < 1000; i++ {
+		tasks = append(tasks, submit(t, p, func(w *Worker) { count.Add(1) }))
+	}
+	for _, task := range tasks {
+		task.Wait()
+	}
+
+	if n := count.Load(); n != 1000 {
+		t.Errorf("ran %v tasks, want %v", n, 1000)
+	}
+}
+
+func TestPool_ManySubtasks(t *testing.T) {
+	t.Parallel()
+	p := NewPool(4)
+	defer p.Close()
+
+	// One task spawns many subtasks on its own deque, which idle workers steal from.
+	var count atomic.Int64
+	submit(t, p, func(w *Worker) {
+		var tasks []*Task
+		for i := 0; i < 2000; i++ {
+			tasks = append(tasks, w.Spawn(func(w *Worker) { count.Add(1) }))
+		}
+		for _, task := range tasks {
+			w.Wait(task)
+		}
+	}).Wait()
+
+	if n := count.Load(); n != 2000 {
+		t.Errorf("ran %v subtasks, want %v", n, 2000)
+	}
+}
+
+func TestPool_CloseReleasesDroppedTasks(t *testing.T) {
+	t.Parallel()
+	p := NewPool(1)
+
+	// The only worker is busy until the pool is closing, so the second task never starts.
+	started := make(chan struct{})
+	busy := submit(t, p, func(w *Worker) {
+		close(started)
+		<-p.quit
+	})
+	var ran atomic.Bool
+	dropped := submit(t, p, func(w *Worker) { ran.Store(true) })
+
+	<-started
+	p.Close()
+	dropped.Wait()
+	if ran.Load() || !errors.Is(dropped.Err(), ErrClosed) {
+		t.Errorf("dropped task ran = %v, Err() = %v, want false, %v", ran.Load(), dropped.Err(), ErrClosed)
+	}
+	if busy.Err() != nil {
+		t.Errorf("Err() of a task that ran = %v, want nil", busy.Err())
+	}
+
+	if _, err := p.Submit(func(w *Worker) {}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit() after Close = %v, want %v", err, ErrClosed)
+	}
+	// Closing again has no effect
+	p.Close()
+}
@@ -1,9 +1,11 @@
 module practice
 
-go 1.18
+// Go 1.24 is the minimum because collections/persistent and collections/cache hash keys with maphash.Comparable.
+go 1.24
 
 require (
 	github.com/gyuho/goraph v0.0.0-20171001060514-a7a4454fd3eb
+	github.com/spf13/cobra v1.8.0
 	github.com/stretchr/testify v1.7.0
 )
 
@@ -11,7 +13,6 @@ require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/spf13/cobra v1.8.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect